@@ -4,15 +4,20 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	AWS      AWSConfig
-	Auth     AuthConfig
+	Server      ServerConfig
+	Database    DatabaseConfig
+	Redis       RedisConfig
+	AWS         AWSConfig
+	Auth        AuthConfig
+	Photo       PhotoConfig
+	Geocoding   GeocodingConfig
+	Idempotency IdempotencyConfig
+	Mail        MailConfig
 }
 
 type ServerConfig struct {
@@ -44,9 +49,111 @@ type AWSConfig struct {
 	S3Bucket        string
 }
 
+// Token modes select how access tokens are issued and checked.
+const (
+	// TokenModeOpaque issues random access tokens looked up in Redis on
+	// every request.
+	TokenModeOpaque = "opaque"
+	// TokenModeSigned issues HMAC-signed access tokens verified by their
+	// signature and checked against a Redis denylist of revoked ones.
+	// Requests are refused while Redis is unreachable, as in opaque mode.
+	TokenModeSigned = "signed"
+)
+
 type AuthConfig struct {
-	TokenSecret        string
+	TokenMode string
+	// TokenSecret signs access tokens in signed mode under TokenKeyID.
+	TokenSecret string
+	TokenKeyID  string
+	// PreviousTokenKeys maps the key IDs of retired secrets to the secrets,
+	// so tokens they signed stay valid until they expire.
+	PreviousTokenKeys map[string]string
+	// TokenHashKey keys the hashes tokens are stored under in Redis. It
+	// defaults to TokenSecret; set it separately so rotating the signing
+	// secret doesn't end every session.
+	TokenHashKey string
+	// TokenExpirationMin is the lifetime of access tokens in minutes.
 	TokenExpirationMin int
+	// RefreshTokenTTL is how long a token family stays valid without being
+	// refreshed.
+	RefreshTokenTTL time.Duration
+	// PasswordResetTTL is how long a password reset link stays valid.
+	PasswordResetTTL time.Duration
+	// PasswordResetURL is the page reset links point to; the reset token is
+	// added as the token query parameter.
+	PasswordResetURL string
+	// EmailVerificationTTL is how long an email verification link stays
+	// valid.
+	EmailVerificationTTL time.Duration
+	// EmailVerificationURL is the page verification links point to; the
+	// token is added as the token query parameter.
+	EmailVerificationURL string
+	// UnverifiedRestrictions lists what users can't do until they verify
+	// their email address: "upload" for uploading and importing photos,
+	// "share" for share links and access grants.
+	UnverifiedRestrictions []string
+	// AccountDeletionGracePeriod is how long after asking for their account
+	// to be deleted a user can still keep it by logging in.
+	AccountDeletionGracePeriod time.Duration
+	// MFAIssuer names the service in authenticator apps.
+	MFAIssuer string
+	// MFAChallengeTTL is how long users with two-factor authentication have
+	// to enter their code after their password.
+	MFAChallengeTTL time.Duration
+}
+
+// Restrictions for users with an unverified email address.
+const (
+	RestrictUpload = "upload"
+	RestrictShare  = "share"
+)
+
+// Mail drivers select how emails are delivered.
+const (
+	MailDriverSMTP = "smtp"
+	// MailDriverFile writes emails to File, or to the log when it's empty.
+	MailDriverFile = "file"
+)
+
+type MailConfig struct {
+	Driver       string
+	From         string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	File         string
+}
+
+type PhotoConfig struct {
+	// MaxVersionsPerUser is how many previous versions of their photos a
+	// user keeps; older ones are deleted when a photo's content is replaced.
+	MaxVersionsPerUser int
+	// ImportTimeout bounds fetching a photo imported from a URL.
+	ImportTimeout time.Duration
+	// ImportMaxRedirects is how many redirects an import follows.
+	ImportMaxRedirects int
+}
+
+type IdempotencyConfig struct {
+	// TTL is how long the response to a request with an Idempotency-Key is
+	// kept for replay.
+	TTL time.Duration
+	// LockTimeout is how long a key stays claimed by a request that never
+	// completes, e.g. because the server stopped while handling it. The
+	// claim is extended while the request is handled, so it may be shorter
+	// than the slowest request.
+	LockTimeout time.Duration
+}
+
+type GeocodingConfig struct {
+	// CitiesFile and CountriesFile are GeoNames exports (cities15000.txt and
+	// countryInfo.txt); the bundled dataset is used when they are empty.
+	CitiesFile    string
+	CountriesFile string
+	// MaxDistanceKM is how far a photo may be from the nearest place for
+	// that place to be attached to it.
+	MaxDistanceKM int
 }
 
 func Load() (*Config, error) {
@@ -70,8 +177,21 @@ func Load() (*Config, error) {
 			DB:       getIntEnv("REDIS_DB", 0),
 		},
 		Auth: AuthConfig{
-			TokenSecret:        getEnv("AUTH_TOKEN_SECRET", "secret-key"),
-			TokenExpirationMin: getIntEnv("AUTH_TOKEN_EXPIRATION_MIN", 60),
+			TokenMode:                  getEnv("AUTH_TOKEN_MODE", TokenModeOpaque),
+			TokenSecret:                getEnv("AUTH_TOKEN_SECRET", "secret-key"),
+			TokenKeyID:                 getEnv("AUTH_TOKEN_KEY_ID", "default"),
+			PreviousTokenKeys:          getMapEnv("AUTH_TOKEN_PREVIOUS_KEYS"),
+			TokenHashKey:               getEnv("AUTH_TOKEN_HASH_KEY", ""),
+			TokenExpirationMin:         getIntEnv("AUTH_TOKEN_EXPIRATION_MIN", 15),
+			RefreshTokenTTL:            getDurationEnv("AUTH_REFRESH_TOKEN_TTL", 30*24*time.Hour),
+			PasswordResetTTL:           getDurationEnv("AUTH_PASSWORD_RESET_TTL", time.Hour),
+			PasswordResetURL:           getEnv("AUTH_PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+			EmailVerificationTTL:       getDurationEnv("AUTH_EMAIL_VERIFICATION_TTL", 48*time.Hour),
+			EmailVerificationURL:       getEnv("AUTH_EMAIL_VERIFICATION_URL", "http://localhost:3000/verify-email"),
+			UnverifiedRestrictions:     getListEnv("AUTH_UNVERIFIED_RESTRICTIONS", []string{RestrictShare}),
+			AccountDeletionGracePeriod: getDurationEnv("AUTH_ACCOUNT_DELETION_GRACE_PERIOD", 7*24*time.Hour),
+			MFAIssuer:                  getEnv("AUTH_MFA_ISSUER", "GoUp"),
+			MFAChallengeTTL:            getDurationEnv("AUTH_MFA_CHALLENGE_TTL", 5*time.Minute),
 		},
 		AWS: AWSConfig{
 			Region:          getEnv("AWS_REGION", "us-east-1"),
@@ -79,10 +199,56 @@ func Load() (*Config, error) {
 			SecretAccessKey: getEnv("AWS_SECRET_ACCESS_KEY", ""),
 			S3Bucket:        getEnv("AWS_S3_BUCKET", "goup-images"),
 		},
+		Photo: PhotoConfig{
+			MaxVersionsPerUser: getIntEnv("PHOTO_MAX_VERSIONS_PER_USER", 100),
+			ImportTimeout:      getDurationEnv("PHOTO_IMPORT_TIMEOUT", 15*time.Second),
+			ImportMaxRedirects: getIntEnv("PHOTO_IMPORT_MAX_REDIRECTS", 3),
+		},
+		Geocoding: GeocodingConfig{
+			CitiesFile:    getEnv("GEOCODING_CITIES_FILE", ""),
+			CountriesFile: getEnv("GEOCODING_COUNTRIES_FILE", ""),
+			MaxDistanceKM: getIntEnv("GEOCODING_MAX_DISTANCE_KM", 100),
+		},
+		Idempotency: IdempotencyConfig{
+			TTL:         getDurationEnv("IDEMPOTENCY_TTL", 24*time.Hour),
+			LockTimeout: getDurationEnv("IDEMPOTENCY_LOCK_TIMEOUT", 2*time.Minute),
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", MailDriverFile),
+			From:         getEnv("MAIL_FROM", "GoUp <no-reply@localhost>"),
+			SMTPHost:     getEnv("MAIL_SMTP_HOST", "localhost"),
+			SMTPPort:     getIntEnv("MAIL_SMTP_PORT", 587),
+			SMTPUsername: getEnv("MAIL_SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("MAIL_SMTP_PASSWORD", ""),
+			File:         getEnv("MAIL_FILE", ""),
+		},
 	}
 	if cfg.AWS.AccessKeyID == "" || cfg.AWS.SecretAccessKey == "" {
 		return nil, fmt.Errorf("AWS credentials are required")
 	}
+	if cfg.Auth.TokenHashKey == "" {
+		cfg.Auth.TokenHashKey = cfg.Auth.TokenSecret
+	}
+	if cfg.Auth.AccountDeletionGracePeriod < 0 {
+		return nil, fmt.Errorf("AUTH_ACCOUNT_DELETION_GRACE_PERIOD must not be negative")
+	}
+	for _, restriction := range cfg.Auth.UnverifiedRestrictions {
+		if restriction != RestrictUpload && restriction != RestrictShare {
+			return nil, fmt.Errorf("unknown AUTH_UNVERIFIED_RESTRICTIONS entry %q", restriction)
+		}
+	}
+	switch cfg.Auth.TokenMode {
+	case TokenModeOpaque:
+	case TokenModeSigned:
+		if len(cfg.Auth.TokenSecret) < 32 {
+			return nil, fmt.Errorf("AUTH_TOKEN_SECRET must be at least 32 characters in signed token mode")
+		}
+		if _, exists := cfg.Auth.PreviousTokenKeys[cfg.Auth.TokenKeyID]; exists {
+			return nil, fmt.Errorf("AUTH_TOKEN_KEY_ID %q is also a previous key", cfg.Auth.TokenKeyID)
+		}
+	default:
+		return nil, fmt.Errorf("unknown AUTH_TOKEN_MODE %q", cfg.Auth.TokenMode)
+	}
 	return cfg, nil
 }
 
@@ -116,3 +282,32 @@ func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	}
 	return value
 }
+
+// getListEnv parses a comma-separated list. An empty variable gives an
+// empty list; an unset one gives defaultValue.
+func getListEnv(key string, defaultValue []string) []string {
+	strValue, exist := os.LookupEnv(key)
+	if !exist {
+		return defaultValue
+	}
+	result := []string{}
+	for _, item := range strings.Split(strValue, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// getMapEnv parses a comma-separated list of key:value pairs. Values may
+// contain colons.
+func getMapEnv(key string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(getEnv(key, ""), ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if ok && k != "" && v != "" {
+			result[k] = v
+		}
+	}
+	return result
+}
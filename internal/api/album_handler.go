@@ -0,0 +1,336 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/middleware"
+	"github.com/mmd-moradi/goup/internal/service"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/mmd-moradi/goup/pkg/response"
+)
+
+type AlbumHandler struct {
+	albumService *service.AlbumService
+}
+
+func NewAlbumHandler(albumService *service.AlbumService) *AlbumHandler {
+	return &AlbumHandler{
+		albumService: albumService,
+	}
+}
+
+// Create handles creating an album
+// @Summary Create an album
+// @Description Create a new album for the authenticated user
+// @Tags albums
+// @Accept json
+// @Produce json
+// @Param input body service.AlbumCreateInput true "Album information"
+// @Security Bearer
+// @Success 201 {object} response.Response{data=service.AlbumResponse} "Album created successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid request payload"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /albums [post]
+func (h *AlbumHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	var input service.AlbumCreateInput
+	err = json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid request payload"))
+		return
+	}
+
+	album, err := h.albumService.CreateAlbum(r.Context(), input, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, album)
+}
+
+// List handles listing the albums of the current user
+// @Summary List user albums
+// @Description Get the albums owned by the authenticated user
+// @Tags albums
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=[]service.AlbumResponse} "Albums retrieved successfully"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /albums [get]
+func (h *AlbumHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	albums, err := h.albumService.GetAlbums(r.Context(), userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, albums)
+}
+
+// SharedWithMe handles listing the albums shared with the current user
+// @Summary List albums shared with me
+// @Description Get the albums other users granted the authenticated user access to
+// @Tags albums
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=[]service.AlbumResponse} "Albums retrieved successfully"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /albums/shared [get]
+func (h *AlbumHandler) SharedWithMe(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	albums, err := h.albumService.GetSharedWithMe(r.Context(), userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, albums)
+}
+
+// GetByID handles getting an album by ID
+// @Summary Get an album by ID
+// @Description Get an album the authenticated user owns or was granted access to
+// @Tags albums
+// @Produce json
+// @Param id path string true "Album ID"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=service.AlbumResponse} "Album retrieved successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid album ID"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User doesn't have access to the album"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Album not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /albums/{id} [get]
+func (h *AlbumHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	albumID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid album ID"))
+		return
+	}
+
+	album, err := h.albumService.GetAlbumByID(r.Context(), albumID, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, album)
+}
+
+// Photos handles listing the photos of an album
+// @Summary List album photos
+// @Description Get a paginated list of the photos in an album
+// @Tags albums
+// @Produce json
+// @Param id path string true "Album ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 10, max: 100)"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=service.PhotosResponse} "Photos retrieved successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid album ID"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User doesn't have access to the album"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Album not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /albums/{id}/photos [get]
+func (h *AlbumHandler) Photos(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	albumID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid album ID"))
+		return
+	}
+	page, pageSize := parsePagination(r)
+
+	photos, err := h.albumService.GetAlbumPhotos(r.Context(), albumID, userID, page, pageSize)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, photos)
+}
+
+// AddPhoto handles moving a photo into an album
+// @Summary Add a photo to an album
+// @Description Move a photo into an album. The photo leaves any album it was in before.
+// @Tags albums
+// @Param id path string true "Album ID"
+// @Param photoID path string true "Photo ID"
+// @Security Bearer
+// @Success 204 "Photo added successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid ID"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User doesn't have access to the album or photo"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Album or photo not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /albums/{id}/photos/{photoID} [put]
+func (h *AlbumHandler) AddPhoto(w http.ResponseWriter, r *http.Request) {
+	userID, albumID, photoID, ok := parseAlbumPhotoParams(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.albumService.AddPhoto(r.Context(), albumID, photoID, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// RemovePhoto handles removing a photo from an album
+// @Summary Remove a photo from an album
+// @Description Remove a photo from an album without deleting it
+// @Tags albums
+// @Param id path string true "Album ID"
+// @Param photoID path string true "Photo ID"
+// @Security Bearer
+// @Success 204 "Photo removed successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid ID"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User doesn't have access to the album"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Album or photo not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /albums/{id}/photos/{photoID} [delete]
+func (h *AlbumHandler) RemovePhoto(w http.ResponseWriter, r *http.Request) {
+	userID, albumID, photoID, ok := parseAlbumPhotoParams(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.albumService.RemovePhoto(r.Context(), albumID, photoID, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// Delete handles deleting an album
+// @Summary Delete an album
+// @Description Delete an album. Its photos are kept and no longer belong to any album.
+// @Tags albums
+// @Param id path string true "Album ID"
+// @Security Bearer
+// @Success 204 "Album deleted successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid album ID"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User doesn't have access to the album"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Album not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /albums/{id} [delete]
+func (h *AlbumHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	albumID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid album ID"))
+		return
+	}
+
+	err = h.albumService.DeleteAlbum(r.Context(), albumID, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// Suggestions handles suggesting albums by place
+// @Summary Suggest albums
+// @Description Suggest albums for the places where the authenticated user took at least 3 photos that aren't in an album yet. Create the album and move the listed photos into it to accept a suggestion.
+// @Tags albums
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=[]service.AlbumSuggestionResponse} "Suggestions retrieved successfully"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /albums/suggestions [get]
+func (h *AlbumHandler) Suggestions(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	suggestions, err := h.albumService.GetSuggestions(r.Context(), userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, suggestions)
+}
+
+func (h *AlbumHandler) RegisterRoutes(r chi.Router, authMiddleware func(next http.Handler) http.Handler) {
+	r.Group(func(r chi.Router) {
+		r.Use(authMiddleware)
+		r.Post("/", h.Create)
+		r.Get("/", h.List)
+		r.Get("/shared", h.SharedWithMe)
+		r.Get("/suggestions", h.Suggestions)
+		r.Get("/{id}", h.GetByID)
+		r.Delete("/{id}", h.Delete)
+		r.Get("/{id}/photos", h.Photos)
+		r.Put("/{id}/photos/{photoID}", h.AddPhoto)
+		r.Delete("/{id}/photos/{photoID}", h.RemovePhoto)
+	})
+}
+
+func parseAlbumPhotoParams(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, uuid.UUID, bool) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	albumID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid album ID"))
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	photoID, err := uuid.Parse(chi.URLParam(r, "photoID"))
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid photo ID"))
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	return userID, albumID, photoID, true
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/middleware"
+	"github.com/mmd-moradi/goup/internal/service"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/mmd-moradi/goup/pkg/response"
+)
+
+type CustomFieldHandler struct {
+	customFieldService *service.CustomFieldService
+}
+
+func NewCustomFieldHandler(customFieldService *service.CustomFieldService) *CustomFieldHandler {
+	return &CustomFieldHandler{
+		customFieldService: customFieldService,
+	}
+}
+
+// Create handles defining a custom field
+// @Summary Create a custom field
+// @Description Define a metadata field for the authenticated user's photos. Names start with a lowercase letter and contain only lowercase letters, digits and underscores. Values are set through the custom_fields object of a photo and a user can define up to 50 fields.
+// @Tags custom-fields
+// @Accept json
+// @Produce json
+// @Param input body service.CustomFieldCreateInput true "Custom field definition"
+// @Security Bearer
+// @Success 201 {object} response.Response{data=service.CustomFieldResponse} "Custom field created successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid request payload"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 409 {object} response.Response{error=response.ErrorInfo} "A custom field with the same name exists"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /custom-fields [post]
+func (h *CustomFieldHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	var input service.CustomFieldCreateInput
+	err = json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid request payload"))
+		return
+	}
+
+	field, err := h.customFieldService.CreateCustomField(r.Context(), input, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, field)
+}
+
+// List handles listing the custom fields of the current user
+// @Summary List custom fields
+// @Description Get the custom fields defined by the authenticated user, ordered by name
+// @Tags custom-fields
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=[]service.CustomFieldResponse} "Custom fields retrieved successfully"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /custom-fields [get]
+func (h *CustomFieldHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	fields, err := h.customFieldService.GetCustomFields(r.Context(), userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, fields)
+}
+
+// Update handles changing the constraints of a custom field
+// @Summary Update a custom field
+// @Description Change whether a custom field is required and which values it allows. Existing photos are checked against the new constraints the next time they are saved. The name and type of a field can't be changed.
+// @Tags custom-fields
+// @Accept json
+// @Produce json
+// @Param id path string true "Custom field ID"
+// @Param input body service.CustomFieldUpdateInput true "Custom field constraints"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=service.CustomFieldResponse} "Custom field updated successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid request payload"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Custom field not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /custom-fields/{id} [put]
+func (h *CustomFieldHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	fieldID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid custom field ID"))
+		return
+	}
+
+	var input service.CustomFieldUpdateInput
+	err = json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid request payload"))
+		return
+	}
+
+	field, err := h.customFieldService.UpdateCustomField(r.Context(), fieldID, input, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, field)
+}
+
+// Delete handles deleting a custom field
+// @Summary Delete a custom field
+// @Description Delete a custom field and remove its values from the authenticated user's photos
+// @Tags custom-fields
+// @Param id path string true "Custom field ID"
+// @Security Bearer
+// @Success 204 "Custom field deleted successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid custom field ID"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Custom field not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /custom-fields/{id} [delete]
+func (h *CustomFieldHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	fieldID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid custom field ID"))
+		return
+	}
+
+	err = h.customFieldService.DeleteCustomField(r.Context(), fieldID, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+func (h *CustomFieldHandler) RegisterRoutes(r chi.Router, authMiddleware func(next http.Handler) http.Handler) {
+	r.Group(func(r chi.Router) {
+		r.Use(authMiddleware)
+		r.Post("/", h.Create)
+		r.Get("/", h.List)
+		r.Put("/{id}", h.Update)
+		r.Delete("/{id}", h.Delete)
+	})
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mmd-moradi/goup/internal/middleware"
+	"github.com/mmd-moradi/goup/internal/service"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/mmd-moradi/goup/pkg/response"
+)
+
+// exportWriteTimeout replaces the server write timeout and the request
+// timeout while an archive is streamed in the response.
+const exportWriteTimeout = 10 * time.Minute
+
+type ExportHandler struct {
+	exportService *service.ExportService
+}
+
+func NewExportHandler(exportService *service.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// Create handles exporting photos as a ZIP archive
+// @Summary Export photos
+// @Description Export a selection of photos (photo IDs, an album, or all photos) as a ZIP archive with a metadata.json manifest. Small exports are streamed in the response; large ones, or any export with background set, run as a background job whose status and download link are available from GET /exports/{id}.
+// @Tags exports
+// @Accept json
+// @Produce application/zip
+// @Produce json
+// @Param input body service.ExportCreateInput true "Photos to export"
+// @Security Bearer
+// @Success 200 {file} binary "ZIP archive"
+// @Success 202 {object} response.Response{data=service.ExportJobResponse} "Export job started"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid request payload"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User doesn't have access to a selected photo or album"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Photo or album not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /exports [post]
+func (h *ExportHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	var input service.ExportCreateInput
+	err = json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid request payload"))
+		return
+	}
+
+	export, err := h.exportService.CreateExport(r.Context(), input, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	if export.Job != nil {
+		response.JSON(w, http.StatusAccepted, export.Job)
+		return
+	}
+
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.FileName()))
+	w.WriteHeader(http.StatusOK)
+
+	// The request context ends with the request timeout, which a large
+	// archive sent to a slow client can outlast.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), exportWriteTimeout)
+	defer cancel()
+
+	// The status is already sent, so on failure the connection is aborted
+	// to make sure the client doesn't mistake a truncated archive for a
+	// complete one.
+	err = h.exportService.WriteArchive(ctx, w, export)
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// Get handles getting the state of a background export
+// @Summary Get an export
+// @Description Get the status of a background export. Once completed, download_url is a link to the archive that stops working at expires_at, when the archive is deleted.
+// @Tags exports
+// @Produce json
+// @Param id path string true "Export ID"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=service.ExportJobResponse} "Export retrieved successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid export ID"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Export not found or expired"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /exports/{id} [get]
+func (h *ExportHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, exportID, ok := parseResourceParams(w, r, "invalid export ID")
+	if !ok {
+		return
+	}
+
+	export, err := h.exportService.GetExport(r.Context(), exportID, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, export)
+}
+
+// CreateDataExport handles exporting everything held about the current user
+// @Summary Export account data
+// @Description Start a background job that archives everything held about the authenticated user: a ZIP with a metadata.json manifest holding the profile, the metadata of every photo, and the account's albums, tags, share links, grants, custom field definitions, sessions, ownership transfers and audit events, and the original photo files under photos/. Each photo in the manifest has the path of its file, and photos whose file couldn't be read are listed under missing. The manifest is versioned by its format member and only ever extended with new optional members. The user is emailed a download link when the archive is ready; its status and link are also available from GET /exports/{id}. photo_count is 0 until the job has collected the data. One data export can run at a time, and the archive is deleted once its link expires.
+// @Tags auth
+// @Produce json
+// @Security Bearer
+// @Success 202 {object} response.Response{data=service.ExportJobResponse} "Data export started"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 409 {object} response.Response{error=response.ErrorInfo} "A data export is already in progress"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /auth/data-export [post]
+func (h *ExportHandler) CreateDataExport(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	job, err := h.exportService.CreateDataExport(r.Context(), userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, job)
+}
+
+func (h *ExportHandler) RegisterRoutes(r chi.Router, authMiddleware func(next http.Handler) http.Handler) {
+	r.Group(func(r chi.Router) {
+		r.Use(authMiddleware)
+		r.Post("/", h.Create)
+		r.Get("/{id}", h.Get)
+	})
+}
+
+func (h *ExportHandler) RegisterAccountRoutes(r chi.Router, authMiddleware func(next http.Handler) http.Handler) {
+	r.Group(func(r chi.Router) {
+		r.Use(authMiddleware)
+		r.Post("/data-export", h.CreateDataExport)
+	})
+}
@@ -0,0 +1,258 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/middleware"
+	"github.com/mmd-moradi/goup/internal/service"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/mmd-moradi/goup/pkg/response"
+)
+
+type GrantHandler struct {
+	grantService *service.GrantService
+}
+
+func NewGrantHandler(grantService *service.GrantService) *GrantHandler {
+	return &GrantHandler{
+		grantService: grantService,
+	}
+}
+
+// CreatePhotoGrant handles granting another user access to a photo
+// @Summary Share a photo with a user
+// @Description Grant another user, identified by username or email, a viewer, commenter or editor role on a photo. Granting again changes the role.
+// @Tags grants
+// @Accept json
+// @Produce json
+// @Param id path string true "Photo ID"
+// @Param input body service.GrantInput true "Grantee and role"
+// @Security Bearer
+// @Success 201 {object} response.Response{data=service.GrantResponse} "Access granted successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid request payload"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User doesn't own the photo, or email address not verified"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Photo or user not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /photos/{id}/grants [post]
+func (h *GrantHandler) CreatePhotoGrant(w http.ResponseWriter, r *http.Request) {
+	userID, photoID, ok := parseResourceParams(w, r, "invalid photo ID")
+	if !ok {
+		return
+	}
+
+	var input service.GrantInput
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid request payload"))
+		return
+	}
+
+	grant, err := h.grantService.GrantPhotoAccess(r.Context(), photoID, input, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, grant)
+}
+
+// ListPhotoGrants handles listing the grants on a photo
+// @Summary List photo grants
+// @Description List the users a photo is shared with
+// @Tags grants
+// @Produce json
+// @Param id path string true "Photo ID"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=[]service.GrantResponse} "Grants retrieved successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid photo ID"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User doesn't own the photo"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Photo not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /photos/{id}/grants [get]
+func (h *GrantHandler) ListPhotoGrants(w http.ResponseWriter, r *http.Request) {
+	userID, photoID, ok := parseResourceParams(w, r, "invalid photo ID")
+	if !ok {
+		return
+	}
+
+	grants, err := h.grantService.ListPhotoGrants(r.Context(), photoID, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, grants)
+}
+
+// RevokePhotoGrant handles revoking a grant on a photo
+// @Summary Revoke photo access
+// @Description Revoke a user's access to a photo. Takes effect immediately.
+// @Tags grants
+// @Param id path string true "Photo ID"
+// @Param grantID path string true "Grant ID"
+// @Security Bearer
+// @Success 204 "Grant revoked successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid ID"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User doesn't own the photo"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Photo or grant not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /photos/{id}/grants/{grantID} [delete]
+func (h *GrantHandler) RevokePhotoGrant(w http.ResponseWriter, r *http.Request) {
+	userID, photoID, ok := parseResourceParams(w, r, "invalid photo ID")
+	if !ok {
+		return
+	}
+	grantID, err := uuid.Parse(chi.URLParam(r, "grantID"))
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid grant ID"))
+		return
+	}
+
+	err = h.grantService.RevokePhotoGrant(r.Context(), photoID, grantID, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// CreateAlbumGrant handles granting another user access to an album
+// @Summary Share an album with a user
+// @Description Grant another user, identified by username or email, a viewer, commenter or editor role on an album and its photos. Granting again changes the role.
+// @Tags grants
+// @Accept json
+// @Produce json
+// @Param id path string true "Album ID"
+// @Param input body service.GrantInput true "Grantee and role"
+// @Security Bearer
+// @Success 201 {object} response.Response{data=service.GrantResponse} "Access granted successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid request payload"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User doesn't own the album, or email address not verified"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Album or user not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /albums/{id}/grants [post]
+func (h *GrantHandler) CreateAlbumGrant(w http.ResponseWriter, r *http.Request) {
+	userID, albumID, ok := parseResourceParams(w, r, "invalid album ID")
+	if !ok {
+		return
+	}
+
+	var input service.GrantInput
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid request payload"))
+		return
+	}
+
+	grant, err := h.grantService.GrantAlbumAccess(r.Context(), albumID, input, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, grant)
+}
+
+// ListAlbumGrants handles listing the grants on an album
+// @Summary List album grants
+// @Description List the users an album is shared with
+// @Tags grants
+// @Produce json
+// @Param id path string true "Album ID"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=[]service.GrantResponse} "Grants retrieved successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid album ID"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User doesn't own the album"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Album not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /albums/{id}/grants [get]
+func (h *GrantHandler) ListAlbumGrants(w http.ResponseWriter, r *http.Request) {
+	userID, albumID, ok := parseResourceParams(w, r, "invalid album ID")
+	if !ok {
+		return
+	}
+
+	grants, err := h.grantService.ListAlbumGrants(r.Context(), albumID, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, grants)
+}
+
+// RevokeAlbumGrant handles revoking a grant on an album
+// @Summary Revoke album access
+// @Description Revoke a user's access to an album. Takes effect immediately.
+// @Tags grants
+// @Param id path string true "Album ID"
+// @Param grantID path string true "Grant ID"
+// @Security Bearer
+// @Success 204 "Grant revoked successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid ID"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User doesn't own the album"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Album or grant not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /albums/{id}/grants/{grantID} [delete]
+func (h *GrantHandler) RevokeAlbumGrant(w http.ResponseWriter, r *http.Request) {
+	userID, albumID, ok := parseResourceParams(w, r, "invalid album ID")
+	if !ok {
+		return
+	}
+	grantID, err := uuid.Parse(chi.URLParam(r, "grantID"))
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid grant ID"))
+		return
+	}
+
+	err = h.grantService.RevokeAlbumGrant(r.Context(), albumID, grantID, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+func (h *GrantHandler) RegisterPhotoRoutes(r chi.Router, authMiddleware func(next http.Handler) http.Handler) {
+	r.Group(func(r chi.Router) {
+		r.Use(authMiddleware)
+		r.Post("/{id}/grants", h.CreatePhotoGrant)
+		r.Get("/{id}/grants", h.ListPhotoGrants)
+		r.Delete("/{id}/grants/{grantID}", h.RevokePhotoGrant)
+	})
+}
+
+func (h *GrantHandler) RegisterAlbumRoutes(r chi.Router, authMiddleware func(next http.Handler) http.Handler) {
+	r.Group(func(r chi.Router) {
+		r.Use(authMiddleware)
+		r.Post("/{id}/grants", h.CreateAlbumGrant)
+		r.Get("/{id}/grants", h.ListAlbumGrants)
+		r.Delete("/{id}/grants/{grantID}", h.RevokeAlbumGrant)
+	})
+}
+
+// parseResourceParams reads the authenticated user and the {id} URL parameter.
+func parseResourceParams(w http.ResponseWriter, r *http.Request, invalidIDMessage string) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, invalidIDMessage))
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, id, true
+}
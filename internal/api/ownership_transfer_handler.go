@@ -0,0 +1,212 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/middleware"
+	"github.com/mmd-moradi/goup/internal/service"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/mmd-moradi/goup/pkg/response"
+)
+
+type OwnershipTransferHandler struct {
+	transferService *service.OwnershipTransferService
+}
+
+func NewOwnershipTransferHandler(transferService *service.OwnershipTransferService) *OwnershipTransferHandler {
+	return &OwnershipTransferHandler{
+		transferService: transferService,
+	}
+}
+
+// Create handles offering photos and albums to another user
+// @Summary Create an ownership transfer
+// @Description Offer photos, albums or everything the authenticated user owns to another user, identified by username or email. Nothing moves until the recipient accepts, and the offer expires after 7 days.
+// @Tags transfers
+// @Accept json
+// @Produce json
+// @Param input body service.TransferCreateInput true "Transfer selection"
+// @Security Bearer
+// @Success 201 {object} response.Response{data=service.TransferResponse} "Transfer created successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid request payload"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "A selected photo or album is owned by someone else"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Recipient, photo or album not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /transfers [post]
+func (h *OwnershipTransferHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	var input service.TransferCreateInput
+	err = json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid request payload"))
+		return
+	}
+
+	transfer, err := h.transferService.CreateTransfer(r.Context(), input, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, transfer)
+}
+
+// List handles listing the transfers of the current user
+// @Summary List ownership transfers
+// @Description Get the latest 50 transfers the authenticated user sent or received, newest first
+// @Tags transfers
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=[]service.TransferResponse} "Transfers retrieved successfully"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /transfers [get]
+func (h *OwnershipTransferHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	transfers, err := h.transferService.GetTransfers(r.Context(), userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, transfers)
+}
+
+// Get handles getting a transfer by ID
+// @Summary Get an ownership transfer
+// @Description Get a transfer the authenticated user sent or received. Poll it after accepting to see when the move has completed or failed.
+// @Tags transfers
+// @Produce json
+// @Param id path string true "Transfer ID"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=service.TransferResponse} "Transfer retrieved successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid transfer ID"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Transfer not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /transfers/{id} [get]
+func (h *OwnershipTransferHandler) Get(w http.ResponseWriter, r *http.Request) {
+	h.handle(w, r, h.transferService.GetTransfer)
+}
+
+// Accept handles accepting a transfer
+// @Summary Accept an ownership transfer
+// @Description Accept a pending transfer sent to the authenticated user. Photos, their versions, albums, grants, share links and custom field definitions move to the recipient in the background; the transfer reports completed or failed once done. The storage_used of both profiles reflects the move as soon as it completes.
+// @Tags transfers
+// @Produce json
+// @Param id path string true "Transfer ID"
+// @Security Bearer
+// @Success 202 {object} response.Response{data=service.TransferResponse} "Transfer accepted"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid transfer ID or transfer expired"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User is not the recipient"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Transfer not found"
+// @Failure 409 {object} response.Response{error=response.ErrorInfo} "Transfer is not pending"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /transfers/{id}/accept [post]
+func (h *OwnershipTransferHandler) Accept(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	transferID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid transfer ID"))
+		return
+	}
+
+	transfer, err := h.transferService.AcceptTransfer(r.Context(), transferID, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, transfer)
+}
+
+// Decline handles declining a transfer
+// @Summary Decline an ownership transfer
+// @Description Decline a pending transfer sent to the authenticated user
+// @Tags transfers
+// @Produce json
+// @Param id path string true "Transfer ID"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=service.TransferResponse} "Transfer declined"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid transfer ID"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User is not the recipient"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Transfer not found"
+// @Failure 409 {object} response.Response{error=response.ErrorInfo} "Transfer is not pending"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /transfers/{id}/decline [post]
+func (h *OwnershipTransferHandler) Decline(w http.ResponseWriter, r *http.Request) {
+	h.handle(w, r, h.transferService.DeclineTransfer)
+}
+
+// Cancel handles canceling a transfer
+// @Summary Cancel an ownership transfer
+// @Description Withdraw a pending transfer the authenticated user sent
+// @Tags transfers
+// @Produce json
+// @Param id path string true "Transfer ID"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=service.TransferResponse} "Transfer canceled"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid transfer ID"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User is not the sender"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Transfer not found"
+// @Failure 409 {object} response.Response{error=response.ErrorInfo} "Transfer is not pending"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /transfers/{id}/cancel [post]
+func (h *OwnershipTransferHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	h.handle(w, r, h.transferService.CancelTransfer)
+}
+
+func (h *OwnershipTransferHandler) handle(w http.ResponseWriter, r *http.Request, fn func(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*service.TransferResponse, error)) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	transferID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid transfer ID"))
+		return
+	}
+
+	transfer, err := fn(r.Context(), transferID, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, transfer)
+}
+
+func (h *OwnershipTransferHandler) RegisterRoutes(r chi.Router, authMiddleware func(next http.Handler) http.Handler) {
+	r.Group(func(r chi.Router) {
+		r.Use(authMiddleware)
+		r.Post("/", h.Create)
+		r.Get("/", h.List)
+		r.Get("/{id}", h.Get)
+		r.Post("/{id}/accept", h.Accept)
+		r.Post("/{id}/decline", h.Decline)
+		r.Post("/{id}/cancel", h.Cancel)
+	})
+}
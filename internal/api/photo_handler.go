@@ -3,8 +3,13 @@ package api
 import (
 	"encoding/json"
 	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -14,13 +19,29 @@ import (
 	"github.com/mmd-moradi/goup/pkg/response"
 )
 
+const (
+	// maxUploadSize limits the body of a single photo upload request.
+	maxUploadSize = 10 << 20
+	// maxBatchUploadSize limits the whole body of a batch upload request.
+	maxBatchUploadSize = 256 << 20
+	// maxBatchUploadFiles limits the number of files in a batch upload.
+	maxBatchUploadFiles = 200
+	// batchUploadMemory is how much of a batch upload is kept in memory
+	// while parsing; larger bodies are spooled to temporary files.
+	batchUploadMemory = 32 << 20
+	// maxPatchSize limits the body of a merge patch request.
+	maxPatchSize = 1 << 20
+)
+
 type PhotoHandler struct {
 	photoService *service.PhotoService
+	idempotency  *middleware.IdempotencyStore
 }
 
-func NewPhotoHandler(photoService *service.PhotoService) *PhotoHandler {
+func NewPhotoHandler(photoService *service.PhotoService, idempotency *middleware.IdempotencyStore) *PhotoHandler {
 	return &PhotoHandler{
 		photoService: photoService,
+		idempotency:  idempotency,
 	}
 }
 
@@ -32,16 +53,24 @@ func NewPhotoHandler(photoService *service.PhotoService) *PhotoHandler {
 // @Produce json
 // @Param title formData string true "Photo title"
 // @Param description formData string false "Photo description"
+// @Param latitude formData number false "Latitude the photo was taken at, required with longitude"
+// @Param longitude formData number false "Longitude the photo was taken at, required with latitude"
+// @Param taken_at formData string false "When the photo was taken, in RFC 3339 format"
+// @Param custom_fields formData string false "JSON object of custom field values by field name"
 // @Param file formData file true "Photo file to upload"
+// @Param Idempotency-Key header string false "Unique key making retries of the request safe, kept for 24 hours"
 // @Security Bearer
 // @Success 201 {object} response.Response{data=service.PhotoResponse} "Photo uploaded successfully"
 // @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid request payload"
 // @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "Email address not verified and unverified users can't upload"
+// @Failure 409 {object} response.Response{error=response.ErrorInfo} "A request with the same idempotency key is in progress"
+// @Failure 422 {object} response.Response{error=response.ErrorInfo} "Idempotency key reused with a different request"
 // @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
 // @Router /photos [post]
 func (h *PhotoHandler) Upload(w http.ResponseWriter, r *http.Request) {
-	r.Body = http.MaxBytesReader(w, r.Body, 10*1024*1024)
-	if err := r.ParseMultipartForm(10 << 20); err != nil {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
 		response.Error(w, apperrors.NewWithFormat(apperrors.BadRequest, "failed to parse form: %v", err))
 	}
 
@@ -66,12 +95,34 @@ func (h *PhotoHandler) Upload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	latitude, longitude, err := parseLocation(r.FormValue("latitude"), r.FormValue("longitude"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	takenAt, err := parseTakenAt(r.FormValue("taken_at"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	customFields, err := parseCustomFields(r.FormValue("custom_fields"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
 	input := service.PhotoUploadInput{
-		Title:       title,
-		Description: description,
-		FileName:    header.Filename,
-		FileSize:    header.Size,
-		ContentType: header.Header.Get("Content-Type"),
+		Title:        title,
+		Description:  description,
+		FileName:     header.Filename,
+		FileSize:     header.Size,
+		ContentType:  header.Header.Get("Content-Type"),
+		Latitude:     latitude,
+		Longitude:    longitude,
+		TakenAt:      takenAt,
+		CustomFields: customFields,
 	}
 
 	photo, err := h.photoService.UploadPhoto(r.Context(), input, userID, data)
@@ -83,6 +134,146 @@ func (h *PhotoHandler) Upload(w http.ResponseWriter, r *http.Request) {
 	response.JSON(w, http.StatusCreated, photo)
 }
 
+// BatchUpload handles uploading multiple photos in one request
+// @Summary Upload multiple photos
+// @Description Upload up to 200 photos in one multipart request. Every "file" part is a photo. Metadata fields are named after the zero-based index of the file they belong to: "title[n]", "description[n]", "latitude[n]", "longitude[n]", "taken_at[n]" and "custom_fields[n]" belong to the n-th file. Every field is optional and a missing title defaults to the file name; fields without a valid index reject the request. Each file is limited to 10MB and the whole request to 256MB. A file that fails is reported in its result without failing the batch.
+// @Tags photos
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Photo files to upload (repeat the field for each file)"
+// @Param title[0] formData string false "Title of the first file, likewise title[1] for the second and so on"
+// @Param description[0] formData string false "Description of the first file"
+// @Param latitude[0] formData number false "Latitude of the first file, required with longitude[0]"
+// @Param longitude[0] formData number false "Longitude of the first file, required with latitude[0]"
+// @Param taken_at[0] formData string false "When the first file was taken, in RFC 3339 format"
+// @Param custom_fields[0] formData string false "JSON object of custom field values of the first file"
+// @Param Idempotency-Key header string false "Unique key making retries of the request safe, kept for 24 hours"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=service.PhotoBatchResponse} "Batch processed, see the per-file results"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid request payload"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "Email address not verified and unverified users can't upload"
+// @Failure 409 {object} response.Response{error=response.ErrorInfo} "A request with the same idempotency key is in progress"
+// @Failure 422 {object} response.Response{error=response.ErrorInfo} "Idempotency key reused with a different request"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /photos/batch [post]
+func (h *PhotoHandler) BatchUpload(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBatchUploadSize)
+	if err := r.ParseMultipartForm(batchUploadMemory); err != nil {
+		response.Error(w, apperrors.NewWithFormat(apperrors.BadRequest, "failed to parse form: %v", err))
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	files := r.MultipartForm.File["file"]
+	if len(files) == 0 {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "at least one file is required"))
+		return
+	}
+	if len(files) > maxBatchUploadFiles {
+		response.Error(w, apperrors.NewWithFormat(apperrors.BadRequest, "a batch can contain at most %d files", maxBatchUploadFiles))
+		return
+	}
+
+	fields, err := batchFormFields(r.MultipartForm.Value, len(files))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	items := make([]service.PhotoBatchItem, len(files))
+	for i, header := range files {
+		title := fields[i]["title"]
+		if title == "" {
+			title = header.Filename
+		}
+		latitude, longitude, err := parseLocation(fields[i]["latitude"], fields[i]["longitude"])
+		if err != nil {
+			response.Error(w, apperrors.NewWithFormat(apperrors.BadRequest, "file %d: %v", i, err))
+			return
+		}
+		takenAt, err := parseTakenAt(fields[i]["taken_at"])
+		if err != nil {
+			response.Error(w, apperrors.NewWithFormat(apperrors.BadRequest, "file %d: %v", i, err))
+			return
+		}
+		customFields, err := parseCustomFields(fields[i]["custom_fields"])
+		if err != nil {
+			response.Error(w, apperrors.NewWithFormat(apperrors.BadRequest, "file %d: %v", i, err))
+			return
+		}
+		items[i] = service.PhotoBatchItem{
+			Input: service.PhotoUploadInput{
+				Title:        title,
+				Description:  fields[i]["description"],
+				FileName:     header.Filename,
+				FileSize:     header.Size,
+				ContentType:  header.Header.Get("Content-Type"),
+				Latitude:     latitude,
+				Longitude:    longitude,
+				TakenAt:      takenAt,
+				CustomFields: customFields,
+			},
+			Open: openFileHeader(header),
+		}
+	}
+
+	result, err := h.photoService.UploadPhotos(r.Context(), items, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, result)
+}
+
+// Bulk handles applying one operation to many photos
+// @Summary Apply an operation to many photos
+// @Description Apply delete, update-metadata, add-tags, move-to-album or change-visibility to up to 100 photos owned by the authenticated user. Photos that don't exist or aren't owned by the user are reported as failed without affecting the others.
+// @Tags photos
+// @Accept json
+// @Produce json
+// @Param input body service.PhotoBulkInput true "Photo IDs, operation and its arguments"
+// @Param Idempotency-Key header string false "Unique key making retries of the request safe, kept for 24 hours"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=service.PhotoBulkResponse} "Operation applied, see the per-photo results"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid request payload"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 409 {object} response.Response{error=response.ErrorInfo} "A request with the same idempotency key is in progress"
+// @Failure 422 {object} response.Response{error=response.ErrorInfo} "Idempotency key reused with a different request"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User doesn't own the target album"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Target album not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /photos/bulk [post]
+func (h *PhotoHandler) Bulk(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	var input service.PhotoBulkInput
+	err = json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid request payload"))
+		return
+	}
+
+	result, err := h.photoService.BulkUpdatePhotos(r.Context(), input, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, result)
+}
+
 // GetByID handles getting a photo by ID
 // @Summary Get a photo by ID
 // @Description Get a photo by its ID
@@ -91,6 +282,7 @@ func (h *PhotoHandler) Upload(w http.ResponseWriter, r *http.Request) {
 // @Param id path string true "Photo ID"
 // @Security Bearer
 // @Success 200 {object} response.Response{data=service.PhotoResponse} "Photo retrieved successfully"
+// @Header 200 {string} ETag "Entity tag of the photo"
 // @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid photo ID"
 // @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
 // @Failure 403 {object} response.Response{error=response.ErrorInfo} "User doesn't have access to the photo"
@@ -115,18 +307,63 @@ func (h *PhotoHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("ETag", photo.ETag())
 	response.JSON(w, http.StatusOK, photo)
 }
 
+// Content handles downloading the current file of a photo
+// @Summary Download a photo
+// @Description Download the current file of a photo. Access is checked on every request, so a revoked grant takes effect immediately.
+// @Tags photos
+// @Produce octet-stream
+// @Param id path string true "Photo ID"
+// @Param download query bool false "Serve the file as an attachment instead of inline"
+// @Security Bearer
+// @Success 200 {file} binary "Photo file"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid photo ID"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User doesn't have access to the photo"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Photo not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /photos/{id}/content [get]
+func (h *PhotoHandler) Content(w http.ResponseWriter, r *http.Request) {
+	userID, photoID, ok := parseResourceParams(w, r, "invalid photo ID")
+	if !ok {
+		return
+	}
+
+	content, err := h.photoService.GetPhotoContent(r.Context(), photoID, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	disposition := "inline"
+	if r.URL.Query().Get("download") == "true" {
+		disposition = "attachment"
+	}
+
+	w.Header().Set("Content-Type", content.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(content.Data)))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": content.FileName}))
+	w.WriteHeader(http.StatusOK)
+	w.Write(content.Data)
+}
+
 // List handles listing photos for the current user with pagination
 // @Summary List user photos
-// @Description Get a paginated list of photos for the authenticated user
+// @Description Get a paginated list of photos for the authenticated user. place, bucket and field.* filters can't be combined with each other; passing more than one of them is rejected.
 // @Tags photos
 // @Produce json
 // @Param page query int false "Page number (default: 1)"
 // @Param page_size query int false "Page size (default: 10, max: 100)"
+// @Param place query string false "Only photos whose place or country name contains this text. Can't be combined with bucket or field.*"
+// @Param bucket query string false "Return the page holding the first photo of this timeline bucket (YYYY, YYYY-MM or YYYY-MM-DD); page is ignored. Can't be combined with place or field.*"
+// @Param tz query string false "IANA time zone the bucket is read in (default: the user's time zone)"
+// @Param field.name query string false "Only photos whose custom field \"name\" has this value; repeat with other fields to combine filters, each field at most once. Can't be combined with place or bucket"
 // @Security Bearer
 // @Success 200 {object} response.Response{data=service.PhotosResponse} "Photos retrieved successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid filter or filters that can't be combined"
 // @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
 // @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
 // @Router /photos [get]
@@ -137,27 +374,38 @@ func (h *PhotoHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pageStr := r.URL.Query().Get("page")
-	pageSizeStr := r.URL.Query().Get("page_size")
+	page, pageSize := parsePagination(r)
 
-	page := 1
-	pageSize := 10
-
-	if pageStr != "" {
-		pageInt, err := strconv.Atoi(pageStr)
-		if err == nil && page > 0 {
-			page = pageInt
-		}
+	query := r.URL.Query()
+	filters, err := customFieldFilters(query)
+	if err != nil {
+		response.Error(w, err)
+		return
 	}
+	place, bucket := query.Get("place"), query.Get("bucket")
 
-	if pageSizeStr != "" {
-		pageSizeInt, err := strconv.Atoi(pageSizeStr)
-		if err == nil && pageSize > 0 && pageSizeInt <= 100 {
-			pageSize = pageSizeInt
+	families := 0
+	for _, set := range []bool{place != "", bucket != "", len(filters) > 0} {
+		if set {
+			families++
 		}
 	}
+	if families > 1 {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "place, bucket and field filters can't be combined"))
+		return
+	}
 
-	listPhotos, err := h.photoService.GetPhotosByID(r.Context(), userID, page, pageSize)
+	var listPhotos *service.PhotosResponse
+	switch {
+	case place != "":
+		listPhotos, err = h.photoService.SearchPhotosByPlace(r.Context(), userID, place, page, pageSize)
+	case bucket != "":
+		listPhotos, err = h.photoService.GetPhotosFromBucket(r.Context(), userID, bucket, query.Get("tz"), pageSize)
+	case len(filters) > 0:
+		listPhotos, err = h.photoService.SearchPhotosByCustomFields(r.Context(), userID, filters, page, pageSize)
+	default:
+		listPhotos, err = h.photoService.GetPhotosByID(r.Context(), userID, page, pageSize)
+	}
 	if err != nil {
 		response.Error(w, err)
 		return
@@ -167,20 +415,52 @@ func (h *PhotoHandler) List(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// SharedWithMe handles listing the photos shared with the current user
+// @Summary List photos shared with me
+// @Description Get a paginated list of the photos other users granted the authenticated user access to, directly or through an album
+// @Tags photos
+// @Produce json
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 10, max: 100)"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=service.PhotosResponse} "Photos retrieved successfully"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /photos/shared [get]
+func (h *PhotoHandler) SharedWithMe(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	page, pageSize := parsePagination(r)
+
+	photos, err := h.photoService.GetSharedWithMe(r.Context(), userID, page, pageSize)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, photos)
+}
+
 // Update handles updating a photo's metadata
 // @Summary Update photo metadata
-// @Description Update the title and description of a photo
+// @Description Update the title, description and visibility of a photo
 // @Tags photos
 // @Accept json
 // @Produce json
 // @Param id path string true "Photo ID"
 // @Param input body service.PhotoUpdateInput true "Photo update information"
+// @Param If-Match header string false "Only update if the photo's ETag matches"
 // @Security Bearer
 // @Success 200 {object} response.Response{data=service.PhotoResponse} "Photo updated successfully"
+// @Header 200 {string} ETag "Entity tag of the updated photo"
 // @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid request payload"
 // @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
 // @Failure 403 {object} response.Response{error=response.ErrorInfo} "User doesn't have access to the photo"
 // @Failure 404 {object} response.Response{error=response.ErrorInfo} "Photo not found"
+// @Failure 412 {object} response.Response{error=response.ErrorInfo} "Photo was modified since the given ETag"
 // @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
 // @Router /photos/{id} [put]
 func (h *PhotoHandler) Update(w http.ResponseWriter, r *http.Request) {
@@ -203,12 +483,61 @@ func (h *PhotoHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	updatedPhoto, err := h.photoService.UpdatePhoto(r.Context(), photoID, input, userID)
+	updatedPhoto, err := h.photoService.UpdatePhoto(r.Context(), photoID, input, parseIfMatch(r), userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", updatedPhoto.ETag())
+	response.JSON(w, http.StatusOK, updatedPhoto)
+}
+
+// Patch handles partially updating a photo's metadata
+// @Summary Partially update photo metadata
+// @Description Update the title, description and visibility of a photo with a JSON Merge Patch (RFC 7396). Members missing from the patch are left unchanged; null clears the description.
+// @Tags photos
+// @Accept json
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "Photo ID"
+// @Param input body service.PhotoPatchDocument true "Merge patch, any subset of the members"
+// @Param If-Match header string false "Only update if the photo's ETag matches"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=service.PhotoResponse} "Photo updated successfully"
+// @Header 200 {string} ETag "Entity tag of the updated photo"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid merge patch"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User doesn't have access to the photo"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Photo not found"
+// @Failure 412 {object} response.Response{error=response.ErrorInfo} "Photo was modified since the given ETag"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /photos/{id} [patch]
+func (h *PhotoHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	userID, photoID, ok := parseResourceParams(w, r, "invalid photo ID")
+	if !ok {
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "content type must be application/merge-patch+json"))
+		return
+	}
+
+	patch, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPatchSize))
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid request payload"))
+		return
+	}
+
+	updatedPhoto, err := h.photoService.PatchPhoto(r.Context(), photoID, patch, parseIfMatch(r), userID)
 	if err != nil {
 		response.Error(w, err)
 		return
 	}
 
+	w.Header().Set("ETag", updatedPhoto.ETag())
 	response.JSON(w, http.StatusOK, updatedPhoto)
 }
 
@@ -245,13 +574,380 @@ func (h *PhotoHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	response.NoContent(w)
 }
 
+// Gallery handles listing a user's public photos
+// @Summary List a user's public photos
+// @Description Get a paginated list of the public photos of a user. No authentication required.
+// @Tags photos
+// @Produce json
+// @Param username path string true "Username"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 10, max: 100)"
+// @Success 200 {object} response.Response{data=service.PhotosResponse} "Photos retrieved successfully"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "User not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /users/{username}/photos [get]
+func (h *PhotoHandler) Gallery(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+	page, pageSize := parsePagination(r)
+
+	gallery, err := h.photoService.GetUserGallery(r.Context(), username, page, pageSize)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, gallery)
+}
+
+// Feed handles listing the most recent public photos
+// @Summary Public photo feed
+// @Description Get the most recent public photos of all users using cursor pagination. No authentication required.
+// @Tags photos
+// @Produce json
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param limit query int false "Page size (default: 20, max: 100)"
+// @Success 200 {object} response.Response{data=service.PhotoFeedResponse} "Photos retrieved successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid cursor"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /photos/public [get]
+func (h *PhotoHandler) Feed(w http.ResponseWriter, r *http.Request) {
+	cursor := r.URL.Query().Get("cursor")
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limitInt, err := strconv.Atoi(limitStr)
+		if err == nil {
+			limit = limitInt
+		}
+	}
+
+	feed, err := h.photoService.GetPublicFeed(r.Context(), cursor, limit)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, feed)
+}
+
+// Timeline handles counting photos per date bucket
+// @Summary Photo timeline
+// @Description Count the authenticated user's photos per year, month or day, newest first. Photos are dated by when they were taken if known, and by when they were uploaded otherwise. Pass a bucket key to the photo list's bucket parameter to jump to it.
+// @Tags photos
+// @Produce json
+// @Param granularity query string false "Bucket size: year, month or day (default: month)"
+// @Param tz query string false "IANA time zone to split dates in (default: the user's time zone)"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=service.TimelineResponse} "Timeline retrieved successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid granularity or time zone"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /photos/timeline [get]
+func (h *PhotoHandler) Timeline(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	timeline, err := h.photoService.GetTimeline(r.Context(), userID, query.Get("granularity"), query.Get("tz"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, timeline)
+}
+
+// Places handles listing the places of the current user's photos
+// @Summary List photo places
+// @Description List the places where the authenticated user's photos were taken, most photographed first, with a recent photo of each. Places are resolved from the photo locations.
+// @Tags photos
+// @Produce json
+// @Param q query string false "Only places whose name or country contains this text"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=[]service.PhotoPlaceResponse} "Places retrieved successfully"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /photos/places [get]
+func (h *PhotoHandler) Places(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	places, err := h.photoService.GetPlaces(r.Context(), userID, r.URL.Query().Get("q"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, places)
+}
+
+// Geo handles searching photos by location
+// @Summary Search photos by location
+// @Description List the authenticated user's geotagged photos inside a bounding box, newest first, or within a radius of a point, nearest first. Exactly one of bbox and near is required.
+// @Tags photos
+// @Produce json
+// @Param bbox query string false "Bounding box as minLng,minLat,maxLng,maxLat; minLng may exceed maxLng to cross the antimeridian"
+// @Param near query string false "Center point as lat,lng"
+// @Param radius query number false "Radius around near in meters (default: 1000, max: 100000)"
+// @Param limit query int false "Maximum number of photos (default: 100, max: 500)"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=service.PhotoGeoSearchResponse} "Photos retrieved successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid location query"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /photos/geo [get]
+func (h *PhotoHandler) Geo(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	input := service.PhotoGeoSearchInput{
+		BBox: query.Get("bbox"),
+		Near: query.Get("near"),
+	}
+	if radius := query.Get("radius"); radius != "" {
+		input.Radius, err = strconv.ParseFloat(radius, 64)
+		if err != nil {
+			response.Error(w, apperrors.New(apperrors.BadRequest, "invalid radius"))
+			return
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		input.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			response.Error(w, apperrors.New(apperrors.BadRequest, "invalid limit"))
+			return
+		}
+	}
+
+	photos, err := h.photoService.SearchPhotosByLocation(r.Context(), input, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, photos)
+}
+
+// GeoClusters handles clustering photos for a map
+// @Summary Cluster photos on a map
+// @Description Group the authenticated user's geotagged photos inside a bounding box into clusters sized for the given map zoom level. The response is a GeoJSON FeatureCollection of points, returned without the usual response envelope.
+// @Tags photos
+// @Produce application/geo+json
+// @Param bbox query string true "Bounding box as minLng,minLat,maxLng,maxLat; minLng may exceed maxLng to cross the antimeridian"
+// @Param zoom query int true "Map zoom level (0-22)"
+// @Security Bearer
+// @Success 200 {object} service.GeoJSONFeatureCollection "Photo clusters"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid bounding box or zoom"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /photos/geo/clusters [get]
+func (h *PhotoHandler) GeoClusters(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	zoom, err := strconv.Atoi(r.URL.Query().Get("zoom"))
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid zoom"))
+		return
+	}
+
+	clusters, err := h.photoService.ClusterPhotos(r.Context(), r.URL.Query().Get("bbox"), zoom, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/geo+json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(clusters)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func (h *PhotoHandler) RegisterRoutes(r chi.Router, authMiddleware func(next http.Handler) http.Handler) {
+	r.Get("/public", h.Feed)
+
 	r.Group(func(r chi.Router) {
 		r.Use(authMiddleware)
-		r.Post("/", h.Upload)
+		r.With(middleware.Idempotent(h.idempotency, maxUploadSize)).Post("/", h.Upload)
+		r.With(middleware.Idempotent(h.idempotency, maxBatchUploadSize)).Post("/batch", h.BatchUpload)
+		r.With(middleware.Idempotent(h.idempotency, maxPatchSize)).Post("/bulk", h.Bulk)
 		r.Get("/", h.List)
+		r.Get("/shared", h.SharedWithMe)
+		r.Get("/timeline", h.Timeline)
+		r.Get("/places", h.Places)
+		r.Get("/geo", h.Geo)
+		r.Get("/geo/clusters", h.GeoClusters)
 		r.Get("/{id}", h.GetByID)
+		r.Get("/{id}/content", h.Content)
 		r.Put("/{id}", h.Update)
+		r.Patch("/{id}", h.Patch)
 		r.Delete("/{id}", h.Delete)
 	})
 }
+
+func (h *PhotoHandler) RegisterUserRoutes(r chi.Router) {
+	r.Get("/{username}/photos", h.Gallery)
+}
+
+func parsePagination(r *http.Request) (int, int) {
+	page := 1
+	pageSize := 10
+
+	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
+		pageInt, err := strconv.Atoi(pageStr)
+		if err == nil && pageInt > 0 {
+			page = pageInt
+		}
+	}
+
+	if pageSizeStr := r.URL.Query().Get("page_size"); pageSizeStr != "" {
+		pageSizeInt, err := strconv.Atoi(pageSizeStr)
+		if err == nil && pageSizeInt > 0 && pageSizeInt <= 100 {
+			pageSize = pageSizeInt
+		}
+	}
+
+	return page, pageSize
+}
+
+// parseIfMatch returns the entity tags listed in the If-Match header.
+func parseIfMatch(r *http.Request) []string {
+	var tags []string
+	for _, header := range r.Header.Values("If-Match") {
+		for _, tag := range strings.Split(header, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// batchUploadFields are the metadata fields a batch upload takes per file.
+var batchUploadFields = map[string]bool{
+	"title":         true,
+	"description":   true,
+	"latitude":      true,
+	"longitude":     true,
+	"taken_at":      true,
+	"custom_fields": true,
+}
+
+// batchFormFields returns the metadata of each of the count files of a batch
+// upload. Fields are named after the index of the file they belong to, e.g.
+// "title[0]", so a file leaving a field out can't shift the values of the
+// files after it. Fields without a valid index are rejected.
+func batchFormFields(values map[string][]string, count int) ([]map[string]string, error) {
+	fields := make([]map[string]string, count)
+	for i := range fields {
+		fields[i] = map[string]string{}
+	}
+
+	for key, value := range values {
+		name, index, indexed := strings.Cut(key, "[")
+		if !batchUploadFields[name] {
+			continue
+		}
+		if !indexed || !strings.HasSuffix(index, "]") {
+			return nil, apperrors.NewWithFormat(apperrors.BadRequest, "field %q must name the index of its file, e.g. %s[0]", key, name)
+		}
+		i, err := strconv.Atoi(strings.TrimSuffix(index, "]"))
+		if err != nil || i < 0 || i >= count {
+			return nil, apperrors.NewWithFormat(apperrors.BadRequest, "field %q doesn't refer to one of the %d files", key, count)
+		}
+		if len(value) != 1 {
+			return nil, apperrors.NewWithFormat(apperrors.BadRequest, "field %q is set more than once", key)
+		}
+		fields[i][name] = value[0]
+	}
+
+	return fields, nil
+}
+
+func openFileHeader(header *multipart.FileHeader) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		return header.Open()
+	}
+}
+
+// parseLocation parses the optional latitude and longitude form fields of an
+// upload. Range checks are left to the service.
+func parseLocation(latitude, longitude string) (*float64, *float64, error) {
+	if latitude == "" && longitude == "" {
+		return nil, nil, nil
+	}
+	if latitude == "" || longitude == "" {
+		return nil, nil, apperrors.New(apperrors.BadRequest, "latitude and longitude must be set together")
+	}
+
+	lat, err := strconv.ParseFloat(latitude, 64)
+	if err != nil {
+		return nil, nil, apperrors.New(apperrors.BadRequest, "invalid latitude")
+	}
+	lng, err := strconv.ParseFloat(longitude, 64)
+	if err != nil {
+		return nil, nil, apperrors.New(apperrors.BadRequest, "invalid longitude")
+	}
+
+	return &lat, &lng, nil
+}
+
+// parseTakenAt parses the optional taken_at form field of an upload.
+func parseTakenAt(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	takenAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, apperrors.New(apperrors.BadRequest, "taken_at must be in RFC 3339 format")
+	}
+	return &takenAt, nil
+}
+
+// parseCustomFields parses the optional custom_fields form field of an
+// upload, a JSON object of values by field name.
+func parseCustomFields(value string) (map[string]interface{}, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return nil, apperrors.New(apperrors.BadRequest, "custom_fields must be a JSON object")
+	}
+	return fields, nil
+}
+
+// customFieldFilters collects the field.<name> query parameters of a photo
+// list request. A field repeated with several values is rejected rather
+// than filtered by one of them.
+func customFieldFilters(query url.Values) (map[string]string, error) {
+	filters := make(map[string]string)
+	for key, values := range query {
+		name, ok := strings.CutPrefix(key, "field.")
+		if !ok || name == "" || len(values) == 0 {
+			continue
+		}
+		if len(values) > 1 {
+			return nil, apperrors.NewWithFormat(apperrors.BadRequest, "custom field %s can only be filtered by one value", name)
+		}
+		filters[name] = values[0]
+	}
+	return filters, nil
+}
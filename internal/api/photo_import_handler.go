@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mmd-moradi/goup/internal/middleware"
+	"github.com/mmd-moradi/goup/internal/service"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/mmd-moradi/goup/pkg/response"
+)
+
+// maxImportRequestSize limits the body of a photo import request.
+const maxImportRequestSize = 64 << 10
+
+type PhotoImportHandler struct {
+	importService *service.PhotoImportService
+	idempotency   *middleware.IdempotencyStore
+}
+
+func NewPhotoImportHandler(importService *service.PhotoImportService, idempotency *middleware.IdempotencyStore) *PhotoImportHandler {
+	return &PhotoImportHandler{
+		importService: importService,
+		idempotency:   idempotency,
+	}
+}
+
+// Import handles saving an image from a URL
+// @Summary Import a photo from a URL
+// @Description Fetch an image from an http or https URL on the server and save it as a new photo. Only public addresses are contacted, at most 3 redirects are followed and the image is limited to 10MB.
+// @Tags photos
+// @Accept json
+// @Produce json
+// @Param input body service.PhotoImportInput true "Image URL and photo metadata"
+// @Param Idempotency-Key header string false "Unique key making retries of the request safe, kept for 24 hours"
+// @Security Bearer
+// @Success 201 {object} response.Response{data=service.PhotoResponse} "Photo imported successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid or disallowed URL, or not an image"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "Email address not verified and unverified users can't upload"
+// @Failure 409 {object} response.Response{error=response.ErrorInfo} "A request with the same idempotency key is in progress"
+// @Failure 422 {object} response.Response{error=response.ErrorInfo} "Idempotency key reused with a different request"
+// @Failure 502 {object} response.Response{error=response.ErrorInfo} "The URL could not be fetched"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /photos/import [post]
+func (h *PhotoImportHandler) Import(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	var input service.PhotoImportInput
+	err = json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid request payload"))
+		return
+	}
+
+	photo, err := h.importService.ImportPhoto(r.Context(), input, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", photo.ETag())
+	response.JSON(w, http.StatusCreated, photo)
+}
+
+func (h *PhotoImportHandler) RegisterRoutes(r chi.Router, authMiddleware func(next http.Handler) http.Handler) {
+	r.Group(func(r chi.Router) {
+		r.Use(authMiddleware)
+		r.With(middleware.Idempotent(h.idempotency, maxImportRequestSize)).Post("/import", h.Import)
+	})
+}
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/middleware"
+	"github.com/mmd-moradi/goup/internal/service"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/mmd-moradi/goup/pkg/response"
+)
+
+type PhotoVersionHandler struct {
+	versionService *service.PhotoVersionService
+}
+
+func NewPhotoVersionHandler(versionService *service.PhotoVersionService) *PhotoVersionHandler {
+	return &PhotoVersionHandler{
+		versionService: versionService,
+	}
+}
+
+// ReplaceContent handles uploading a new binary for an existing photo
+// @Summary Replace a photo's content
+// @Description Upload a new file for an existing photo. The previous file is kept as a version of the photo.
+// @Tags versions
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Photo ID"
+// @Param file formData file true "New photo file"
+// @Param If-Match header string false "Only replace the content if the photo's ETag matches"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=service.PhotoResponse} "Photo content replaced successfully"
+// @Header 200 {string} ETag "Entity tag of the updated photo"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid request payload"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User can't edit the photo"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Photo not found"
+// @Failure 412 {object} response.Response{error=response.ErrorInfo} "Photo was modified since the given ETag or during the upload"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /photos/{id}/content [put]
+func (h *PhotoVersionHandler) ReplaceContent(w http.ResponseWriter, r *http.Request) {
+	userID, photoID, ok := parseResourceParams(w, r, "invalid photo ID")
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, service.MaxPhotoSize+1<<20)
+	if err := r.ParseMultipartForm(service.MaxPhotoSize); err != nil {
+		response.Error(w, apperrors.NewWithFormat(apperrors.BadRequest, "failed to parse form: %v", err))
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		response.Error(w, apperrors.NewWithFormat(apperrors.BadRequest, "failed to get file: %v", err))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		response.Error(w, apperrors.NewWithFormat(apperrors.InternalServer, "failed to read file: %v", err))
+		return
+	}
+
+	input := service.PhotoContentInput{
+		FileName:    header.Filename,
+		FileSize:    header.Size,
+		ContentType: header.Header.Get("Content-Type"),
+	}
+
+	photo, err := h.versionService.ReplaceContent(r.Context(), photoID, input, parseIfMatch(r), userID, data)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", photo.ETag())
+	response.JSON(w, http.StatusOK, photo)
+}
+
+// List handles listing the previous versions of a photo
+// @Summary List photo versions
+// @Description List the previous versions of a photo, newest first. Only the owner and editors can see the version history.
+// @Tags versions
+// @Produce json
+// @Param id path string true "Photo ID"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=[]service.PhotoVersionResponse} "Versions retrieved successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid photo ID"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User can't edit the photo"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Photo not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /photos/{id}/versions [get]
+func (h *PhotoVersionHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, photoID, ok := parseResourceParams(w, r, "invalid photo ID")
+	if !ok {
+		return
+	}
+
+	versions, err := h.versionService.ListVersions(r.Context(), photoID, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, versions)
+}
+
+// Download handles downloading a previous version of a photo
+// @Summary Download a photo version
+// @Description Download the file of a previous version of a photo. Only the owner and editors can download previous versions.
+// @Tags versions
+// @Produce octet-stream
+// @Param id path string true "Photo ID"
+// @Param version path int true "Version number"
+// @Security Bearer
+// @Success 200 {file} binary "Version file"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid photo ID or version"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User can't edit the photo"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Photo or version not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /photos/{id}/versions/{version}/content [get]
+func (h *PhotoVersionHandler) Download(w http.ResponseWriter, r *http.Request) {
+	userID, photoID, version, ok := parseVersionParams(w, r)
+	if !ok {
+		return
+	}
+
+	content, err := h.versionService.GetVersionContent(r.Context(), photoID, version, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", content.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(content.Data)))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": content.FileName}))
+	w.WriteHeader(http.StatusOK)
+	w.Write(content.Data)
+}
+
+// Revert handles restoring a previous version of a photo
+// @Summary Revert to a photo version
+// @Description Make a previous version the current content of the photo. The replaced content is kept as a new version.
+// @Tags versions
+// @Produce json
+// @Param id path string true "Photo ID"
+// @Param version path int true "Version number"
+// @Param If-Match header string false "Only revert if the photo's ETag matches"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=service.PhotoResponse} "Photo reverted successfully"
+// @Header 200 {string} ETag "Entity tag of the updated photo"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid photo ID or version"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User can't edit the photo"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Photo or version not found"
+// @Failure 412 {object} response.Response{error=response.ErrorInfo} "Photo was modified since the given ETag or concurrently"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /photos/{id}/versions/{version}/revert [post]
+func (h *PhotoVersionHandler) Revert(w http.ResponseWriter, r *http.Request) {
+	userID, photoID, version, ok := parseVersionParams(w, r)
+	if !ok {
+		return
+	}
+
+	photo, err := h.versionService.RevertToVersion(r.Context(), photoID, version, parseIfMatch(r), userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", photo.ETag())
+	response.JSON(w, http.StatusOK, photo)
+}
+
+func (h *PhotoVersionHandler) RegisterRoutes(r chi.Router, authMiddleware func(next http.Handler) http.Handler) {
+	r.Group(func(r chi.Router) {
+		r.Use(authMiddleware)
+		r.Put("/{id}/content", h.ReplaceContent)
+		r.Get("/{id}/versions", h.List)
+		r.Get("/{id}/versions/{version}/content", h.Download)
+		r.Post("/{id}/versions/{version}/revert", h.Revert)
+	})
+}
+
+func parseVersionParams(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, int, bool) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return uuid.Nil, uuid.Nil, 0, false
+	}
+	photoID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid photo ID"))
+		return uuid.Nil, uuid.Nil, 0, false
+	}
+	version, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil || version < 1 {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid version number"))
+		return uuid.Nil, uuid.Nil, 0, false
+	}
+	return userID, photoID, version, true
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -9,11 +10,14 @@ import (
 	"github.com/go-chi/cors"
 	"github.com/mmd-moradi/goup/configs"
 	"github.com/mmd-moradi/goup/internal/auth"
+	"github.com/mmd-moradi/goup/internal/geocoding"
+	"github.com/mmd-moradi/goup/internal/mail"
 	customMiddleware "github.com/mmd-moradi/goup/internal/middleware"
 	repositories "github.com/mmd-moradi/goup/internal/repository"
 	"github.com/mmd-moradi/goup/internal/repository/postgres"
 	"github.com/mmd-moradi/goup/internal/service"
 	"github.com/mmd-moradi/goup/internal/storage"
+	"github.com/mmd-moradi/goup/internal/urlfetch"
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	httpSwagger "github.com/swaggo/http-swagger"
@@ -26,14 +30,32 @@ var WhiteListOrigins = map[string]bool{
 
 type Server struct {
 	*http.Server
-	router     chi.Router
-	logger     zerolog.Logger
-	tokenSvc   *auth.TokenService
-	userSvc    *service.UserService
-	photoSvc   *service.PhotoService
-	storageSvc storage.StorageService
-	userRepo   repositories.UserRepository
-	photoRepo  repositories.PhotoRepository
+	router       chi.Router
+	logger       zerolog.Logger
+	tokenSvc     *auth.TokenService
+	userSvc      *service.UserService
+	photoSvc     *service.PhotoService
+	shareSvc     *service.ShareService
+	albumSvc     *service.AlbumService
+	grantSvc     *service.GrantService
+	exportSvc    *service.ExportService
+	fieldSvc     *service.CustomFieldService
+	versionSvc   *service.PhotoVersionService
+	importSvc    *service.PhotoImportService
+	transferSvc  *service.OwnershipTransferService
+	erasureSvc   *service.AccountErasureService
+	authz        *service.Authorizer
+	idempotent   *customMiddleware.IdempotencyStore
+	storageSvc   storage.StorageService
+	userRepo     repositories.UserRepository
+	photoRepo    repositories.PhotoRepository
+	shareRepo    repositories.ShareLinkRepository
+	albumRepo    repositories.AlbumRepository
+	grantRepo    repositories.GrantRepository
+	exportRepo   repositories.ExportJobRepository
+	fieldRepo    repositories.CustomFieldRepository
+	transferRepo repositories.OwnershipTransferRepository
+	auditRepo    repositories.AuditRepository
 }
 
 func NewServer(
@@ -50,9 +72,9 @@ func NewServer(
 
 	router.Use(cors.Handler(cors.Options{
 		AllowOriginFunc:  AllowOriginFunc,
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Share-Password", "If-Match", "Idempotency-Key"},
+		ExposedHeaders:   []string{"Link", "ETag", "Idempotent-Replayed"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
@@ -87,6 +109,13 @@ func (s *Server) InitDependencies(cfg *configs.Config) error {
 
 	s.userRepo = postgres.NewUserRepository(db)
 	s.photoRepo = postgres.NewPhotoRepository(db)
+	s.shareRepo = postgres.NewShareLinkRepository(db)
+	s.albumRepo = postgres.NewAlbumRepository(db)
+	s.grantRepo = postgres.NewGrantRepository(db)
+	s.exportRepo = postgres.NewExportJobRepository(db)
+	s.fieldRepo = postgres.NewCustomFieldRepository(db)
+	s.transferRepo = postgres.NewOwnershipTransferRepository(db)
+	s.auditRepo = postgres.NewAuditRepository(db)
 
 	redisClient := redis.NewClient(&redis.Options{
 		Addr:     cfg.Redis.Addr,
@@ -95,14 +124,56 @@ func (s *Server) InitDependencies(cfg *configs.Config) error {
 	})
 
 	s.tokenSvc = auth.NewTokenService(redisClient, &cfg.Auth)
+	s.idempotent = customMiddleware.NewIdempotencyStore(redisClient, cfg.Idempotency.TTL, cfg.Idempotency.LockTimeout, s.logger)
 
 	s.storageSvc, err = storage.NewS3StorageService(&cfg.AWS, s.logger)
 	if err != nil {
 		return err
 	}
 
-	s.userSvc = service.NewUserService(s.userRepo, s.tokenSvc, s.logger)
-	s.photoSvc = service.NewPhotoService(s.photoRepo, s.userRepo, s.storageSvc, s.logger)
+	s.authz = service.NewAuthorizer(s.grantRepo, s.userRepo, cfg.Auth.UnverifiedRestrictions)
+
+	geocoder, err := geocoding.Load(&cfg.Geocoding)
+	if err != nil {
+		return err
+	}
+	s.logger.Info().Int("places", geocoder.Len()).Msg("geocoding dataset loaded")
+
+	mailer, err := mail.New(&cfg.Mail, s.logger)
+	if err != nil {
+		return err
+	}
+
+	s.userSvc = service.NewUserService(s.userRepo, s.photoRepo, s.auditRepo, s.tokenSvc, mailer, service.AccountLinks{
+		PasswordReset:     cfg.Auth.PasswordResetURL,
+		EmailVerification: cfg.Auth.EmailVerificationURL,
+	}, cfg.Auth.AccountDeletionGracePeriod,
+		auth.NewAttemptLimiter(redisClient, "mfa_failures:", service.MFAMaxFailures, service.MFALockout), s.logger)
+	s.photoSvc = service.NewPhotoService(s.photoRepo, s.userRepo, s.albumRepo, s.fieldRepo, s.storageSvc, s.authz, geocoder, s.logger)
+	s.shareSvc = service.NewShareService(s.shareRepo, s.photoRepo, s.storageSvc, s.authz,
+		auth.NewAttemptLimiter(redisClient, "share_password_failures:", service.SharePasswordMaxFailures, service.SharePasswordLockout), s.logger)
+	s.albumSvc = service.NewAlbumService(s.albumRepo, s.photoRepo, s.authz, s.logger)
+	s.grantSvc = service.NewGrantService(s.grantRepo, s.photoRepo, s.albumRepo, s.userRepo, s.authz, s.logger)
+	s.versionSvc = service.NewPhotoVersionService(s.photoRepo, s.storageSvc, s.authz, cfg.Photo.MaxVersionsPerUser, s.logger)
+	s.fieldSvc = service.NewCustomFieldService(s.fieldRepo, s.logger)
+	s.exportSvc = service.NewExportService(s.exportRepo, s.photoRepo, s.albumRepo, s.userRepo, s.shareRepo, s.grantRepo, s.fieldRepo,
+		s.transferRepo, s.auditRepo, s.tokenSvc, s.storageSvc, s.authz, mailer, s.logger)
+	s.transferSvc = service.NewOwnershipTransferService(s.transferRepo, s.photoRepo, s.albumRepo, s.userRepo, s.auditRepo, s.storageSvc, s.versionSvc, s.logger)
+	s.erasureSvc = service.NewAccountErasureService(s.userRepo, s.photoRepo, s.storageSvc, s.tokenSvc, s.logger)
+	s.importSvc = service.NewPhotoImportService(s.photoSvc, urlfetch.New(urlfetch.Config{
+		Timeout:      cfg.Photo.ImportTimeout,
+		MaxSize:      service.MaxPhotoSize,
+		MaxRedirects: cfg.Photo.ImportMaxRedirects,
+	}), s.logger)
+
+	go func() {
+		if err := s.photoSvc.BackfillPlaces(context.Background()); err != nil {
+			s.logger.Error().Err(err).Msg("failed to backfill photo places")
+		}
+	}()
+	go s.erasureSvc.Run(context.Background())
+	go s.exportSvc.Run(context.Background())
+	go s.transferSvc.Run(context.Background())
 
 	return nil
 }
@@ -110,9 +181,22 @@ func (s *Server) InitDependencies(cfg *configs.Config) error {
 func (s *Server) routes() {
 
 	userHandler := NewUserHandler(s.userSvc)
-	photoHandler := NewPhotoHandler(s.photoSvc)
-
-	authMiddleware := customMiddleware.Authenticate(s.tokenSvc)
+	photoHandler := NewPhotoHandler(s.photoSvc, s.idempotent)
+	shareHandler := NewShareHandler(s.shareSvc, s.idempotent)
+	albumHandler := NewAlbumHandler(s.albumSvc)
+	grantHandler := NewGrantHandler(s.grantSvc)
+	exportHandler := NewExportHandler(s.exportSvc)
+	versionHandler := NewPhotoVersionHandler(s.versionSvc)
+	importHandler := NewPhotoImportHandler(s.importSvc, s.idempotent)
+	fieldHandler := NewCustomFieldHandler(s.fieldSvc)
+	transferHandler := NewOwnershipTransferHandler(s.transferSvc)
+
+	// Authenticated responses depend on grants that can be revoked at any
+	// time, so clients and proxies must not serve them from a cache.
+	authenticate := customMiddleware.Authenticate(s.tokenSvc)
+	authMiddleware := func(next http.Handler) http.Handler {
+		return authenticate(customMiddleware.NoStore(next))
+	}
 
 	s.router.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -123,13 +207,38 @@ func (s *Server) routes() {
 		httpSwagger.URL("/swagger/doc.json"),
 	))
 
+	s.router.Route("/s", func(r chi.Router) {
+		shareHandler.RegisterPublicRoutes(r)
+	})
+
 	s.router.Route("/api", func(r chi.Router) {
 		r.Route("/v1", func(r chi.Router) {
 			r.Route("/auth", func(r chi.Router) {
 				userHandler.RegisterRoutes(r, authMiddleware)
+				exportHandler.RegisterAccountRoutes(r, authMiddleware)
+			})
+			r.Route("/users", func(r chi.Router) {
+				photoHandler.RegisterUserRoutes(r)
 			})
 			r.Route("/photos", func(r chi.Router) {
 				photoHandler.RegisterRoutes(r, authMiddleware)
+				shareHandler.RegisterRoutes(r, authMiddleware)
+				grantHandler.RegisterPhotoRoutes(r, authMiddleware)
+				versionHandler.RegisterRoutes(r, authMiddleware)
+				importHandler.RegisterRoutes(r, authMiddleware)
+			})
+			r.Route("/albums", func(r chi.Router) {
+				albumHandler.RegisterRoutes(r, authMiddleware)
+				grantHandler.RegisterAlbumRoutes(r, authMiddleware)
+			})
+			r.Route("/exports", func(r chi.Router) {
+				exportHandler.RegisterRoutes(r, authMiddleware)
+			})
+			r.Route("/custom-fields", func(r chi.Router) {
+				fieldHandler.RegisterRoutes(r, authMiddleware)
+			})
+			r.Route("/transfers", func(r chi.Router) {
+				transferHandler.RegisterRoutes(r, authMiddleware)
 			})
 		})
 	})
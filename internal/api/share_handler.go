@@ -0,0 +1,238 @@
+package api
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/middleware"
+	"github.com/mmd-moradi/goup/internal/service"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/mmd-moradi/goup/pkg/response"
+)
+
+const (
+	sharePasswordHeader = "X-Share-Password"
+	// maxShareCreateSize limits the body of a create share link request.
+	maxShareCreateSize = 64 << 10
+)
+
+type ShareHandler struct {
+	shareService *service.ShareService
+	idempotency  *middleware.IdempotencyStore
+}
+
+func NewShareHandler(shareService *service.ShareService, idempotency *middleware.IdempotencyStore) *ShareHandler {
+	return &ShareHandler{
+		shareService: shareService,
+		idempotency:  idempotency,
+	}
+}
+
+// Create handles creating a share link for a photo
+// @Summary Create a share link
+// @Description Create a public share link for a photo with optional expiry, password and view limit
+// @Tags shares
+// @Accept json
+// @Produce json
+// @Param id path string true "Photo ID"
+// @Param input body service.ShareLinkCreateInput true "Share link options"
+// @Param Idempotency-Key header string false "Unique key making retries of the request safe, kept for 24 hours"
+// @Security Bearer
+// @Success 201 {object} response.Response{data=service.ShareLinkResponse} "Share link created successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid request payload"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 409 {object} response.Response{error=response.ErrorInfo} "A request with the same idempotency key is in progress"
+// @Failure 422 {object} response.Response{error=response.ErrorInfo} "Idempotency key reused with a different request"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User doesn't have access to the photo, or email address not verified"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Photo not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /photos/{id}/shares [post]
+func (h *ShareHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	photoID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid photo ID"))
+		return
+	}
+
+	var input service.ShareLinkCreateInput
+	err = json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid request payload"))
+		return
+	}
+
+	link, err := h.shareService.CreateShareLink(r.Context(), photoID, input, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, link)
+}
+
+// List handles listing the share links of a photo
+// @Summary List share links
+// @Description List all share links created for a photo
+// @Tags shares
+// @Produce json
+// @Param id path string true "Photo ID"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=[]service.ShareLinkResponse} "Share links retrieved successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid photo ID"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User doesn't have access to the photo"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Photo not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /photos/{id}/shares [get]
+func (h *ShareHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	photoID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid photo ID"))
+		return
+	}
+
+	links, err := h.shareService.ListShareLinks(r.Context(), photoID, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, links)
+}
+
+// Revoke handles revoking a share link
+// @Summary Revoke a share link
+// @Description Revoke a share link so it can no longer be used
+// @Tags shares
+// @Param id path string true "Photo ID"
+// @Param shareID path string true "Share link ID"
+// @Security Bearer
+// @Success 204 "Share link revoked successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid ID"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "User doesn't have access to the share link"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Share link not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /photos/{id}/shares/{shareID} [delete]
+func (h *ShareHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	photoID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid photo ID"))
+		return
+	}
+	shareID, err := uuid.Parse(chi.URLParam(r, "shareID"))
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid share link ID"))
+		return
+	}
+
+	err = h.shareService.RevokeShareLink(r.Context(), photoID, shareID, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// Resolve handles the unauthenticated GET /s/{token} and returns the shared
+// photo's metadata. Password protected links expect the password in the
+// X-Share-Password header.
+// @Summary Resolve a share link
+// @Description Get the metadata of a photo shared by link. The route is served at /s/{token}, outside the /api/v1 base path. Password protected links expect the password in the X-Share-Password header; a client is locked out of a link for a while after too many wrong passwords.
+// @Tags shares
+// @Produce json
+// @Param token path string true "Share link token"
+// @Param X-Share-Password header string false "Password of a protected link"
+// @Success 200 {object} response.Response{data=service.SharedPhotoResponse} "Shared photo retrieved successfully"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "Invalid or missing share link password"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "Share link has expired or reached its view limit"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Share link not found"
+// @Failure 429 {object} response.Response{error=response.ErrorInfo} "Too many wrong passwords"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /s/{token} [get]
+func (h *ShareHandler) Resolve(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+
+	photo, err := h.shareService.GetSharedPhoto(r.Context(), token, r.Header.Get(sharePasswordHeader), clientInfo(r).IP)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, http.StatusOK, photo)
+}
+
+// Content handles the unauthenticated GET /s/{token}/content and streams the
+// shared photo. Each call counts as one view. Passing ?download=true serves
+// the photo as an attachment, which the link must allow.
+// @Summary Get shared photo content
+// @Description Stream the binary of a photo shared by link, counting one view against the link. The route is served at /s/{token}/content, outside the /api/v1 base path. Passing download=true serves the photo as an attachment, which the link must allow; a refused download doesn't count as a view.
+// @Tags shares
+// @Produce octet-stream
+// @Param token path string true "Share link token"
+// @Param download query bool false "Serve the photo as an attachment"
+// @Param X-Share-Password header string false "Password of a protected link"
+// @Success 200 {file} binary "Photo content"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "Invalid or missing share link password"
+// @Failure 403 {object} response.Response{error=response.ErrorInfo} "Share link has expired, reached its view limit or doesn't allow downloads"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Share link not found"
+// @Failure 429 {object} response.Response{error=response.ErrorInfo} "Too many wrong passwords"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /s/{token}/content [get]
+func (h *ShareHandler) Content(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+	download := r.URL.Query().Get("download") == "true"
+
+	content, err := h.shareService.GetSharedPhotoContent(r.Context(), token, r.Header.Get(sharePasswordHeader), clientInfo(r).IP, download)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	disposition := "inline"
+	if download {
+		disposition = "attachment"
+	}
+
+	w.Header().Set("Content-Type", content.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(content.Data)))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": content.FileName}))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(content.Data)
+}
+
+func (h *ShareHandler) RegisterRoutes(r chi.Router, authMiddleware func(next http.Handler) http.Handler) {
+	r.Group(func(r chi.Router) {
+		r.Use(authMiddleware)
+		r.With(middleware.Idempotent(h.idempotency, maxShareCreateSize)).Post("/{id}/shares", h.Create)
+		r.Get("/{id}/shares", h.List)
+		r.Delete("/{id}/shares/{shareID}", h.Revoke)
+	})
+}
+
+func (h *ShareHandler) RegisterPublicRoutes(r chi.Router) {
+	r.Get("/{token}", h.Resolve)
+	r.Get("/{token}/content", h.Content)
+}
@@ -2,16 +2,23 @@ package api
 
 import (
 	"encoding/json"
+	"io"
+	"mime"
+	"net"
 	"net/http"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/mmd-moradi/goup/internal/auth"
 	"github.com/mmd-moradi/goup/internal/middleware"
 	"github.com/mmd-moradi/goup/internal/service"
 	"github.com/mmd-moradi/goup/pkg/apperrors"
 	"github.com/mmd-moradi/goup/pkg/response"
 )
 
+// maxUserAgentLength bounds the user agent stored with a session.
+const maxUserAgentLength = 255
+
 type UserHandler struct {
 	userService *service.UserService
 }
@@ -45,7 +52,7 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 	input.Username = strings.TrimSpace(input.Username)
 	input.Email = strings.TrimSpace(input.Email)
 
-	authResponse, err := h.userService.Register(r.Context(), input)
+	authResponse, err := h.userService.Register(r.Context(), input, clientInfo(r))
 	if err != nil {
 		response.Error(w, err)
 		return
@@ -56,12 +63,13 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 // Login handles user authentication
 // @Summary Login a user
-// @Description Authenticate a user with email and password
+// @Description Authenticate a user with email and password. For users with two-factor authentication the response is a short-lived MFA challenge instead of tokens, and the login is finished at POST /auth/mfa/verify. Logging in during the grace period of an account deletion cancels the deletion.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param input body service.UserLoginInput true "User login information"
 // @Success 200 {object} response.Response{data=service.AuthResponse} "User logged in successfully"
+// @Success 202 {object} response.Response{data=service.MFAChallengeResponse} "Password correct, two-factor authentication code required"
 // @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid request payload"
 // @Failure 401 {object} response.Response{error=response.ErrorInfo} "Invalid email or password"
 // @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
@@ -76,19 +84,195 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	input.Email = strings.TrimSpace(input.Email)
 
-	authResponse, err := h.userService.Login(r.Context(), input)
+	result, err := h.userService.Login(r.Context(), input, clientInfo(r))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	if result.Challenge != nil {
+		response.JSON(w, http.StatusAccepted, result.Challenge)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, result.Auth)
+
+}
+
+// VerifyMFA handles finishing a login with two-factor authentication
+// @Summary Verify two-factor authentication
+// @Description Finish a login by sending the MFA token from POST /auth/login with a code from the authenticator app or an unused recovery code. Each code works once, and the MFA token is revoked after 5 wrong codes.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body service.MFAVerifyInput true "MFA token and code"
+// @Success 200 {object} response.Response{data=service.AuthResponse} "User logged in successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid request payload"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "Invalid or expired MFA token, or invalid code"
+// @Failure 429 {object} response.Response{error=response.ErrorInfo} "Too many wrong codes, try again later"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /auth/mfa/verify [post]
+func (h *UserHandler) VerifyMFA(w http.ResponseWriter, r *http.Request) {
+	var input service.MFAVerifyInput
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid request payload"))
+		return
+	}
+
+	authResponse, err := h.userService.VerifyMFA(r.Context(), input, clientInfo(r))
 	if err != nil {
 		response.Error(w, err)
 		return
 	}
 
 	response.JSON(w, http.StatusOK, authResponse)
+}
+
+// Refresh handles exchanging a refresh token for a new token pair
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new access token and refresh token. Each refresh token can be used once; presenting one that was already used revokes all tokens issued since the same login.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body service.UserRefreshInput true "Refresh token"
+// @Success 200 {object} response.Response{data=service.TokenResponse} "Tokens refreshed successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid request payload"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "Invalid, expired or reused refresh token"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /auth/refresh [post]
+func (h *UserHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var input service.UserRefreshInput
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid request payload"))
+		return
+	}
 
+	tokens, err := h.userService.Refresh(r.Context(), input, clientInfo(r))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, tokens)
+}
+
+// ForgotPassword handles requesting a password reset email
+// @Summary Request a password reset
+// @Description Email a single-use password reset link to the address if an account uses it. The response is the same whether or not an account exists.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body service.PasswordForgotInput true "Account email"
+// @Success 202 {object} response.Response{data=service.MessageResponse} "Request accepted"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid request payload"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /auth/password/forgot [post]
+func (h *UserHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var input service.PasswordForgotInput
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid request payload"))
+		return
+	}
+
+	input.Email = strings.TrimSpace(input.Email)
+
+	message, err := h.userService.ForgotPassword(r.Context(), input)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, message)
+}
+
+// ResetPassword handles setting a new password with a reset token
+// @Summary Reset password
+// @Description Set a new password with the token from a password reset email. The token can be used once, and every session of the user is logged out.
+// @Tags auth
+// @Accept json
+// @Param input body service.PasswordResetInput true "Reset token and new password"
+// @Success 204 "Password reset successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid request payload or invalid or expired token"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /auth/password/reset [post]
+func (h *UserHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var input service.PasswordResetInput
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid request payload"))
+		return
+	}
+
+	err = h.userService.ResetPassword(r.Context(), input)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// VerifyEmail handles verifying an email address
+// @Summary Verify email address
+// @Description Confirm the email address with the token from a verification email. Links sent to a previous address of the user no longer work.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body service.EmailVerifyInput true "Verification token"
+// @Success 200 {object} response.Response{data=service.UserResponse} "Email verified successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid request payload or invalid or expired token"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /auth/verify-email [post]
+func (h *UserHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var input service.EmailVerifyInput
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid request payload"))
+		return
+	}
+
+	user, err := h.userService.VerifyEmail(r.Context(), input)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, user)
+}
+
+// ResendEmailVerification handles sending a new verification email
+// @Summary Resend verification email
+// @Description Send a new verification link to the authenticated user's email address
+// @Tags auth
+// @Produce json
+// @Security Bearer
+// @Success 202 {object} response.Response{data=service.MessageResponse} "Verification email sent"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 409 {object} response.Response{error=response.ErrorInfo} "Email address already verified"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /auth/verify-email/resend [post]
+func (h *UserHandler) ResendEmailVerification(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	message, err := h.userService.ResendEmailVerification(r.Context(), userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, message)
 }
 
 // GetProfile handles getting the current user's profile
 // @Summary Get user profile
-// @Description Get the profile of the authenticated user
+// @Description Get the profile of the authenticated user, including the storage their photos and previous versions use
 // @Tags auth
 // @Produce json
 // @Security Bearer
@@ -112,9 +296,87 @@ func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	response.JSON(w, http.StatusOK, user)
 }
 
+// PatchProfile handles changing the current user's profile
+// @Summary Update user profile
+// @Description Change the username, email address and time zone of the authenticated user with a JSON Merge Patch (RFC 7396). Members missing from the patch are left unchanged. A new email address has to be verified again, and a verification link is sent to it.
+// @Tags auth
+// @Accept json
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param input body service.ProfilePatchDocument true "Merge patch, any subset of the members"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=service.UserResponse} "Profile updated successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid merge patch"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 409 {object} response.Response{error=response.ErrorInfo} "Username or email already exists"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /auth/profile [patch]
+func (h *UserHandler) PatchProfile(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "content type must be application/merge-patch+json"))
+		return
+	}
+
+	patch, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPatchSize))
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid request payload"))
+		return
+	}
+
+	user, err := h.userService.PatchProfile(r.Context(), userID, patch)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, user)
+}
+
+// ChangePassword handles changing the current user's password
+// @Summary Change password
+// @Description Change the authenticated user's password. The current password is required, and every other session of the user is logged out.
+// @Tags auth
+// @Accept json
+// @Param input body service.PasswordChangeInput true "Current and new password"
+// @Security Bearer
+// @Success 204 "Password changed successfully"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid request payload"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated or current password incorrect"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /auth/password [post]
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	var input service.PasswordChangeInput
+	err = json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid request payload"))
+		return
+	}
+
+	err = h.userService.ChangePassword(r.Context(), userID, input, middleware.GetTokenFamily(r.Context()))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
 // Logout handles user logout
 // @Summary Logout a user
-// @Description Invalidate the user's authentication token
+// @Description Invalidate the user's access token and the refresh token issued with it
 // @Tags auth
 // @Produce json
 // @Security Bearer
@@ -146,14 +408,260 @@ func (h *UserHandler) Logout(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// ListSessions handles listing where the current user is logged in
+// @Summary List sessions
+// @Description Get the sessions of the authenticated user, one per login, most recently used first. The session of the request is marked current. Last use is updated whenever the session's tokens are refreshed.
+// @Tags auth
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=[]service.SessionResponse} "Sessions retrieved successfully"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /auth/sessions [get]
+func (h *UserHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	sessions, err := h.userService.GetSessions(r.Context(), userID, middleware.GetTokenFamily(r.Context()))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, sessions)
+}
+
+// RevokeSession handles logging out one session
+// @Summary Revoke a session
+// @Description Revoke the access and refresh tokens of one of the authenticated user's sessions
+// @Tags auth
+// @Param id path string true "Session ID"
+// @Security Bearer
+// @Success 204 "Session revoked successfully"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 404 {object} response.Response{error=response.ErrorInfo} "Session not found"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /auth/sessions/{id} [delete]
+func (h *UserHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	err = h.userService.RevokeSession(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// LogoutAll handles logging out everywhere
+// @Summary Logout everywhere
+// @Description Revoke every session of the authenticated user, including the current one
+// @Tags auth
+// @Security Bearer
+// @Success 204 "User logged out everywhere successfully"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /auth/logout-all [post]
+func (h *UserHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	err = h.userService.LogoutAll(r.Context(), userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// EnrollMFA handles starting two-factor authentication enrollment
+// @Summary Enroll in two-factor authentication
+// @Description Create a TOTP secret for the authenticated user. Add it to an authenticator app with the otpauth:// URI (usually shown as a QR code) or the secret, then confirm a code at POST /auth/mfa/confirm to enable two-factor authentication. Enrolling again before confirming replaces the secret.
+// @Tags auth
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=service.MFAEnrollmentResponse} "TOTP secret created"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated"
+// @Failure 409 {object} response.Response{error=response.ErrorInfo} "Two-factor authentication is already enabled"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /auth/mfa/enroll [post]
+func (h *UserHandler) EnrollMFA(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	enrollment, err := h.userService.EnrollMFA(r.Context(), userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, enrollment)
+}
+
+// ConfirmMFA handles enabling two-factor authentication
+// @Summary Confirm two-factor authentication
+// @Description Enable two-factor authentication with a code from the authenticator app the secret from POST /auth/mfa/enroll was added to. The response holds single-use recovery codes for logging in without the app; they are not shown again.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body service.MFACodeInput true "Code from the authenticator app"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=service.RecoveryCodesResponse} "Two-factor authentication enabled"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid request payload or enrollment not started"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated or invalid code"
+// @Failure 409 {object} response.Response{error=response.ErrorInfo} "Two-factor authentication is already enabled"
+// @Failure 429 {object} response.Response{error=response.ErrorInfo} "Too many wrong codes, try again later"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /auth/mfa/confirm [post]
+func (h *UserHandler) ConfirmMFA(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	var input service.MFACodeInput
+	err = json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid request payload"))
+		return
+	}
+
+	codes, err := h.userService.ConfirmMFA(r.Context(), userID, input)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, codes)
+}
+
+// DisableMFA handles turning two-factor authentication off
+// @Summary Disable two-factor authentication
+// @Description Turn two-factor authentication off for the authenticated user, which takes a code from the authenticator app or an unused recovery code. The remaining recovery codes are deleted.
+// @Tags auth
+// @Accept json
+// @Param input body service.MFACodeInput true "Code from the authenticator app or a recovery code"
+// @Security Bearer
+// @Success 204 "Two-factor authentication disabled"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid request payload or two-factor authentication not enabled"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated or invalid code"
+// @Failure 429 {object} response.Response{error=response.ErrorInfo} "Too many wrong codes, try again later"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /auth/mfa/disable [post]
+func (h *UserHandler) DisableMFA(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	var input service.MFACodeInput
+	err = json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid request payload"))
+		return
+	}
+
+	err = h.userService.DisableMFA(r.Context(), userID, input)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// DeleteAccount handles deleting the current user's account
+// @Summary Delete account
+// @Description Delete the authenticated user's account after confirming their password. The user is logged out everywhere, and logging in again during the grace period cancels the deletion. After the grace period the account is erased in the background: all stored photos, previous versions and export archives, every token and session, and all records of the account. Only a record that the erasure happened is kept.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body service.AccountDeleteInput true "Password confirmation"
+// @Security Bearer
+// @Success 202 {object} response.Response{data=service.AccountDeletionResponse} "Account deletion scheduled"
+// @Failure 400 {object} response.Response{error=response.ErrorInfo} "Invalid request payload"
+// @Failure 401 {object} response.Response{error=response.ErrorInfo} "User not authenticated or password incorrect"
+// @Failure 500 {object} response.Response{error=response.ErrorInfo} "Internal server error"
+// @Router /auth/account [delete]
+func (h *UserHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	var input service.AccountDeleteInput
+	err = json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		response.Error(w, apperrors.New(apperrors.BadRequest, "invalid request payload"))
+		return
+	}
+
+	deletion, err := h.userService.DeleteAccount(r.Context(), userID, input)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, deletion)
+}
+
+// clientInfo describes the client of a request for its session.
+func clientInfo(r *http.Request) auth.ClientInfo {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	userAgent := r.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	return auth.ClientInfo{
+		UserAgent: userAgent,
+		IP:        ip,
+	}
+}
+
 func (h *UserHandler) RegisterRoutes(r chi.Router, authMiddleware func(http.Handler) http.Handler) {
 	r.Post("/register", h.Register)
 	r.Post("/login", h.Login)
+	r.Post("/mfa/verify", h.VerifyMFA)
+	r.Post("/refresh", h.Refresh)
+	r.Post("/password/forgot", h.ForgotPassword)
+	r.Post("/password/reset", h.ResetPassword)
+	r.Post("/verify-email", h.VerifyEmail)
 	r.Get("/logout", h.Logout)
 
 	r.Group(func(r chi.Router) {
 		r.Use(authMiddleware)
 		r.Get("/profile", h.GetProfile)
+		r.Patch("/profile", h.PatchProfile)
+		r.Post("/password", h.ChangePassword)
+		r.Post("/verify-email/resend", h.ResendEmailVerification)
+		r.Get("/sessions", h.ListSessions)
+		r.Delete("/sessions/{id}", h.RevokeSession)
+		r.Post("/logout-all", h.LogoutAll)
+		r.Delete("/account", h.DeleteAccount)
+		r.Post("/mfa/enroll", h.EnrollMFA)
+		r.Post("/mfa/confirm", h.ConfirmMFA)
+		r.Post("/mfa/disable", h.DisableMFA)
 	})
 
 }
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/redis/go-redis/v9"
+)
+
+// failAttemptScript counts a failed attempt and returns the count. The
+// count expires ARGV[2] milliseconds after the first failure, and again
+// after the one reaching the limit of ARGV[1], so a lockout always lasts a
+// full window.
+var failAttemptScript = redis.NewScript(`
+local failures = redis.call('INCR', KEYS[1])
+if failures == 1 or failures == tonumber(ARGV[1]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return failures
+`)
+
+// AttemptLimiter locks a key out for a while after too many failed attempts
+// at guessing the secret behind it, such as a password or a code. It slows
+// brute force down to maxFailures guesses per window.
+type AttemptLimiter struct {
+	redis       *redis.Client
+	prefix      string
+	maxFailures int
+	window      time.Duration
+}
+
+func NewAttemptLimiter(redis *redis.Client, prefix string, maxFailures int, window time.Duration) *AttemptLimiter {
+	return &AttemptLimiter{
+		redis:       redis,
+		prefix:      prefix,
+		maxFailures: maxFailures,
+		window:      window,
+	}
+}
+
+// Check fails with TooManyRequests while key is locked out. It is called
+// before the secret is checked, so a locked out key costs no hashing.
+func (l *AttemptLimiter) Check(ctx context.Context, key string) error {
+	failures, err := l.redis.Get(ctx, l.prefix+key).Int()
+	if err != nil && err != redis.Nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to check failed attempts: %v", err)
+	}
+	if failures >= l.maxFailures {
+		return apperrors.New(apperrors.TooManyRequests, "too many failed attempts, try again later")
+	}
+	return nil
+}
+
+// Fail counts a failed attempt for key.
+func (l *AttemptLimiter) Fail(ctx context.Context, key string) error {
+	err := failAttemptScript.Run(ctx, l.redis, []string{l.prefix + key}, l.maxFailures, l.window.Milliseconds()).Err()
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to count failed attempt: %v", err)
+	}
+	return nil
+}
+
+// Reset forgets the failed attempts for key after a successful one.
+func (l *AttemptLimiter) Reset(ctx context.Context, key string) error {
+	err := l.redis.Del(ctx, l.prefix+key).Err()
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to reset failed attempts: %v", err)
+	}
+	return nil
+}
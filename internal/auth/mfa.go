@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	mfaChallengePrefix = "mfa_challenge:"
+	totpUsedPrefix     = "totp_used:"
+
+	// maxMFAAttempts is how many wrong codes a challenge takes before it
+	// is revoked and the user has to log in again.
+	maxMFAAttempts = 5
+
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+)
+
+// failMFAChallengeScript counts a wrong code entered for the challenge
+// KEYS[1] and deletes it after ARGV[1] of them. A challenge that expired or
+// was deleted meanwhile is left alone rather than recreated without an
+// expiry.
+var failMFAChallengeScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+local attempts = redis.call('HINCRBY', KEYS[1], 'attempts', 1)
+if attempts >= tonumber(ARGV[1]) then
+	redis.call('DEL', KEYS[1])
+end
+return attempts
+`)
+
+// MFAChallengeTTL is how long a user has to enter their code after their
+// password.
+func (s *TokenService) MFAChallengeTTL() time.Duration {
+	return s.config.MFAChallengeTTL
+}
+
+// TOTPURI returns the otpauth:// URI authenticator apps enroll the secret
+// of account from, usually shown as a QR code.
+func (s *TokenService) TOTPURI(account, secret string) string {
+	return totpURI(s.config.MFAIssuer, account, secret)
+}
+
+// CreateMFAChallenge issues a token proving the user entered their password,
+// to be exchanged together with a second factor for a token pair.
+func (s *TokenService) CreateMFAChallenge(ctx context.Context, userID uuid.UUID) (string, error) {
+	token, err := generateRandomString(64)
+	if err != nil {
+		return "", apperrors.New(apperrors.InternalServer, "failed to generate token")
+	}
+
+	key := mfaChallengePrefix + s.hashToken(token)
+	_, err = s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, "user_id", userID.String(), "attempts", 0)
+		pipe.Expire(ctx, key, s.config.MFAChallengeTTL)
+		return nil
+	})
+	if err != nil {
+		return "", apperrors.NewWithFormat(apperrors.InternalServer, "failed to save MFA challenge: %v", err)
+	}
+
+	return token, nil
+}
+
+// CheckMFAChallenge returns the user an MFA challenge was issued to. It
+// stays valid until ConsumeMFAChallenge or too many FailMFAChallenge calls.
+func (s *TokenService) CheckMFAChallenge(ctx context.Context, token string) (uuid.UUID, error) {
+	userIDStr, err := s.redis.HGet(ctx, mfaChallengePrefix+s.hashToken(token), "user_id").Result()
+	if err != nil {
+		if err == redis.Nil {
+			return uuid.Nil, apperrors.New(apperrors.Unauthorized, "invalid or expired MFA token")
+		}
+		return uuid.Nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to check MFA challenge: %v", err)
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return uuid.Nil, apperrors.New(apperrors.InternalServer, "Invalid user ID format in token")
+	}
+	return userID, nil
+}
+
+// FailMFAChallenge counts a wrong code entered for a challenge and revokes
+// the challenge after maxMFAAttempts of them.
+func (s *TokenService) FailMFAChallenge(ctx context.Context, token string) error {
+	err := failMFAChallengeScript.Run(ctx, s.redis, []string{mfaChallengePrefix + s.hashToken(token)}, maxMFAAttempts).Err()
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to update MFA challenge: %v", err)
+	}
+	return nil
+}
+
+// ConsumeMFAChallenge invalidates a challenge once it was passed. Only one
+// of concurrent calls for the same challenge succeeds.
+func (s *TokenService) ConsumeMFAChallenge(ctx context.Context, token string) error {
+	deleted, err := s.redis.Del(ctx, mfaChallengePrefix+s.hashToken(token)).Result()
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to consume MFA challenge: %v", err)
+	}
+	if deleted == 0 {
+		return apperrors.New(apperrors.Unauthorized, "invalid or expired MFA token")
+	}
+	return nil
+}
+
+// UseTOTPStep records that the user entered the TOTP code of step and
+// reports false if they already did, so an intercepted code can't be
+// replayed while it is still valid.
+func (s *TokenService) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	key := totpUsedPrefix + userID.String() + ":" + strconv.FormatInt(step, 10)
+	ttl := (2*totpSkew + 1) * totpPeriod
+	fresh, err := s.redis.SetNX(ctx, key, 1, ttl).Result()
+	if err != nil {
+		return false, apperrors.NewWithFormat(apperrors.InternalServer, "failed to record TOTP code: %v", err)
+	}
+	return fresh, nil
+}
+
+// GenerateRecoveryCodes returns new single-use recovery codes, which let a
+// user without their authenticator sign in, and the hashes to store them
+// under.
+func (s *TokenService) GenerateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := generateRandomString(2 * recoveryCodeLength)
+		if err != nil {
+			return nil, nil, apperrors.New(apperrors.InternalServer, "failed to generate recovery codes")
+		}
+		code = strings.ToLower(code[:recoveryCodeLength])
+		codes[i] = code[:recoveryCodeLength/2] + "-" + code[recoveryCodeLength/2:]
+		hashes[i] = s.HashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode returns the hash a recovery code is stored under. Case
+// and the separator are ignored so codes can be typed loosely.
+func (s *TokenService) HashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	return s.hashToken(code)
+}
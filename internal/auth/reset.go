@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	passwordResetPrefix     = "password_reset:"
+	passwordResetUserPrefix = "password_reset_user:"
+)
+
+// PasswordResetTTL is how long password reset tokens stay valid.
+func (s *TokenService) PasswordResetTTL() time.Duration {
+	return s.config.PasswordResetTTL
+}
+
+// GeneratePasswordResetToken issues a single-use password reset token for the
+// user, valid for PasswordResetTTL. Issuing one invalidates the previous
+// token of the user.
+func (s *TokenService) GeneratePasswordResetToken(ctx context.Context, userID uuid.UUID) (string, error) {
+	token, err := generateRandomString(64)
+	if err != nil {
+		return "", apperrors.New(apperrors.InternalServer, "failed to generate token")
+	}
+	hash := s.hashToken(token)
+	ttl := s.config.PasswordResetTTL
+
+	previous, err := s.redis.SetArgs(ctx, passwordResetUserPrefix+userID.String(), hash, redis.SetArgs{
+		TTL: ttl,
+		Get: true,
+	}).Result()
+	if err != nil && err != redis.Nil {
+		return "", apperrors.NewWithFormat(apperrors.InternalServer, "failed to save password reset token: %v", err)
+	}
+
+	_, err = s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if previous != "" {
+			pipe.Del(ctx, passwordResetPrefix+previous)
+		}
+		pipe.Set(ctx, passwordResetPrefix+hash, userID.String(), ttl)
+		return nil
+	})
+	if err != nil {
+		return "", apperrors.NewWithFormat(apperrors.InternalServer, "failed to save password reset token: %v", err)
+	}
+
+	return token, nil
+}
+
+// ConsumePasswordResetToken returns the user a password reset token was
+// issued to and invalidates it.
+func (s *TokenService) ConsumePasswordResetToken(ctx context.Context, token string) (uuid.UUID, error) {
+	userIDStr, err := s.redis.GetDel(ctx, passwordResetPrefix+s.hashToken(token)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return uuid.Nil, apperrors.New(apperrors.BadRequest, "invalid or expired password reset token")
+		}
+		return uuid.Nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to check password reset token: %v", err)
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return uuid.Nil, apperrors.New(apperrors.InternalServer, "Invalid user ID format in token")
+	}
+	s.redis.Del(ctx, passwordResetUserPrefix+userIDStr)
+	return userID, nil
+}
+
+// RevokePasswordResetToken invalidates the outstanding password reset token
+// of the user, if there is one.
+func (s *TokenService) RevokePasswordResetToken(ctx context.Context, userID uuid.UUID) error {
+	hash, err := s.redis.GetDel(ctx, passwordResetUserPrefix+userID.String()).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil
+		}
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to revoke password reset token: %v", err)
+	}
+
+	err = s.redis.Del(ctx, passwordResetPrefix+hash).Err()
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to revoke password reset token: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/redis/go-redis/v9"
+)
+
+// ClientInfo describes the client a session's tokens were issued to.
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+// Session is a token family as shown to its user. LastSeenAt is when its
+// tokens were last issued or refreshed, so it lags actual use by at most the
+// access token lifetime.
+type Session struct {
+	ID         string
+	UserAgent  string
+	IP         string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	// Current is set on the session of the token family passed to
+	// ListSessions.
+	Current bool
+
+	familyID    string
+	accessToken string
+}
+
+// ListSessions returns the user's sessions, most recently used first.
+// currentFamilyID marks the session of the request.
+func (s *TokenService) ListSessions(ctx context.Context, userID uuid.UUID, currentFamilyID string) ([]*Session, error) {
+	sessions, err := s.getSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		session.Current = session.familyID == currentFamilyID
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	return sessions, nil
+}
+
+// RevokeSession revokes one of the user's sessions by ID.
+func (s *TokenService) RevokeSession(ctx context.Context, userID uuid.UUID, sessionID string) error {
+	sessions, err := s.getSessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if session.ID == sessionID {
+			return s.revokeFamily(ctx, userID.String(), session.familyID, session.accessToken)
+		}
+	}
+	return apperrors.NewWithFormat(apperrors.NotFound, "session with id %s not found", sessionID)
+}
+
+// RevokeAllSessions revokes every session of the user except the one of
+// exceptFamilyID, which may be empty, and returns how many were revoked.
+func (s *TokenService) RevokeAllSessions(ctx context.Context, userID uuid.UUID, exceptFamilyID string) (int, error) {
+	sessions, err := s.getSessions(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	revoked := 0
+	for _, session := range sessions {
+		if exceptFamilyID != "" && session.familyID == exceptFamilyID {
+			continue
+		}
+		err := s.revokeFamily(ctx, userID.String(), session.familyID, session.accessToken)
+		if err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
+// getSessions loads the user's token families, dropping expired ones from
+// the user's set.
+func (s *TokenService) getSessions(ctx context.Context, userID uuid.UUID) ([]*Session, error) {
+	setKey := s.sessionsPrefix + userID.String()
+	familyIDs, err := s.redis.SMembers(ctx, setKey).Result()
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list sessions: %v", err)
+	}
+	if len(familyIDs) == 0 {
+		return []*Session{}, nil
+	}
+
+	cmds := make([]*redis.MapStringStringCmd, len(familyIDs))
+	_, err = s.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, familyID := range familyIDs {
+			cmds[i] = pipe.HGetAll(ctx, s.familyPrefix+familyID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list sessions: %v", err)
+	}
+
+	sessions := make([]*Session, 0, len(familyIDs))
+	var expired []interface{}
+	for i, cmd := range cmds {
+		fields := cmd.Val()
+		if fields["user_id"] != userID.String() {
+			expired = append(expired, familyIDs[i])
+			continue
+		}
+		sessions = append(sessions, &Session{
+			ID:          fields["session_id"],
+			UserAgent:   fields["user_agent"],
+			IP:          fields["ip"],
+			CreatedAt:   parseUnixTime(fields["created_at"]),
+			LastSeenAt:  parseUnixTime(fields["last_seen_at"]),
+			familyID:    familyIDs[i],
+			accessToken: fields["access"],
+		})
+	}
+	if len(expired) > 0 {
+		s.redis.SRem(ctx, setKey, expired...)
+	}
+	return sessions, nil
+}
+
+func parseUnixTime(value string) time.Time {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/mmd-moradi/goup/configs"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+)
+
+// signedTokenVersion prefixes signed access tokens, which have the form
+// v1.<key id>.<claims>.<signature> with base64url claims and signature.
+const signedTokenVersion = "v1"
+
+// accessClaims are the contents of a signed access token.
+type accessClaims struct {
+	Subject   string `json:"sub"`
+	FamilyID  string `json:"fam"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// tokenSigner signs access tokens with the current key and verifies them
+// with it or any previous key, so the secret can be rotated without logging
+// everyone out.
+type tokenSigner struct {
+	keyID string
+	keys  map[string][]byte
+}
+
+func newTokenSigner(config *configs.AuthConfig) *tokenSigner {
+	keys := make(map[string][]byte, len(config.PreviousTokenKeys)+1)
+	for keyID, secret := range config.PreviousTokenKeys {
+		keys[keyID] = []byte(secret)
+	}
+	keys[config.TokenKeyID] = []byte(config.TokenSecret)
+
+	return &tokenSigner{
+		keyID: config.TokenKeyID,
+		keys:  keys,
+	}
+}
+
+func (s *tokenSigner) sign(claims accessClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signed := signedTokenVersion + "." + s.keyID + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(s.mac(s.keys[s.keyID], signed)), nil
+}
+
+func (s *tokenSigner) verify(token string, now time.Time) (*accessClaims, error) {
+	invalid := apperrors.New(apperrors.Unauthorized, "Invalid or expired token")
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 || parts[0] != signedTokenVersion {
+		return nil, invalid
+	}
+	key, ok := s.keys[parts[1]]
+	if !ok {
+		return nil, invalid
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		return nil, invalid
+	}
+	if !hmac.Equal(signature, s.mac(key, strings.Join(parts[:3], "."))) {
+		return nil, invalid
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, invalid
+	}
+	var claims accessClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, invalid
+	}
+	if now.Unix() >= claims.ExpiresAt {
+		return nil, invalid
+	}
+	return &claims, nil
+}
+
+func (s *tokenSigner) mac(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -2,8 +2,13 @@ package auth
 
 import (
 	"context"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base32"
+	"encoding/hex"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,93 +17,382 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// TokenService issues and checks access and refresh tokens. In opaque mode
+// access tokens are random strings kept in Redis; in signed mode they are
+// verified with the token secret and only revoked ones are kept in Redis.
+//
+// Redis only holds keyed hashes of tokens, so reading it doesn't reveal any
+// token that could be presented.
 type TokenService struct {
-	redis       *redis.Client
-	config      *configs.AuthConfig
-	tokenPrefix string
+	redis          *redis.Client
+	config         *configs.AuthConfig
+	signer         *tokenSigner
+	hashKey        []byte
+	tokenPrefix    string
+	familyPrefix   string
+	denylistPrefix string
+	// sessionsPrefix keys the set of a user's token families.
+	sessionsPrefix string
 }
 
-type TokenDetails struct {
-	Token     string
-	UserID    uuid.UUID
-	ExpiresAt time.Time
+// TokenInfo identifies the user and token family of a valid access token.
+type TokenInfo struct {
+	UserID   uuid.UUID
+	FamilyID string
 }
 
+// TokenPair is a short-lived access token and the refresh token that
+// replaces it. Both belong to a token family started at login; refreshing
+// rotates them within the family.
+type TokenPair struct {
+	AccessToken      string
+	RefreshToken     string
+	UserID           uuid.UUID
+	FamilyID         string
+	ExpiresAt        time.Time
+	RefreshExpiresAt time.Time
+}
+
+// rotateRefreshScript replaces the refresh and access tokens of a family if
+// the hash of its current refresh token is ARGV[1], and deletes the family
+// if it isn't. A rotation also records the client the family was last used
+// from. It returns the outcome, the replaced access token and the family's
+// user.
+var rotateRefreshScript = redis.NewScript(`
+local current = redis.call('HGET', KEYS[1], 'refresh')
+if not current then
+	return {0, '', ''}
+end
+local access = redis.call('HGET', KEYS[1], 'access') or ''
+if current ~= ARGV[1] then
+	local user = redis.call('HGET', KEYS[1], 'user_id') or ''
+	redis.call('DEL', KEYS[1])
+	return {-1, access, user}
+end
+redis.call('HSET', KEYS[1], 'refresh', ARGV[2], 'access', ARGV[3],
+	'user_agent', ARGV[5], 'ip', ARGV[6], 'last_seen_at', ARGV[7])
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+return {1, access, redis.call('HGET', KEYS[1], 'user_id')}
+`)
+
 func NewTokenService(redis *redis.Client, config *configs.AuthConfig) *TokenService {
-	return &TokenService{
-		redis:       redis,
-		config:      config,
-		tokenPrefix: "auth_token:",
+	s := &TokenService{
+		redis:          redis,
+		config:         config,
+		hashKey:        []byte(config.TokenHashKey),
+		tokenPrefix:    "auth_token_hash:",
+		familyPrefix:   "refresh_family:",
+		denylistPrefix: "token_denylist:",
+		sessionsPrefix: "user_sessions:",
 	}
+	if config.TokenMode == configs.TokenModeSigned {
+		s.signer = newTokenSigner(config)
+	}
+	return s
 }
 
-func (s *TokenService) GenerateToken(userID uuid.UUID) (*TokenDetails, error) {
-	token, err := generateRandomString(32)
+// GenerateTokenPair starts a new token family for the user and issues its
+// first access and refresh tokens. The family is listed as a session of the
+// user on the client it was issued to.
+func (s *TokenService) GenerateTokenPair(userID uuid.UUID, client ClientInfo) (*TokenPair, error) {
+	familyID, err := generateRandomString(32)
 	if err != nil {
 		return nil, apperrors.New(apperrors.InternalServer, "failed to generate token")
 	}
-
-	expiresAt := time.Now().Add(time.Duration(s.config.TokenExpirationMin) * time.Minute)
-
-	tokenDetail := &TokenDetails{
-		Token:     token,
-		UserID:    userID,
-		ExpiresAt: expiresAt,
+	pair, err := s.newTokenPair(userID, familyID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.signAccessToken(pair)
+	if err != nil {
+		return nil, err
 	}
 
-	key := s.tokenPrefix + token
-	err = s.redis.Set(
-		context.Background(),
-		key,
-		userID.String(),
-		time.Until(expiresAt),
-	).Err()
+	sessionID := uuid.New()
+	now := strconv.FormatInt(time.Now().Unix(), 10)
 
+	ctx := context.Background()
+	_, err = s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		familyKey := s.familyPrefix + familyID
+		pipe.HSet(ctx, familyKey,
+			"user_id", userID.String(),
+			"refresh", s.hashToken(refreshSecret(pair.RefreshToken)),
+			"access", s.storedAccessToken(pair),
+			"session_id", sessionID.String(),
+			"user_agent", client.UserAgent,
+			"ip", client.IP,
+			"created_at", now,
+			"last_seen_at", now,
+		)
+		pipe.Expire(ctx, familyKey, s.config.RefreshTokenTTL)
+		pipe.SAdd(ctx, s.sessionsPrefix+userID.String(), familyID)
+		pipe.Expire(ctx, s.sessionsPrefix+userID.String(), s.config.RefreshTokenTTL)
+		if s.signer == nil {
+			pipe.Set(ctx, s.tokenPrefix+s.hashToken(pair.AccessToken), accessTokenValue(userID, familyID), time.Until(pair.ExpiresAt))
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to save token: %v", err)
 	}
 
-	return tokenDetail, nil
+	return pair, nil
 }
 
-func (s *TokenService) ValidateToken(token string) (uuid.UUID, error) {
-	key := s.tokenPrefix + token
-	userIDStr, err := s.redis.Get(context.Background(), key).Result()
+func (s *TokenService) ValidateToken(token string) (*TokenInfo, error) {
+	if s.signer != nil {
+		return s.validateSignedToken(token)
+	}
+
+	value, err := s.lookupAccessToken(context.Background(), token)
 	if err != nil {
 		if err == redis.Nil {
-			return uuid.Nil, apperrors.New(apperrors.Unauthorized, "Invalid or expired token")
+			return nil, apperrors.New(apperrors.Unauthorized, "Invalid or expired token")
 		}
-		return uuid.Nil, apperrors.NewWithFormat(apperrors.InternalServer, "Failed to validate token: %v", err)
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "Failed to validate token: %v", err)
+	}
+	userIDStr, familyID := parseAccessTokenValue(value)
+	if familyID == "" {
+		// Only tokens that belong to a family can be revoked with their
+		// session.
+		return nil, apperrors.New(apperrors.Unauthorized, "Invalid or expired token")
 	}
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		return uuid.Nil, apperrors.New(apperrors.InternalServer, "Invalid user ID format in token")
+		return nil, apperrors.New(apperrors.InternalServer, "Invalid user ID format in token")
 	}
-	return userID, nil
+	return &TokenInfo{UserID: userID, FamilyID: familyID}, nil
 }
 
+// RevokeToken revokes an access token along with the token family it was
+// issued in, so its refresh token can't be used either.
 func (s *TokenService) RevokeToken(token string) error {
-	key := s.tokenPrefix + token
-	err := s.redis.Del(context.Background(), key).Err()
+	ctx := context.Background()
+
+	if s.signer != nil {
+		claims, err := s.signer.verify(token, time.Now())
+		if err != nil {
+			return nil
+		}
+		return s.revokeFamily(ctx, claims.Subject, claims.FamilyID, "")
+	}
+
+	value, err := s.lookupAccessToken(ctx, token)
+	if err != nil && err != redis.Nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "Failed to revoke token: %v", err)
+	}
+
+	if userID, familyID := parseAccessTokenValue(value); familyID != "" {
+		return s.revokeFamily(ctx, userID, familyID, s.hashToken(token))
+	}
+	err = s.redis.Del(ctx, s.tokenPrefix+s.hashToken(token)).Err()
 	if err != nil {
 		return apperrors.NewWithFormat(apperrors.InternalServer, "Failed to revoke token: %v", err)
 	}
 	return nil
 }
 
-func (s *TokenService) RefreshToken(token string) (*TokenDetails, error) {
-	userIDStr, err := s.ValidateToken(token)
+// RefreshToken exchanges a refresh token for a new token pair in the same
+// family, and records the client as the session's last use. Each refresh
+// token can be used once: presenting one that was already exchanged means it
+// was leaked, so the whole family is revoked.
+func (s *TokenService) RefreshToken(refreshToken string, client ClientInfo) (*TokenPair, error) {
+	familyID, secret, ok := strings.Cut(refreshToken, ".")
+	if !ok || familyID == "" || secret == "" {
+		return nil, apperrors.New(apperrors.Unauthorized, "Invalid or expired refresh token")
+	}
+
+	pair, err := s.newTokenPair(uuid.Nil, familyID)
 	if err != nil {
 		return nil, err
 	}
 
-	err = s.RevokeToken(token)
+	ctx := context.Background()
+	result, err := rotateRefreshScript.Run(ctx, s.redis,
+		[]string{s.familyPrefix + familyID},
+		s.hashToken(secret),
+		s.hashToken(refreshSecret(pair.RefreshToken)),
+		s.storedAccessToken(pair),
+		s.config.RefreshTokenTTL.Milliseconds(),
+		client.UserAgent,
+		client.IP,
+		time.Now().Unix(),
+	).Slice()
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "Failed to refresh token: %v", err)
+	}
+
+	outcome, _ := result[0].(int64)
+	oldAccessToken, _ := result[1].(string)
+	userIDStr, _ := result[2].(string)
+	switch outcome {
+	case 0:
+		return nil, apperrors.New(apperrors.Unauthorized, "Invalid or expired refresh token")
+	case -1:
+		s.revokeFamily(ctx, userIDStr, familyID, oldAccessToken)
+		return nil, apperrors.New(apperrors.Unauthorized, "Refresh token has already been used, please log in again")
+	}
+
+	pair.UserID, err = uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, apperrors.New(apperrors.InternalServer, "Invalid user ID format in token")
+	}
+
+	// Signed access tokens aren't kept in Redis and can only be signed now
+	// the family's user is known.
+	err = s.signAccessToken(pair)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if s.signer == nil {
+			pipe.Set(ctx, s.tokenPrefix+s.hashToken(pair.AccessToken), accessTokenValue(pair.UserID, familyID), time.Until(pair.ExpiresAt))
+			if oldAccessToken != "" {
+				pipe.Del(ctx, s.tokenPrefix+oldAccessToken)
+			}
+		}
+		pipe.SAdd(ctx, s.sessionsPrefix+userIDStr, familyID)
+		pipe.Expire(ctx, s.sessionsPrefix+userIDStr, s.config.RefreshTokenTTL)
+		return nil
+	})
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to save token: %v", err)
+	}
+
+	return pair, nil
+}
+
+// validateSignedToken verifies a signed access token and checks that its
+// family hasn't been revoked. A token is refused while the denylist can't be
+// checked, since it may have been revoked because it leaked.
+func (s *TokenService) validateSignedToken(token string) (*TokenInfo, error) {
+	claims, err := s.signer.verify(token, time.Now())
 	if err != nil {
 		return nil, err
 	}
 
-	return s.GenerateToken(userIDStr)
+	denied, err := s.redis.Exists(context.Background(), s.denylistPrefix+claims.FamilyID).Result()
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.ServiceUnavailable, "Failed to check token revocation: %v", err)
+	}
+	if denied > 0 {
+		return nil, apperrors.New(apperrors.Unauthorized, "Invalid or expired token")
+	}
 
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, apperrors.New(apperrors.Unauthorized, "Invalid or expired token")
+	}
+	return &TokenInfo{UserID: userID, FamilyID: claims.FamilyID}, nil
+}
+
+// lookupAccessToken returns what an opaque access token maps to. Tokens
+// stored before they were hashed aren't looked up: they belong to no token
+// family, so logging out everywhere couldn't revoke them, and their users
+// log in again instead.
+func (s *TokenService) lookupAccessToken(ctx context.Context, token string) (string, error) {
+	return s.redis.Get(ctx, s.tokenPrefix+s.hashToken(token)).Result()
+}
+
+// revokeFamily deletes a token family of the user and the access token
+// issued in it, given as stored in the family. In signed mode the family is
+// denylisted for as long as its access tokens can live.
+func (s *TokenService) revokeFamily(ctx context.Context, userID string, familyID string, accessToken string) error {
+	_, err := s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, s.familyPrefix+familyID)
+		if userID != "" {
+			pipe.SRem(ctx, s.sessionsPrefix+userID, familyID)
+		}
+		if s.signer != nil {
+			pipe.Set(ctx, s.denylistPrefix+familyID, 1, time.Duration(s.config.TokenExpirationMin)*time.Minute)
+		} else if accessToken != "" {
+			pipe.Del(ctx, s.tokenPrefix+accessToken)
+		}
+		return nil
+	})
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "Failed to revoke token: %v", err)
+	}
+	return nil
+}
+
+// newTokenPair creates the tokens of a family. In signed mode the access
+// token is left for signAccessToken.
+func (s *TokenService) newTokenPair(userID uuid.UUID, familyID string) (*TokenPair, error) {
+	var accessToken string
+	if s.signer == nil {
+		var err error
+		accessToken, err = generateRandomString(32)
+		if err != nil {
+			return nil, apperrors.New(apperrors.InternalServer, "failed to generate token")
+		}
+	}
+	secret, err := generateRandomString(32)
+	if err != nil {
+		return nil, apperrors.New(apperrors.InternalServer, "failed to generate token")
+	}
+
+	now := time.Now()
+	return &TokenPair{
+		AccessToken:      accessToken,
+		RefreshToken:     familyID + "." + secret,
+		UserID:           userID,
+		FamilyID:         familyID,
+		ExpiresAt:        now.Add(time.Duration(s.config.TokenExpirationMin) * time.Minute),
+		RefreshExpiresAt: now.Add(s.config.RefreshTokenTTL),
+	}, nil
+}
+
+func (s *TokenService) signAccessToken(pair *TokenPair) error {
+	if s.signer == nil {
+		return nil
+	}
+	token, err := s.signer.sign(accessClaims{
+		Subject:   pair.UserID.String(),
+		FamilyID:  pair.FamilyID,
+		IssuedAt:  time.Now().Unix(),
+		ExpiresAt: pair.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return apperrors.New(apperrors.InternalServer, "failed to sign token")
+	}
+	pair.AccessToken = token
+	return nil
+}
+
+// hashToken returns the keyed hash a token is stored under.
+func (s *TokenService) hashToken(token string) string {
+	h := hmac.New(sha256.New, s.hashKey)
+	h.Write([]byte(token))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// storedAccessToken is how a family refers to its access token. Signed
+// access tokens aren't stored.
+func (s *TokenService) storedAccessToken(pair *TokenPair) string {
+	if s.signer != nil {
+		return ""
+	}
+	return s.hashToken(pair.AccessToken)
+}
+
+// refreshSecret returns the part of a refresh token after its family ID.
+func refreshSecret(refreshToken string) string {
+	_, secret, _ := strings.Cut(refreshToken, ".")
+	return secret
+}
+
+// accessTokenValue is what an access token maps to: its user and the token
+// family it was issued in. Tokens issued before families existed map to the
+// user ID alone.
+func accessTokenValue(userID uuid.UUID, familyID string) string {
+	return userID.String() + ":" + familyID
+}
+
+func parseAccessTokenValue(value string) (userID, familyID string) {
+	userID, familyID, _ = strings.Cut(value, ":")
+	return userID, familyID
 }
 
 func generateRandomString(length int) (string, error) {
@@ -108,9 +402,5 @@ func generateRandomString(length int) (string, error) {
 		return "", err
 	}
 	plainText := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(randBytes)
-
-	// hash := sha256.Sum256([]byte(plainText))
-	// hashedToken := hash[:]
-
 	return plainText, nil
 }
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 key of the RFC 6238 test vectors, base32
+// encoded.
+var rfc6238Secret = totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCode(t *testing.T) {
+	// The RFC lists 8 digit codes; their last 6 digits are the 6 digit
+	// codes, since both are the same value modulo a power of ten.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+		{unix: 20000000000, want: "353130"},
+	}
+
+	key := []byte("12345678901234567890")
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got := totpCode(key, tt.unix/int64(totpPeriod.Seconds()))
+			if got != tt.want {
+				t.Errorf("totpCode at %d = %s, want %s", tt.unix, got, tt.want)
+			}
+			if len(got) != totpDigits {
+				t.Errorf("totpCode returned %d digits, want %d", len(got), totpDigits)
+			}
+		})
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := now.Unix() / int64(totpPeriod.Seconds())
+	key := []byte("12345678901234567890")
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current step", secret: rfc6238Secret, code: totpCode(key, step), wantStep: step, wantOK: true},
+		{name: "previous step", secret: rfc6238Secret, code: totpCode(key, step-1), wantStep: step - 1, wantOK: true},
+		{name: "next step", secret: rfc6238Secret, code: totpCode(key, step+1), wantStep: step + 1, wantOK: true},
+		{name: "lowercase secret", secret: strings.ToLower(rfc6238Secret), code: totpCode(key, step), wantStep: step, wantOK: true},
+		{name: "beyond the skew", secret: rfc6238Secret, code: totpCode(key, step-2)},
+		{name: "wrong code", secret: rfc6238Secret, code: "000000"},
+		{name: "too short", secret: rfc6238Secret, code: totpCode(key, step)[1:]},
+		{name: "too long", secret: rfc6238Secret, code: totpCode(key, step) + "0"},
+		{name: "empty secret", secret: "", code: totpCode(key, step)},
+		{name: "invalid secret", secret: "not base32!", code: totpCode(key, step)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, gotOK := ValidateTOTP(tt.secret, tt.code, now)
+			if gotOK != tt.wantOK {
+				t.Fatalf("ValidateTOTP ok = %v, want %v", gotOK, tt.wantOK)
+			}
+			if gotStep != tt.wantStep {
+				t.Errorf("ValidateTOTP step = %d, want %d", gotStep, tt.wantStep)
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret failed: %v", err)
+	}
+
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("secret %q is not base32: %v", secret, err)
+	}
+	if len(key) != totpSecretSize {
+		t.Errorf("secret has %d bytes, want %d", len(key), totpSecretSize)
+	}
+
+	other, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret failed: %v", err)
+	}
+	if other == secret {
+		t.Error("GenerateTOTPSecret returned the same secret twice")
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := totpURI("Go Up", "jane@example.com", rfc6238Secret)
+
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("invalid URI %q: %v", uri, err)
+	}
+	if parsed.Scheme != "otpauth" || parsed.Host != "totp" {
+		t.Errorf("URI %q is not an otpauth://totp URI", uri)
+	}
+	if parsed.Path != "/Go Up:jane@example.com" {
+		t.Errorf("label = %q, want %q", parsed.Path, "/Go Up:jane@example.com")
+	}
+	if got := parsed.Query().Get("secret"); got != rfc6238Secret {
+		t.Errorf("secret = %q, want %q", got, rfc6238Secret)
+	}
+	if got := parsed.Query().Get("issuer"); got != "Go Up" {
+		t.Errorf("issuer = %q, want %q", got, "Go Up")
+	}
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditEvent records an action taken on a record, kept after the record and
+// the acting user are gone.
+type AuditEvent struct {
+	ID uuid.UUID `json:"id"`
+	// ActorID is the user who took the action, or nil for the system.
+	ActorID     *uuid.UUID             `json:"actor_id,omitempty"`
+	Action      string                 `json:"action"`
+	SubjectType string                 `json:"subject_type"`
+	SubjectID   uuid.UUID              `json:"subject_id"`
+	Details     map[string]interface{} `json:"details"`
+	CreatedAt   time.Time              `json:"created_at"`
+}
+
+func NewAuditEvent(actorID *uuid.UUID, action, subjectType string, subjectID uuid.UUID, details map[string]interface{}) *AuditEvent {
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	return &AuditEvent{
+		ID:          uuid.New(),
+		ActorID:     actorID,
+		Action:      action,
+		SubjectType: subjectType,
+		SubjectID:   subjectID,
+		Details:     details,
+		CreatedAt:   time.Now(),
+	}
+}
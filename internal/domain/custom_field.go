@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CustomFieldType string
+
+const (
+	CustomFieldText    CustomFieldType = "text"
+	CustomFieldNumber  CustomFieldType = "number"
+	CustomFieldBoolean CustomFieldType = "boolean"
+	CustomFieldDate    CustomFieldType = "date"
+)
+
+// CustomFieldDefinition is a metadata field a user defined for their photos,
+// such as a project code or client name. Values are kept in
+// Photo.CustomFields under the field's name.
+type CustomFieldDefinition struct {
+	ID       uuid.UUID       `json:"id"`
+	UserID   uuid.UUID       `json:"user_id"`
+	Name     string          `json:"name"`
+	Type     CustomFieldType `json:"type"`
+	Required bool            `json:"required"`
+	// AllowedValues restricts the field to a set of values when not empty.
+	AllowedValues []string  `json:"allowed_values"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func NewCustomFieldDefinition(userID uuid.UUID, name string, fieldType CustomFieldType, required bool, allowedValues []string) *CustomFieldDefinition {
+	now := time.Now()
+	if allowedValues == nil {
+		allowedValues = []string{}
+	}
+	return &CustomFieldDefinition{
+		ID:            uuid.New(),
+		UserID:        userID,
+		Name:          name,
+		Type:          fieldType,
+		Required:      required,
+		AllowedValues: allowedValues,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
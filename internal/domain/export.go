@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ExportStatus string
+
+const (
+	ExportStatusPending   ExportStatus = "pending"
+	ExportStatusRunning   ExportStatus = "running"
+	ExportStatusCompleted ExportStatus = "completed"
+	ExportStatusFailed    ExportStatus = "failed"
+	// ExportStatusExpired is a completed job whose archive was deleted once
+	// its download link expired.
+	ExportStatusExpired ExportStatus = "expired"
+)
+
+// ExportJobTimeout bounds building the archive of a job, including the time
+// it waits for its turn. A job still pending or running after that was cut
+// short, e.g. by a restart.
+const ExportJobTimeout = time.Hour
+
+// ExportKind is what an export holds.
+type ExportKind string
+
+const (
+	// ExportKindPhotos is a selection of photos.
+	ExportKindPhotos ExportKind = "photos"
+	// ExportKindAccount is everything held about the user, with all their
+	// photos.
+	ExportKindAccount ExportKind = "account"
+)
+
+// ExportJob is a ZIP export built in the background. PhotoIDs is the
+// selection resolved when the export was requested.
+type ExportJob struct {
+	ID          uuid.UUID    `json:"id"`
+	UserID      uuid.UUID    `json:"user_id"`
+	Kind        ExportKind   `json:"kind"`
+	Status      ExportStatus `json:"status"`
+	PhotoIDs    []uuid.UUID  `json:"photo_ids"`
+	StoragePath string       `json:"-"`
+	Error       string       `json:"error,omitempty"`
+	ExpiresAt   *time.Time   `json:"expires_at,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+func NewExportJob(userID uuid.UUID, kind ExportKind, photoIDs []uuid.UUID) *ExportJob {
+	now := time.Now()
+	return &ExportJob{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Kind:      kind,
+		Status:    ExportStatusPending,
+		PhotoIDs:  photoIDs,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Deadline is when the job has to be done by.
+func (j *ExportJob) Deadline() time.Time {
+	return j.CreatedAt.Add(ExportJobTimeout)
+}
+
+// IsActive reports whether the job is still building its archive. A job
+// past its deadline isn't, even before it is marked as failed.
+func (j *ExportJob) IsActive() bool {
+	if j.Status != ExportStatusPending && j.Status != ExportStatusRunning {
+		return false
+	}
+	return time.Now().Before(j.Deadline())
+}
+
+// IsExpired reports whether the archive of a completed job is no longer
+// available for download.
+func (j *ExportJob) IsExpired() bool {
+	return j.ExpiresAt != nil && time.Now().After(*j.ExpiresAt)
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"math"
+
+	"github.com/google/uuid"
+)
+
+const earthRadiusMeters = 6371000
+
+// BoundingBox is an area between two latitudes and two longitudes. MinLng
+// is greater than MaxLng when the box crosses the antimeridian.
+type BoundingBox struct {
+	MinLat float64
+	MinLng float64
+	MaxLat float64
+	MaxLng float64
+}
+
+// GeoCluster groups the photos that fall into one cell of a clustering grid.
+// PhotoID is the most recent photo of the cluster.
+type GeoCluster struct {
+	Latitude  float64
+	Longitude float64
+	Count     int
+	PhotoID   uuid.UUID
+}
+
+// PlaceGroup is a place and the photos a user took there. PhotoIDs lists the
+// most recent photos first and may hold fewer than PhotoCount IDs.
+type PlaceGroup struct {
+	Name        string
+	CountryCode string
+	CountryName string
+	PhotoCount  int
+	PhotoIDs    []uuid.UUID
+}
+
+// BoundingBoxAround returns the smallest box containing the circle of
+// radiusMeters around lat, lng.
+func BoundingBoxAround(lat, lng, radiusMeters float64) BoundingBox {
+	deltaLat := radiusMeters / earthRadiusMeters * 180 / math.Pi
+	box := BoundingBox{
+		MinLat: math.Max(lat-deltaLat, -90),
+		MaxLat: math.Min(lat+deltaLat, 90),
+		MinLng: -180,
+		MaxLng: 180,
+	}
+
+	// Near the poles the circle covers every longitude.
+	if box.MinLat == -90 || box.MaxLat == 90 {
+		return box
+	}
+
+	deltaLng := deltaLat / math.Cos(lat*math.Pi/180)
+	if deltaLng >= 180 {
+		return box
+	}
+	box.MinLng = wrapLongitude(lng - deltaLng)
+	box.MaxLng = wrapLongitude(lng + deltaLng)
+	return box
+}
+
+func wrapLongitude(lng float64) float64 {
+	if lng < -180 {
+		return lng + 360
+	}
+	if lng > 180 {
+		return lng - 360
+	}
+	return lng
+}
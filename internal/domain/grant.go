@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Role string
+
+const (
+	RoleViewer    Role = "viewer"
+	RoleCommenter Role = "commenter"
+	RoleEditor    Role = "editor"
+)
+
+// Rank orders roles by how much they allow, so the strongest of several
+// grants can be picked. Unknown roles rank lowest.
+func (r Role) Rank() int {
+	switch r {
+	case RoleViewer:
+		return 1
+	case RoleCommenter:
+		return 2
+	case RoleEditor:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Grant gives another user a role on either a photo or an album. Exactly one
+// of PhotoID and AlbumID is set.
+type Grant struct {
+	ID        uuid.UUID  `json:"id"`
+	PhotoID   *uuid.UUID `json:"photo_id,omitempty"`
+	AlbumID   *uuid.UUID `json:"album_id,omitempty"`
+	GranteeID uuid.UUID  `json:"grantee_id"`
+	GrantedBy uuid.UUID  `json:"granted_by"`
+	Role      Role       `json:"role"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func NewPhotoGrant(photoID, granteeID, grantedBy uuid.UUID, role Role) *Grant {
+	return &Grant{
+		ID:        uuid.New(),
+		PhotoID:   &photoID,
+		GranteeID: granteeID,
+		GrantedBy: grantedBy,
+		Role:      role,
+		CreatedAt: time.Now(),
+	}
+}
+
+func NewAlbumGrant(albumID, granteeID, grantedBy uuid.UUID, role Role) *Grant {
+	return &Grant{
+		ID:        uuid.New(),
+		AlbumID:   &albumID,
+		GranteeID: granteeID,
+		GrantedBy: grantedBy,
+		Role:      role,
+		CreatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TransferStatus string
+
+const (
+	TransferStatusPending   TransferStatus = "pending"
+	TransferStatusRunning   TransferStatus = "running"
+	TransferStatusCompleted TransferStatus = "completed"
+	TransferStatusDeclined  TransferStatus = "declined"
+	TransferStatusCanceled  TransferStatus = "canceled"
+	TransferStatusFailed    TransferStatus = "failed"
+)
+
+// TransferJobTimeout bounds moving the photos of an accepted transfer,
+// including the time it waits for its turn. A transfer still running after
+// that was cut short, e.g. by a restart.
+const TransferJobTimeout = time.Hour
+
+const (
+	TransferSelectionPhotos = "photos"
+	TransferSelectionAlbums = "albums"
+	TransferSelectionAll    = "all"
+)
+
+// OwnershipTransfer moves photos and albums from one user to another once
+// the recipient accepts it. The selection is resolved when the transfer
+// runs, so photos added to a selected album in the meantime move too.
+type OwnershipTransfer struct {
+	ID          uuid.UUID      `json:"id"`
+	FromUserID  uuid.UUID      `json:"from_user_id"`
+	ToUserID    uuid.UUID      `json:"to_user_id"`
+	Status      TransferStatus `json:"status"`
+	Selection   string         `json:"selection"`
+	PhotoIDs    []uuid.UUID    `json:"photo_ids"`
+	AlbumIDs    []uuid.UUID    `json:"album_ids"`
+	PhotoCount  int            `json:"photo_count"`
+	Error       string         `json:"error,omitempty"`
+	ExpiresAt   time.Time      `json:"expires_at"`
+	CompletedAt *time.Time     `json:"completed_at,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+func NewOwnershipTransfer(fromUserID, toUserID uuid.UUID, selection string, photoIDs, albumIDs []uuid.UUID, ttl time.Duration) *OwnershipTransfer {
+	now := time.Now()
+	if photoIDs == nil {
+		photoIDs = []uuid.UUID{}
+	}
+	if albumIDs == nil {
+		albumIDs = []uuid.UUID{}
+	}
+	return &OwnershipTransfer{
+		ID:         uuid.New(),
+		FromUserID: fromUserID,
+		ToUserID:   toUserID,
+		Status:     TransferStatusPending,
+		Selection:  selection,
+		PhotoIDs:   photoIDs,
+		AlbumIDs:   albumIDs,
+		ExpiresAt:  now.Add(ttl),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+// IsExpired reports whether a pending transfer can no longer be accepted.
+func (t *OwnershipTransfer) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+// Deadline is when a running transfer has to be done by. A transfer is
+// marked running when it is accepted, which is when it was last updated.
+func (t *OwnershipTransfer) Deadline() time.Time {
+	return t.UpdatedAt.Add(TransferJobTimeout)
+}
+
+// TransferredPhoto is a photo as it is saved when its transfer completes:
+// its file and those of its versions are copied under the recipient and
+// AlbumID is kept only if the album moves too.
+type TransferredPhoto struct {
+	Photo       *Photo
+	StoragePath string
+	PublicURL   string
+	AlbumID     *uuid.UUID
+	Versions    []TransferredVersion
+}
+
+type TransferredVersion struct {
+	Version     *PhotoVersion
+	StoragePath string
+	PublicURL   string
+}
@@ -6,31 +6,77 @@ import (
 	"github.com/google/uuid"
 )
 
+type Visibility string
+
+const (
+	VisibilityPrivate  Visibility = "private"
+	VisibilityUnlisted Visibility = "unlisted"
+	VisibilityPublic   Visibility = "public"
+)
+
 type Photo struct {
-	ID          uuid.UUID `json:"id"`
-	UserID      uuid.UUID `json:"user_id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	FileName    string    `json:"filename"`
-	FileSize    int64     `json:"file_size"`
-	ContentType string    `json:"content_type"`
-	StoragePath string    `json:"storage_path"`
-	PublicURL   string    `json:"public_url"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	FileName    string     `json:"filename"`
+	FileSize    int64      `json:"file_size"`
+	ContentType string     `json:"content_type"`
+	StoragePath string     `json:"storage_path"`
+	PublicURL   string     `json:"public_url"`
+	Visibility  Visibility `json:"visibility"`
+	AlbumID     *uuid.UUID `json:"album_id,omitempty"`
+	Tags        []string   `json:"tags"`
+	Latitude    *float64   `json:"latitude,omitempty"`
+	Longitude   *float64   `json:"longitude,omitempty"`
+	PlaceName   string     `json:"place_name,omitempty"`
+	CountryCode string     `json:"country_code,omitempty"`
+	CountryName string     `json:"country_name,omitempty"`
+	TakenAt     *time.Time `json:"taken_at,omitempty"`
+	// CustomFields holds the values of the owner's custom fields by name.
+	CustomFields map[string]interface{} `json:"custom_fields"`
+	Version      int                    `json:"version"`
+	CreatedAt    time.Time              `json:"created_at"`
+	UpdatedAt    time.Time              `json:"updated_at"`
+}
+
+// DateBucket counts the photos taken in a year, month or day starting at
+// Start.
+type DateBucket struct {
+	Start time.Time
+	Count int
+}
+
+// Date is when the photo was taken, or when it was uploaded if that isn't
+// known. Timelines and the photo list are ordered by it.
+func (p *Photo) Date() time.Time {
+	if p.TakenAt != nil {
+		return *p.TakenAt
+	}
+	return p.CreatedAt
+}
+
+// PhotoCursor identifies a position in a feed ordered by creation time.
+type PhotoCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
 }
 
 func NewPhoto(userID uuid.UUID, fileSize int64, title, description, fileName, contentType, storagePath, publicURL string) *Photo {
 	now := time.Now()
 	return &Photo{
-		ID:          uuid.New(),
-		UserID:      userID,
-		Title:       title,
-		Description: description,
-		FileName:    fileName,
-		FileSize:    fileSize,
-		ContentType: contentType,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		ID:           uuid.New(),
+		UserID:       userID,
+		Title:        title,
+		Description:  description,
+		FileName:     fileName,
+		FileSize:     fileSize,
+		ContentType:  contentType,
+		Visibility:   VisibilityPrivate,
+		Tags:         []string{},
+		CustomFields: map[string]interface{}{},
+		Version:      1,
+		CreatedAt:    now,
+		UpdatedAt:    now,
 	}
 }
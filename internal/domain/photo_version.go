@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PhotoVersion is a previous binary of a photo, kept when the photo's
+// content is replaced. Version numbers increase per photo.
+type PhotoVersion struct {
+	ID            uuid.UUID `json:"id"`
+	PhotoID       uuid.UUID `json:"photo_id"`
+	VersionNumber int       `json:"version_number"`
+	FileName      string    `json:"file_name"`
+	FileSize      int64     `json:"file_size"`
+	ContentType   string    `json:"content_type"`
+	StoragePath   string    `json:"storage_path"`
+	PublicURL     string    `json:"public_url"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// NewPhotoVersion snapshots the current binary of photo. The version number
+// is assigned when the version is stored.
+func NewPhotoVersion(photo *Photo) *PhotoVersion {
+	return &PhotoVersion{
+		ID:          uuid.New(),
+		PhotoID:     photo.ID,
+		FileName:    photo.FileName,
+		FileSize:    photo.FileSize,
+		ContentType: photo.ContentType,
+		StoragePath: photo.StoragePath,
+		PublicURL:   photo.PublicURL,
+		CreatedAt:   time.Now(),
+	}
+}
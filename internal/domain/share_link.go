@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ShareLink struct {
+	ID            uuid.UUID  `json:"id"`
+	Token         string     `json:"token"`
+	PhotoID       uuid.UUID  `json:"photo_id"`
+	UserID        uuid.UUID  `json:"user_id"`
+	PasswordHash  string     `json:"-"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	MaxViews      *int       `json:"max_views,omitempty"`
+	ViewCount     int        `json:"view_count"`
+	AllowDownload bool       `json:"allow_download"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func NewShareLink(photoID, userID uuid.UUID, token string, expiresAt *time.Time, maxViews *int, allowDownload bool) *ShareLink {
+	return &ShareLink{
+		ID:            uuid.New(),
+		Token:         token,
+		PhotoID:       photoID,
+		UserID:        userID,
+		ExpiresAt:     expiresAt,
+		MaxViews:      maxViews,
+		AllowDownload: allowDownload,
+		CreatedAt:     time.Now(),
+	}
+}
+
+// IsExpired reports whether the link is past its expiry time.
+func (l *ShareLink) IsExpired() bool {
+	return l.ExpiresAt != nil && time.Now().After(*l.ExpiresAt)
+}
+
+// IsExhausted reports whether the link has used up its view budget.
+func (l *ShareLink) IsExhausted() bool {
+	return l.MaxViews != nil && l.ViewCount >= *l.MaxViews
+}
@@ -11,8 +11,26 @@ type User struct {
 	Username     string    `json:"username"`
 	Email        string    `json:"email"`
 	PasswordHash string    `json:"-"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	TimeZone     string    `json:"time_zone"`
+	// EmailVerifiedAt is when the user proved they own Email, nil until
+	// then.
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	// DeletionScheduledAt is when the account is erased, set while a
+	// deletion the user asked for is in its grace period.
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+	// TOTPSecret is the base32 secret of the user's authenticator, set from
+	// enrollment on. Two-factor authentication is only required once
+	// TOTPEnabledAt is set by confirming a code.
+	TOTPSecret    string     `json:"-"`
+	TOTPEnabledAt *time.Time `json:"totp_enabled_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// MFAEnabled reports whether the user has to enter a second factor to log
+// in.
+func (u *User) MFAEnabled() bool {
+	return u.TOTPEnabledAt != nil
 }
 
 func NewUser(username, email string) *User {
@@ -21,6 +39,7 @@ func NewUser(username, email string) *User {
 		ID:        uuid.New(),
 		Username:  username,
 		Email:     email,
+		TimeZone:  "UTC",
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -0,0 +1,147 @@
+package geocoding
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mmd-moradi/goup/configs"
+)
+
+// The bundled dataset is a reduced list of capitals and large cities in the
+// GeoNames cities export format. Deployments needing finer results can point
+// GEOCODING_CITIES_FILE at a full export such as cities15000.txt.
+//
+//go:embed data/cities.txt data/countries.txt
+var bundled embed.FS
+
+// Columns of the GeoNames cities and countryInfo exports.
+const (
+	cityColumns         = 19
+	cityNameColumn      = 1
+	cityLatitudeColumn  = 4
+	cityLongitudeColumn = 5
+	cityCountryColumn   = 8
+
+	countryCodeColumn = 0
+	countryNameColumn = 4
+)
+
+// Load builds a Geocoder from the configured dataset files, falling back to
+// the bundled dataset for files that aren't configured.
+func Load(cfg *configs.GeocodingConfig) (*Geocoder, error) {
+	countriesFile, err := openDataset(cfg.CountriesFile, "data/countries.txt")
+	if err != nil {
+		return nil, err
+	}
+	defer countriesFile.Close()
+
+	countries, err := ReadCountries(countriesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	citiesFile, err := openDataset(cfg.CitiesFile, "data/cities.txt")
+	if err != nil {
+		return nil, err
+	}
+	defer citiesFile.Close()
+
+	places, err := ReadCities(citiesFile, countries)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(places, float64(cfg.MaxDistanceKM)), nil
+}
+
+func openDataset(path, bundledPath string) (io.ReadCloser, error) {
+	if path == "" {
+		data, err := bundled.ReadFile(bundledPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundled dataset %s: %w", bundledPath, err)
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open geocoding dataset: %w", err)
+	}
+	return file, nil
+}
+
+// ReadCountries reads a GeoNames countryInfo file into a map from ISO
+// country code to country name.
+func ReadCountries(r io.Reader) (map[string]string, error) {
+	countries := make(map[string]string)
+	err := readRows(r, countryNameColumn+1, func(fields []string) error {
+		countries[fields[countryCodeColumn]] = fields[countryNameColumn]
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid countries dataset: %w", err)
+	}
+	return countries, nil
+}
+
+// ReadCities reads a GeoNames cities file. Country names are looked up in
+// countries by country code.
+func ReadCities(r io.Reader, countries map[string]string) ([]Place, error) {
+	var places []Place
+	err := readRows(r, cityColumns, func(fields []string) error {
+		lat, err := strconv.ParseFloat(fields[cityLatitudeColumn], 64)
+		if err != nil {
+			return fmt.Errorf("invalid latitude %q", fields[cityLatitudeColumn])
+		}
+		lng, err := strconv.ParseFloat(fields[cityLongitudeColumn], 64)
+		if err != nil {
+			return fmt.Errorf("invalid longitude %q", fields[cityLongitudeColumn])
+		}
+
+		countryCode := fields[cityCountryColumn]
+		places = append(places, Place{
+			Name:        fields[cityNameColumn],
+			CountryCode: countryCode,
+			CountryName: countries[countryCode],
+			Latitude:    lat,
+			Longitude:   lng,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid cities dataset: %w", err)
+	}
+	return places, nil
+}
+
+// readRows calls fn for each tab separated row of r, skipping empty lines and
+// comments starting with "#".
+func readRows(r io.Reader, minColumns int, fn func(fields []string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) < minColumns {
+			return fmt.Errorf("line %d: expected %d columns, got %d", line, minColumns, len(fields))
+		}
+		if err := fn(fields); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,74 @@
+// Package geocoding resolves coordinates to place names without any network
+// access. Places are loaded from a GeoNames style dataset into an in-memory
+// spatial index when the service starts.
+package geocoding
+
+import (
+	"math"
+)
+
+const earthRadiusKM = 6371
+
+// Place is a populated place of the dataset.
+type Place struct {
+	Name        string
+	CountryCode string
+	CountryName string
+	Latitude    float64
+	Longitude   float64
+}
+
+// Geocoder finds the place nearest to a location.
+type Geocoder struct {
+	tree          *kdTree
+	maxDistanceKM float64
+}
+
+// New indexes places. Locations farther than maxDistanceKM from every place
+// are not resolved; zero disables the limit.
+func New(places []Place, maxDistanceKM float64) *Geocoder {
+	return &Geocoder{
+		tree:          newKDTree(places),
+		maxDistanceKM: maxDistanceKM,
+	}
+}
+
+// Reverse returns the place nearest to lat, lng, or false when the dataset
+// has no place close enough.
+func (g *Geocoder) Reverse(lat, lng float64) (*Place, bool) {
+	place, chord := g.tree.nearest(toUnitVector(lat, lng))
+	if place == nil {
+		return nil, false
+	}
+
+	if g.maxDistanceKM > 0 && chordToKM(chord) > g.maxDistanceKM {
+		return nil, false
+	}
+
+	return place, true
+}
+
+// Len returns the number of indexed places.
+func (g *Geocoder) Len() int {
+	return g.tree.len()
+}
+
+// toUnitVector maps a location to a point on the unit sphere, where the
+// straight line distance between two points grows with their great circle
+// distance. Searching the nearest point this way needs no special handling
+// of the antimeridian or the poles.
+func toUnitVector(lat, lng float64) [3]float64 {
+	phi := lat * math.Pi / 180
+	lambda := lng * math.Pi / 180
+	return [3]float64{
+		math.Cos(phi) * math.Cos(lambda),
+		math.Cos(phi) * math.Sin(lambda),
+		math.Sin(phi),
+	}
+}
+
+// chordToKM converts a straight line distance between two points on the
+// unit sphere to the great circle distance in kilometers.
+func chordToKM(chord float64) float64 {
+	return 2 * math.Asin(math.Min(chord/2, 1)) * earthRadiusKM
+}
@@ -0,0 +1,95 @@
+package geocoding
+
+import (
+	"math"
+	"sort"
+)
+
+// kdTree is a static three dimensional k-d tree over places mapped to the
+// unit sphere. Nodes are stored in a slice, with the median of each range at
+// its middle.
+type kdTree struct {
+	nodes []kdNode
+}
+
+type kdNode struct {
+	point [3]float64
+	place *Place
+}
+
+func newKDTree(places []Place) *kdTree {
+	nodes := make([]kdNode, len(places))
+	for i := range places {
+		nodes[i] = kdNode{
+			point: toUnitVector(places[i].Latitude, places[i].Longitude),
+			place: &places[i],
+		}
+	}
+
+	build(nodes, 0)
+	return &kdTree{nodes: nodes}
+}
+
+// build orders nodes so that every range has its median on the splitting
+// axis at the middle, smaller values before it and larger ones after.
+func build(nodes []kdNode, depth int) {
+	if len(nodes) <= 1 {
+		return
+	}
+
+	axis := depth % 3
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].point[axis] < nodes[j].point[axis]
+	})
+
+	mid := len(nodes) / 2
+	build(nodes[:mid], depth+1)
+	build(nodes[mid+1:], depth+1)
+}
+
+func (t *kdTree) len() int {
+	return len(t.nodes)
+}
+
+// nearest returns the place closest to target and its straight line
+// distance.
+func (t *kdTree) nearest(target [3]float64) (*Place, float64) {
+	var best *Place
+	bestDist := math.Inf(1)
+	search(t.nodes, 0, target, &best, &bestDist)
+	return best, math.Sqrt(bestDist)
+}
+
+// search walks the range containing target first and only visits the other
+// side of a split when it could hold a closer point. Distances are squared.
+func search(nodes []kdNode, depth int, target [3]float64, best **Place, bestDist *float64) {
+	if len(nodes) == 0 {
+		return
+	}
+
+	mid := len(nodes) / 2
+	node := &nodes[mid]
+	if dist := squaredDistance(node.point, target); dist < *bestDist {
+		*best = node.place
+		*bestDist = dist
+	}
+
+	axis := depth % 3
+	diff := target[axis] - node.point[axis]
+	near, far := nodes[:mid], nodes[mid+1:]
+	if diff > 0 {
+		near, far = far, near
+	}
+
+	search(near, depth+1, target, best, bestDist)
+	if diff*diff < *bestDist {
+		search(far, depth+1, target, best, bestDist)
+	}
+}
+
+func squaredDistance(a, b [3]float64) float64 {
+	dx := a[0] - b[0]
+	dy := a[1] - b[1]
+	dz := a[2] - b[2]
+	return dx*dx + dy*dy + dz*dz
+}
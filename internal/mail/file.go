@@ -0,0 +1,60 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// FileMailer appends emails to a file instead of sending them, or logs them
+// when no file is configured. It stands in for SMTP in development and
+// tests.
+type FileMailer struct {
+	from   string
+	path   string
+	logger zerolog.Logger
+	mu     sync.Mutex
+}
+
+func NewFileMailer(from, path string, logger zerolog.Logger) *FileMailer {
+	return &FileMailer{
+		from:   from,
+		path:   path,
+		logger: logger,
+	}
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	if err := validateHeaders(msg); err != nil {
+		return err
+	}
+
+	if m.path == "" {
+		m.logger.Info().
+			Str("to", msg.To).
+			Str("subject", msg.Subject).
+			Str("body", msg.Body).
+			Msg("mail not sent, no mail file configured")
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	file, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open mail file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(formatMessage(m.from, msg)); err != nil {
+		return fmt.Errorf("failed to write mail file: %w", err)
+	}
+	if _, err := file.WriteString("\r\n\r\n"); err != nil {
+		return fmt.Errorf("failed to write mail file: %w", err)
+	}
+	return nil
+}
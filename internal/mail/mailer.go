@@ -0,0 +1,42 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mmd-moradi/goup/configs"
+	"github.com/rs/zerolog"
+)
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails to users.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New returns the mailer selected by cfg.Driver.
+func New(cfg *configs.MailConfig, logger zerolog.Logger) (Mailer, error) {
+	switch cfg.Driver {
+	case configs.MailDriverSMTP:
+		return NewSMTPMailer(cfg), nil
+	case configs.MailDriverFile:
+		return NewFileMailer(cfg.From, cfg.File, logger), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+	}
+}
+
+// validateHeaders rejects header values that could inject extra headers.
+func validateHeaders(msg Message) error {
+	if strings.ContainsAny(msg.To, "\r\n") || strings.ContainsAny(msg.Subject, "\r\n") {
+		return fmt.Errorf("mail headers must not contain line breaks")
+	}
+	return nil
+}
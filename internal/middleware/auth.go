@@ -13,7 +13,10 @@ import (
 
 type contextKey string
 
-const UserIDKey contextKey = "userID"
+const (
+	UserIDKey      contextKey = "userID"
+	TokenFamilyKey contextKey = "tokenFamily"
+)
 
 func Authenticate(tokenSVC *auth.TokenService) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -31,13 +34,14 @@ func Authenticate(tokenSVC *auth.TokenService) func(next http.Handler) http.Hand
 				return
 			}
 
-			userID, err := tokenSVC.ValidateToken(parts[1])
+			info, err := tokenSVC.ValidateToken(parts[1])
 			if err != nil {
 				response.Error(w, err)
 				return
 			}
 
-			ctx := context.WithValue(r.Context(), UserIDKey, userID)
+			ctx := context.WithValue(r.Context(), UserIDKey, info.UserID)
+			ctx = context.WithValue(ctx, TokenFamilyKey, info.FamilyID)
 
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
@@ -51,3 +55,10 @@ func GetUserID(ctx context.Context) (uuid.UUID, error) {
 	}
 	return userID, nil
 }
+
+// GetTokenFamily returns the token family of the request's access token,
+// which identifies its session.
+func GetTokenFamily(ctx context.Context) string {
+	familyID, _ := ctx.Value(TokenFamilyKey).(string)
+	return familyID
+}
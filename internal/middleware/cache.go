@@ -0,0 +1,14 @@
+package middleware
+
+import "net/http"
+
+// NoStore tells clients and proxies not to cache the response. Unlike chi's
+// NoCache it leaves conditional request headers such as If-Match intact.
+func NoStore(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store, private")
+		w.Header().Set("Pragma", "no-cache")
+		w.Header().Set("Expires", "0")
+		next.ServeHTTP(w, r)
+	})
+}
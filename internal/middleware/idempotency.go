@@ -0,0 +1,414 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/mmd-moradi/goup/pkg/response"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed from an earlier
+	// request with the same key.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	// idempotencyMemory is how much of a request body is held in memory to
+	// fingerprint it. Larger bodies, such as batch uploads, are spooled to
+	// a temporary file.
+	idempotencyMemory = 10 << 20
+)
+
+// replayedHeaders are the response headers stored and replayed along with
+// the status and body.
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// IdempotencyStore keeps the responses of requests sent with an
+// Idempotency-Key header in Redis.
+type IdempotencyStore struct {
+	redis  *redis.Client
+	logger zerolog.Logger
+	// ttl is how long a response is kept for replay.
+	ttl time.Duration
+	// lockTimeout is how long a key stays claimed by a request that never
+	// finishes, e.g. because the server stopped while handling it. The
+	// claim is extended for as long as the request is being handled.
+	lockTimeout time.Duration
+	prefix      string
+}
+
+// idempotencyRecord is the state of a key. Requests in progress have no
+// status yet.
+type idempotencyRecord struct {
+	Fingerprint string            `json:"fingerprint"`
+	Status      int               `json:"status,omitempty"`
+	Header      map[string]string `json:"header,omitempty"`
+	Body        []byte            `json:"body,omitempty"`
+}
+
+func NewIdempotencyStore(redis *redis.Client, ttl, lockTimeout time.Duration, logger zerolog.Logger) *IdempotencyStore {
+	return &IdempotencyStore{
+		redis:       redis,
+		logger:      logger,
+		ttl:         ttl,
+		lockTimeout: lockTimeout,
+		prefix:      "idempotency:",
+	}
+}
+
+// Idempotent makes a route safe to retry. The first request with a given
+// Idempotency-Key is handled and its response stored; retries with the same
+// key and payload get the stored response back instead of being handled
+// again. A retry arriving while the first request is still being handled
+// fails with 409, and reusing a key for a different payload fails with 422.
+// Server errors are not stored so the request can be retried.
+//
+// Keys are scoped to the authenticated user, so the middleware must run
+// after Authenticate. Requests without the header are handled as usual.
+// Bodies are read in full to fingerprint them before the request is handled
+// and may be at most maxBodySize bytes.
+func Idempotent(store *IdempotencyStore, maxBodySize int64) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				response.Error(w, apperrors.NewWithFormat(apperrors.BadRequest, "%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength))
+				return
+			}
+
+			userID, err := GetUserID(r.Context())
+			if err != nil {
+				response.Error(w, err)
+				return
+			}
+
+			body, cleanup, err := spoolBody(w, r, maxBodySize)
+			if err != nil {
+				response.Error(w, err)
+				return
+			}
+			defer cleanup()
+
+			fingerprint, err := requestFingerprint(r, body)
+			if err != nil {
+				response.Error(w, err)
+				return
+			}
+			r.Body = io.NopCloser(body)
+
+			redisKey := store.prefix + userID.String() + ":" + key
+			record, claimed, err := store.claim(r.Context(), redisKey, fingerprint)
+			if err != nil {
+				response.Error(w, err)
+				return
+			}
+			if !claimed {
+				replay(w, record, fingerprint)
+				return
+			}
+
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			stopHolding := store.hold(redisKey)
+			defer func() {
+				// Release the key if the handler panicked, so a retry isn't
+				// rejected until the lock times out.
+				if p := recover(); p != nil {
+					stopHolding()
+					store.release(redisKey)
+					panic(p)
+				}
+			}()
+			next.ServeHTTP(rec, r)
+			stopHolding()
+
+			if rec.status >= http.StatusInternalServerError {
+				store.release(redisKey)
+				return
+			}
+			store.save(redisKey, rec.record(fingerprint))
+		})
+	}
+}
+
+// claim reserves key for a request with the given fingerprint. If the key
+// is already in use it returns the existing record instead.
+func (s *IdempotencyStore) claim(ctx context.Context, key, fingerprint string) (*idempotencyRecord, bool, error) {
+	data, err := json.Marshal(idempotencyRecord{Fingerprint: fingerprint})
+	if err != nil {
+		return nil, false, apperrors.NewWithFormat(apperrors.InternalServer, "failed to encode idempotency record: %v", err)
+	}
+
+	claimed, err := s.redis.SetNX(ctx, key, data, s.lockTimeout).Result()
+	if err != nil {
+		return nil, false, apperrors.NewWithFormat(apperrors.ServiceUnavailable, "failed to check idempotency key: %v", err)
+	}
+	if claimed {
+		return nil, true, nil
+	}
+
+	existing, err := s.redis.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// The other request released the key between the two calls.
+		return s.claim(ctx, key, fingerprint)
+	}
+	if err != nil {
+		return nil, false, apperrors.NewWithFormat(apperrors.ServiceUnavailable, "failed to check idempotency key: %v", err)
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal(existing, &record); err != nil {
+		return nil, false, apperrors.NewWithFormat(apperrors.InternalServer, "failed to decode idempotency record: %v", err)
+	}
+	return &record, false, nil
+}
+
+// hold extends the claim on key while its request is handled, so a handler
+// taking longer than the lock timeout doesn't let a retry in while it is
+// still running. The returned function stops extending the claim.
+func (s *IdempotencyStore) hold(key string) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(s.lockTimeout / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			err := s.redis.Expire(ctx, key, s.lockTimeout).Err()
+			cancel()
+			if err != nil {
+				s.logger.Error().Err(err).Str("key", key).Msg("failed to extend idempotency key claim")
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// save stores the response of a finished request. It doesn't use the
+// request's context, which is canceled when the client gives up, because
+// that is when the client is most likely to retry.
+func (s *IdempotencyStore) save(key string, record *idempotencyRecord) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	data, err := json.Marshal(record)
+	if err == nil {
+		err = s.redis.Set(ctx, key, data, s.ttl).Err()
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Str("key", key).Msg("failed to save idempotent response")
+		s.release(key)
+	}
+}
+
+func (s *IdempotencyStore) release(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := s.redis.Del(ctx, key).Err(); err != nil {
+		s.logger.Error().Err(err).Str("key", key).Msg("failed to release idempotency key")
+	}
+}
+
+func replay(w http.ResponseWriter, record *idempotencyRecord, fingerprint string) {
+	if record.Fingerprint != fingerprint {
+		response.Error(w, apperrors.NewWithFormat(apperrors.UnprocessableEntity, "%s was already used for a different request", IdempotencyKeyHeader))
+		return
+	}
+	if record.Status == 0 {
+		response.Error(w, apperrors.NewWithFormat(apperrors.Conflict, "a request with this %s is still being processed", IdempotencyKeyHeader))
+		return
+	}
+
+	for name, value := range record.Header {
+		w.Header().Set(name, value)
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(record.Status)
+	w.Write(record.Body)
+}
+
+// spoolBody reads the body of r, of at most maxBodySize bytes, so that it
+// can be fingerprinted and then read again by the handler. Bodies larger
+// than idempotencyMemory are spooled to a temporary file, which the
+// returned function removes.
+func spoolBody(w http.ResponseWriter, r *http.Request, maxBodySize int64) (io.ReadSeeker, func(), error) {
+	body := http.MaxBytesReader(w, r.Body, maxBodySize)
+	head, err := io.ReadAll(io.LimitReader(body, idempotencyMemory+1))
+	if err != nil {
+		return nil, nil, bodyError(err, maxBodySize)
+	}
+	if len(head) <= idempotencyMemory {
+		return bytes.NewReader(head), func() {}, nil
+	}
+
+	file, err := os.CreateTemp("", "idempotent-body-*")
+	if err != nil {
+		return nil, nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to buffer request body: %v", err)
+	}
+	cleanup := func() {
+		file.Close()
+		os.Remove(file.Name())
+	}
+
+	_, err = io.Copy(file, io.MultiReader(bytes.NewReader(head), body))
+	if err != nil {
+		cleanup()
+		return nil, nil, bodyError(err, maxBodySize)
+	}
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		cleanup()
+		return nil, nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to buffer request body: %v", err)
+	}
+	return file, cleanup, nil
+}
+
+func bodyError(err error, maxBodySize int64) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return apperrors.NewWithFormat(apperrors.BadRequest, "request body exceeds the maximum size of %d bytes", maxBodySize)
+	}
+	return apperrors.NewWithFormat(apperrors.BadRequest, "failed to read request body: %v", err)
+}
+
+// requestFingerprint identifies the payload of a request and rewinds body
+// for the handler. Multipart bodies are fingerprinted by their parts rather
+// than their bytes, since clients may pick a new boundary when they retry.
+func requestFingerprint(r *http.Request, body io.ReadSeeker) (string, error) {
+	h := sha256.New()
+	writeField(h, []byte(r.Method))
+	writeField(h, []byte(r.URL.Path))
+	writeField(h, []byte(r.URL.RawQuery))
+
+	mediaType, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		writeField(h, []byte(mediaType))
+		size, err := body.Seek(0, io.SeekEnd)
+		if err == nil {
+			_, err = body.Seek(0, io.SeekStart)
+		}
+		if err != nil {
+			return "", apperrors.NewWithFormat(apperrors.InternalServer, "failed to read request body: %v", err)
+		}
+		err = writeFieldFrom(h, body, size)
+		if err != nil {
+			return "", apperrors.NewWithFormat(apperrors.InternalServer, "failed to read request body: %v", err)
+		}
+	} else {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", apperrors.New(apperrors.BadRequest, "invalid multipart body")
+			}
+
+			writeField(h, []byte(part.FormName()))
+			writeField(h, []byte(part.FileName()))
+			writeField(h, []byte(part.Header.Get("Content-Type")))
+			// Parts may be large, so their content is hashed on its own
+			// rather than read into memory to prefix it with its length.
+			content := sha256.New()
+			if _, err := io.Copy(content, part); err != nil {
+				return "", apperrors.New(apperrors.BadRequest, "invalid multipart body")
+			}
+			writeField(h, content.Sum(nil))
+		}
+	}
+
+	_, err := body.Seek(0, io.SeekStart)
+	if err != nil {
+		return "", apperrors.NewWithFormat(apperrors.InternalServer, "failed to read request body: %v", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeField writes a length prefixed value, so that different splits of
+// the same bytes give different fingerprints.
+func writeField(h hash.Hash, value []byte) {
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(value)))
+	h.Write(length[:])
+	h.Write(value)
+}
+
+// writeFieldFrom is writeField for a value of size bytes read from r.
+func writeFieldFrom(h hash.Hash, r io.Reader, size int64) error {
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(size))
+	h.Write(length[:])
+	_, err := io.CopyN(h, r, size)
+	return err
+}
+
+// responseRecorder passes a response through while keeping a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+func (rec *responseRecorder) record(fingerprint string) *idempotencyRecord {
+	record := &idempotencyRecord{
+		Fingerprint: fingerprint,
+		Status:      rec.status,
+		Header:      make(map[string]string),
+		Body:        rec.body.Bytes(),
+	}
+	for _, name := range replayedHeaders {
+		if value := rec.Header().Get(name); value != "" {
+			record.Header[name] = value
+		}
+	}
+	return record
+}
@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/domain"
+)
+
+type AlbumRepository interface {
+	Create(ctx context.Context, album *domain.Album) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Album, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.Album, error)
+	GetSharedWithUser(ctx context.Context, userID uuid.UUID) ([]*domain.Album, error)
+	Update(ctx context.Context, album *domain.Album) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
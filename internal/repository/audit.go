@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/domain"
+)
+
+type AuditRepository interface {
+	Create(ctx context.Context, event *domain.AuditEvent) error
+	// GetBySubject returns the events recorded for a record, oldest first.
+	GetBySubject(ctx context.Context, subjectType string, subjectID uuid.UUID) ([]*domain.AuditEvent, error)
+	// GetByUserID returns the events of actions the user took or that were
+	// taken on their account, oldest first.
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.AuditEvent, error)
+}
@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/domain"
+)
+
+type CustomFieldRepository interface {
+	Create(ctx context.Context, definition *domain.CustomFieldDefinition) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.CustomFieldDefinition, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.CustomFieldDefinition, error)
+	CountByUserID(ctx context.Context, userID uuid.UUID) (int, error)
+	Update(ctx context.Context, definition *domain.CustomFieldDefinition) error
+	// Delete deletes a definition and removes its values from the user's
+	// photos.
+	Delete(ctx context.Context, definition *domain.CustomFieldDefinition, now time.Time) error
+}
@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/domain"
+)
+
+type ExportJobRepository interface {
+	Create(ctx context.Context, job *domain.ExportJob) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.ExportJob, error)
+	// GetLatestByKind returns the most recent export of kind the user
+	// requested.
+	GetLatestByKind(ctx context.Context, userID uuid.UUID, kind domain.ExportKind) (*domain.ExportJob, error)
+	Update(ctx context.Context, job *domain.ExportJob) error
+	// FailStale marks the jobs created before staleBefore that are still
+	// pending or running as failed with message and returns them.
+	FailStale(ctx context.Context, staleBefore time.Time, message string, now time.Time) ([]*domain.ExportJob, error)
+	// GetExpired returns up to limit completed jobs whose download link
+	// expired before now, oldest first.
+	GetExpired(ctx context.Context, now time.Time, limit int) ([]*domain.ExportJob, error)
+}
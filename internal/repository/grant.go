@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/domain"
+)
+
+type GrantRepository interface {
+	Upsert(ctx context.Context, grant *domain.Grant) (*domain.Grant, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Grant, error)
+	GetByPhotoID(ctx context.Context, photoID uuid.UUID) ([]*domain.Grant, error)
+	GetByAlbumID(ctx context.Context, albumID uuid.UUID) ([]*domain.Grant, error)
+	// GetByUserID returns the grants the user gave or received, newest
+	// first.
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.Grant, error)
+	// GetPhotoRoles returns the roles granted to the user on the photo, either
+	// directly or through the album it belongs to.
+	GetPhotoRoles(ctx context.Context, photoID uuid.UUID, albumID *uuid.UUID, userID uuid.UUID) ([]domain.Role, error)
+	// GetAlbumRole returns the role granted to the user on the album, or an
+	// empty role if there is none.
+	GetAlbumRole(ctx context.Context, albumID uuid.UUID, userID uuid.UUID) (domain.Role, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
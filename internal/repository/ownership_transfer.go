@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/domain"
+)
+
+type OwnershipTransferRepository interface {
+	Create(ctx context.Context, transfer *domain.OwnershipTransfer) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.OwnershipTransfer, error)
+	// GetByUserID returns the transfers the user sent or received, newest
+	// first.
+	GetByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*domain.OwnershipTransfer, error)
+	// UpdateStatus saves the status of a transfer that is still in
+	// currentStatus, and fails with a conflict otherwise.
+	UpdateStatus(ctx context.Context, transfer *domain.OwnershipTransfer, currentStatus domain.TransferStatus) error
+	// FailStale marks the transfers that are still running and were last
+	// updated before staleBefore as failed with message and returns them.
+	FailStale(ctx context.Context, staleBefore time.Time, message string, now time.Time) ([]*domain.OwnershipTransfer, error)
+	// Complete moves the photos and albums to the recipient, marks the
+	// running transfer completed and records event, all in one
+	// transaction. The storage both users use afterwards is added to the
+	// details of event as from_storage_used and to_storage_used.
+	Complete(ctx context.Context, transfer *domain.OwnershipTransfer, photos []*domain.TransferredPhoto, albumIDs []uuid.UUID, event *domain.AuditEvent) error
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -12,8 +13,56 @@ type PhotoRepository interface {
 	Create(ctx context.Context, photo *domain.Photo) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Photo, error)
 	GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Photo, int, error)
+	// GetStorageUsage returns the bytes the user's photos take up in
+	// storage, previous versions included.
+	GetStorageUsage(ctx context.Context, userID uuid.UUID) (int64, error)
+	GetPublicByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Photo, int, error)
+	ListPublic(ctx context.Context, cursor *domain.PhotoCursor, limit int) ([]*domain.Photo, error)
+	GetByAlbumID(ctx context.Context, albumID uuid.UUID, limit, offset int) ([]*domain.Photo, int, error)
+	GetSharedWithUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Photo, int, error)
+	GetInBoundingBox(ctx context.Context, userID uuid.UUID, box domain.BoundingBox, limit int) ([]*domain.Photo, error)
+	// GetNear returns the user's photos within radiusMeters of lat, lng,
+	// nearest first.
+	GetNear(ctx context.Context, userID uuid.UUID, lat, lng, radiusMeters float64, limit int) ([]*domain.Photo, error)
+	// GetClusters groups the user's photos in box into square grid cells of
+	// cellSize degrees.
+	GetClusters(ctx context.Context, userID uuid.UUID, box domain.BoundingBox, cellSize float64) ([]*domain.GeoCluster, error)
+	// GetByPlace returns the user's photos whose place or country name
+	// contains query.
+	GetByPlace(ctx context.Context, userID uuid.UUID, query string, limit, offset int) ([]*domain.Photo, int, error)
+	// GetByCustomFields returns the user's photos whose custom fields have
+	// all the values in filter.
+	GetByCustomFields(ctx context.Context, userID uuid.UUID, filter map[string]interface{}, limit, offset int) ([]*domain.Photo, int, error)
+	// GetPlaces returns the places of the user's photos whose name or
+	// country contains query, most photographed first.
+	GetPlaces(ctx context.Context, userID uuid.UUID, query string, limit int) ([]*domain.PlaceGroup, error)
+	// GetUnalbumedPlaces returns the places with at least minPhotos of the
+	// user's photos that aren't in an album.
+	GetUnalbumedPlaces(ctx context.Context, userID uuid.UUID, minPhotos, limit int) ([]*domain.PlaceGroup, error)
+	// GetMissingPlace returns geotagged photos without a place, ordered by
+	// ID and starting after afterID.
+	GetMissingPlace(ctx context.Context, afterID uuid.UUID, limit int) ([]*domain.Photo, error)
+	UpdatePlace(ctx context.Context, photo *domain.Photo) error
+	// GetDateBuckets counts the user's photos per year, month or day
+	// (granularity) in timeZone, newest first.
+	GetDateBuckets(ctx context.Context, userID uuid.UUID, granularity, timeZone string) ([]*domain.DateBucket, error)
+	// CountSince counts the user's photos dated at or after since.
+	CountSince(ctx context.Context, userID uuid.UUID, since time.Time) (int, error)
+	SetAlbum(ctx context.Context, photo *domain.Photo) error
+	AddTags(ctx context.Context, photo *domain.Photo, tags []string) error
 	Update(ctx context.Context, photo *domain.Photo) error
+	UpdateStorageInfo(ctx context.Context, photo *domain.Photo) error
 	Delete(ctx context.Context, id uuid.UUID) error
 
+	CreateVersion(ctx context.Context, version *domain.PhotoVersion) error
+	GetVersion(ctx context.Context, photoID uuid.UUID, versionNumber int) (*domain.PhotoVersion, error)
+	GetVersions(ctx context.Context, photoID uuid.UUID) ([]*domain.PhotoVersion, error)
+	// GetVersionsByUserID returns the versions of all the user's photos.
+	GetVersionsByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.PhotoVersion, error)
+	// GetVersionsBeyondLimit returns the versions of the user's photos that
+	// are older than the newest keep versions.
+	GetVersionsBeyondLimit(ctx context.Context, userID uuid.UUID, keep int) ([]*domain.PhotoVersion, error)
+	DeleteVersion(ctx context.Context, id uuid.UUID) error
+
 	WithTx(ctx context.Context, txOption pgx.TxOptions, fn func(PhotoRepository) error) error
 }
@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mmd-moradi/goup/internal/domain"
+	"github.com/mmd-moradi/goup/internal/repository/postgres/db"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+)
+
+type AlbumRepository struct {
+	queries *db.Queries
+	pool    *pgxpool.Pool
+}
+
+func NewAlbumRepository(pool *pgxpool.Pool) *AlbumRepository {
+	return &AlbumRepository{
+		queries: db.New(pool),
+		pool:    pool,
+	}
+}
+
+func (r *AlbumRepository) Create(ctx context.Context, album *domain.Album) error {
+	_, err := r.queries.CreateAlbum(ctx, db.CreateAlbumParams{
+		ID:          album.ID,
+		UserID:      album.UserID,
+		Title:       album.Title,
+		Description: pgtype.Text{String: album.Description, Valid: album.Description != ""},
+		CreatedAt:   TimeToTimestamptz(album.CreatedAt),
+		UpdatedAt:   TimeToTimestamptz(album.UpdatedAt),
+	})
+
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to create album: %v", err)
+	}
+
+	return nil
+}
+
+func (r *AlbumRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Album, error) {
+	album, err := r.queries.GetAlbumByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperrors.NewWithFormat(apperrors.NotFound, "album with id %s not found", id)
+		}
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to get album: %v", err)
+	}
+
+	return toDomainAlbum(album), nil
+}
+
+func (r *AlbumRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.Album, error) {
+	albums, err := r.queries.ListAlbumsByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list albums: %v", err)
+	}
+
+	return toDomainAlbums(albums), nil
+}
+
+func (r *AlbumRepository) GetSharedWithUser(ctx context.Context, userID uuid.UUID) ([]*domain.Album, error) {
+	albums, err := r.queries.ListAlbumsSharedWithUser(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list shared albums: %v", err)
+	}
+
+	return toDomainAlbums(albums), nil
+}
+
+func (r *AlbumRepository) Update(ctx context.Context, album *domain.Album) error {
+	_, err := r.queries.UpdateAlbum(ctx, db.UpdateAlbumParams{
+		ID:          album.ID,
+		Title:       album.Title,
+		Description: pgtype.Text{String: album.Description, Valid: album.Description != ""},
+		UpdatedAt:   TimeToTimestamptz(album.UpdatedAt),
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return apperrors.NewWithFormat(apperrors.NotFound, "album with id %s not found", album.ID)
+		}
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to update album: %v", err)
+	}
+
+	return nil
+}
+
+func (r *AlbumRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	err := r.queries.DeleteAlbum(ctx, id)
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to delete album: %v", err)
+	}
+
+	return nil
+}
+
+func toDomainAlbum(album db.Album) *domain.Album {
+	return &domain.Album{
+		ID:          album.ID,
+		UserID:      album.UserID,
+		Title:       album.Title,
+		Description: album.Description.String,
+		CreatedAt:   TimestamptzToTime(album.CreatedAt),
+		UpdatedAt:   TimestamptzToTime(album.UpdatedAt),
+	}
+}
+
+func toDomainAlbums(albums []db.Album) []*domain.Album {
+	result := make([]*domain.Album, len(albums))
+	for i, album := range albums {
+		result[i] = toDomainAlbum(album)
+	}
+	return result
+}
@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mmd-moradi/goup/internal/domain"
+	"github.com/mmd-moradi/goup/internal/repository/postgres/db"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+)
+
+type AuditRepository struct {
+	queries *db.Queries
+	pool    *pgxpool.Pool
+}
+
+func NewAuditRepository(pool *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{
+		queries: db.New(pool),
+		pool:    pool,
+	}
+}
+
+func (r *AuditRepository) Create(ctx context.Context, event *domain.AuditEvent) error {
+	return createAuditEvent(ctx, r.queries, event)
+}
+
+func (r *AuditRepository) GetBySubject(ctx context.Context, subjectType string, subjectID uuid.UUID) ([]*domain.AuditEvent, error) {
+	events, err := r.queries.ListAuditEventsBySubject(ctx, db.ListAuditEventsBySubjectParams{
+		SubjectType: subjectType,
+		SubjectID:   subjectID,
+	})
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list audit events: %v", err)
+	}
+
+	result := make([]*domain.AuditEvent, len(events))
+	for i, event := range events {
+		result[i] = toDomainAuditEvent(event)
+	}
+	return result, nil
+}
+
+func (r *AuditRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.AuditEvent, error) {
+	events, err := r.queries.ListAuditEventsByUserID(ctx, &userID)
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list audit events: %v", err)
+	}
+
+	result := make([]*domain.AuditEvent, len(events))
+	for i, event := range events {
+		result[i] = toDomainAuditEvent(event)
+	}
+	return result, nil
+}
+
+// createAuditEvent is shared with repositories that record an event in the
+// same transaction as the change it describes.
+func createAuditEvent(ctx context.Context, queries *db.Queries, event *domain.AuditEvent) error {
+	details, err := json.Marshal(event.Details)
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to encode audit event: %v", err)
+	}
+
+	err = queries.CreateAuditEvent(ctx, db.CreateAuditEventParams{
+		ID:          event.ID,
+		ActorID:     event.ActorID,
+		Action:      event.Action,
+		SubjectType: event.SubjectType,
+		SubjectID:   event.SubjectID,
+		Details:     details,
+		CreatedAt:   TimeToTimestamptz(event.CreatedAt),
+	})
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to create audit event: %v", err)
+	}
+	return nil
+}
+
+func toDomainAuditEvent(event db.AuditEvent) *domain.AuditEvent {
+	details := make(map[string]interface{})
+	_ = json.Unmarshal(event.Details, &details)
+	return &domain.AuditEvent{
+		ID:          event.ID,
+		ActorID:     event.ActorID,
+		Action:      event.Action,
+		SubjectType: event.SubjectType,
+		SubjectID:   event.SubjectID,
+		Details:     details,
+		CreatedAt:   TimestamptzToTime(event.CreatedAt),
+	}
+}
@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mmd-moradi/goup/internal/domain"
+	"github.com/mmd-moradi/goup/internal/repository/postgres/db"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+)
+
+type CustomFieldRepository struct {
+	queries *db.Queries
+	pool    *pgxpool.Pool
+}
+
+func NewCustomFieldRepository(pool *pgxpool.Pool) *CustomFieldRepository {
+	return &CustomFieldRepository{
+		queries: db.New(pool),
+		pool:    pool,
+	}
+}
+
+func (r *CustomFieldRepository) Create(ctx context.Context, definition *domain.CustomFieldDefinition) error {
+	_, err := r.queries.CreateCustomFieldDefinition(ctx, db.CreateCustomFieldDefinitionParams{
+		ID:            definition.ID,
+		UserID:        definition.UserID,
+		Name:          definition.Name,
+		Type:          string(definition.Type),
+		Required:      definition.Required,
+		AllowedValues: definition.AllowedValues,
+		CreatedAt:     TimeToTimestamptz(definition.CreatedAt),
+		UpdatedAt:     TimeToTimestamptz(definition.UpdatedAt),
+	})
+
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to create custom field: %v", err)
+	}
+
+	return nil
+}
+
+func (r *CustomFieldRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.CustomFieldDefinition, error) {
+	definition, err := r.queries.GetCustomFieldDefinitionByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperrors.NewWithFormat(apperrors.NotFound, "custom field with id %s not found", id)
+		}
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to get custom field: %v", err)
+	}
+
+	return toDomainCustomFieldDefinition(definition), nil
+}
+
+func (r *CustomFieldRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.CustomFieldDefinition, error) {
+	definitions, err := r.queries.ListCustomFieldDefinitionsByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list custom fields: %v", err)
+	}
+
+	result := make([]*domain.CustomFieldDefinition, len(definitions))
+	for i, definition := range definitions {
+		result[i] = toDomainCustomFieldDefinition(definition)
+	}
+	return result, nil
+}
+
+func (r *CustomFieldRepository) CountByUserID(ctx context.Context, userID uuid.UUID) (int, error) {
+	count, err := r.queries.CountCustomFieldDefinitionsByUserID(ctx, userID)
+	if err != nil {
+		return 0, apperrors.NewWithFormat(apperrors.InternalServer, "failed to count custom fields: %v", err)
+	}
+	return int(count), nil
+}
+
+func (r *CustomFieldRepository) Update(ctx context.Context, definition *domain.CustomFieldDefinition) error {
+	_, err := r.queries.UpdateCustomFieldDefinition(ctx, db.UpdateCustomFieldDefinitionParams{
+		ID:            definition.ID,
+		Required:      definition.Required,
+		AllowedValues: definition.AllowedValues,
+		UpdatedAt:     TimeToTimestamptz(definition.UpdatedAt),
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return apperrors.NewWithFormat(apperrors.NotFound, "custom field with id %s not found", definition.ID)
+		}
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to update custom field: %v", err)
+	}
+
+	return nil
+}
+
+func (r *CustomFieldRepository) Delete(ctx context.Context, definition *domain.CustomFieldDefinition, now time.Time) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to delete custom field: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	queries := r.queries.WithTx(tx)
+	err = queries.RemovePhotoCustomField(ctx, db.RemovePhotoCustomFieldParams{
+		Name:      definition.Name,
+		UpdatedAt: TimeToTimestamptz(now),
+		UserID:    definition.UserID,
+	})
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to remove custom field from photos: %v", err)
+	}
+
+	err = queries.DeleteCustomFieldDefinition(ctx, definition.ID)
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to delete custom field: %v", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to delete custom field: %v", err)
+	}
+	return nil
+}
+
+func toDomainCustomFieldDefinition(definition db.CustomFieldDefinition) *domain.CustomFieldDefinition {
+	allowedValues := definition.AllowedValues
+	if allowedValues == nil {
+		allowedValues = []string{}
+	}
+	return &domain.CustomFieldDefinition{
+		ID:            definition.ID,
+		UserID:        definition.UserID,
+		Name:          definition.Name,
+		Type:          domain.CustomFieldType(definition.Type),
+		Required:      definition.Required,
+		AllowedValues: allowedValues,
+		CreatedAt:     TimestamptzToTime(definition.CreatedAt),
+		UpdatedAt:     TimestamptzToTime(definition.UpdatedAt),
+	}
+}
@@ -9,25 +9,144 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-type Photo struct {
+type AccessGrant struct {
+	ID        uuid.UUID          `json:"id"`
+	PhotoID   *uuid.UUID         `json:"photo_id"`
+	AlbumID   *uuid.UUID         `json:"album_id"`
+	GranteeID uuid.UUID          `json:"grantee_id"`
+	GrantedBy uuid.UUID          `json:"granted_by"`
+	Role      string             `json:"role"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type Album struct {
 	ID          uuid.UUID          `json:"id"`
 	UserID      uuid.UUID          `json:"user_id"`
 	Title       string             `json:"title"`
 	Description pgtype.Text        `json:"description"`
-	FileName    string             `json:"file_name"`
-	FileSize    int64              `json:"file_size"`
-	ContentType string             `json:"content_type"`
-	StoragePath string             `json:"storage_path"`
-	PublicUrl   pgtype.Text        `json:"public_url"`
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
 }
 
-type User struct {
+type AuditEvent struct {
+	ID          uuid.UUID          `json:"id"`
+	ActorID     *uuid.UUID         `json:"actor_id"`
+	Action      string             `json:"action"`
+	SubjectType string             `json:"subject_type"`
+	SubjectID   uuid.UUID          `json:"subject_id"`
+	Details     []byte             `json:"details"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type CustomFieldDefinition struct {
+	ID            uuid.UUID          `json:"id"`
+	UserID        uuid.UUID          `json:"user_id"`
+	Name          string             `json:"name"`
+	Type          string             `json:"type"`
+	Required      bool               `json:"required"`
+	AllowedValues []string           `json:"allowed_values"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+}
+
+type ExportJob struct {
+	ID          uuid.UUID          `json:"id"`
+	UserID      uuid.UUID          `json:"user_id"`
+	Status      string             `json:"status"`
+	PhotoIds    []uuid.UUID        `json:"photo_ids"`
+	StoragePath pgtype.Text        `json:"storage_path"`
+	Error       pgtype.Text        `json:"error"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	Kind        string             `json:"kind"`
+}
+
+type OwnershipTransfer struct {
+	ID          uuid.UUID          `json:"id"`
+	FromUserID  uuid.UUID          `json:"from_user_id"`
+	ToUserID    uuid.UUID          `json:"to_user_id"`
+	Status      string             `json:"status"`
+	Selection   string             `json:"selection"`
+	PhotoIds    []uuid.UUID        `json:"photo_ids"`
+	AlbumIds    []uuid.UUID        `json:"album_ids"`
+	PhotoCount  int32              `json:"photo_count"`
+	Error       pgtype.Text        `json:"error"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+	CompletedAt pgtype.Timestamptz `json:"completed_at"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Photo struct {
 	ID           uuid.UUID          `json:"id"`
-	Username     string             `json:"username"`
-	Email        string             `json:"email"`
-	PasswordHash string             `json:"password_hash"`
+	UserID       uuid.UUID          `json:"user_id"`
+	Title        string             `json:"title"`
+	Description  pgtype.Text        `json:"description"`
+	FileName     string             `json:"file_name"`
+	FileSize     int64              `json:"file_size"`
+	ContentType  string             `json:"content_type"`
+	StoragePath  string             `json:"storage_path"`
+	PublicUrl    pgtype.Text        `json:"public_url"`
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	Visibility   string             `json:"visibility"`
+	AlbumID      *uuid.UUID         `json:"album_id"`
+	Tags         []string           `json:"tags"`
+	Version      int32              `json:"version"`
+	Latitude     pgtype.Float8      `json:"latitude"`
+	Longitude    pgtype.Float8      `json:"longitude"`
+	PlaceName    pgtype.Text        `json:"place_name"`
+	CountryCode  pgtype.Text        `json:"country_code"`
+	CountryName  pgtype.Text        `json:"country_name"`
+	TakenAt      pgtype.Timestamptz `json:"taken_at"`
+	CustomFields []byte             `json:"custom_fields"`
+}
+
+type PhotoVersion struct {
+	ID            uuid.UUID          `json:"id"`
+	PhotoID       uuid.UUID          `json:"photo_id"`
+	VersionNumber int32              `json:"version_number"`
+	FileName      string             `json:"file_name"`
+	FileSize      int64              `json:"file_size"`
+	ContentType   string             `json:"content_type"`
+	StoragePath   string             `json:"storage_path"`
+	PublicUrl     pgtype.Text        `json:"public_url"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+type RecoveryCode struct {
+	ID        uuid.UUID          `json:"id"`
+	UserID    uuid.UUID          `json:"user_id"`
+	CodeHash  string             `json:"code_hash"`
+	UsedAt    pgtype.Timestamptz `json:"used_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type ShareLink struct {
+	ID            uuid.UUID          `json:"id"`
+	Token         string             `json:"token"`
+	PhotoID       uuid.UUID          `json:"photo_id"`
+	UserID        uuid.UUID          `json:"user_id"`
+	PasswordHash  pgtype.Text        `json:"password_hash"`
+	ExpiresAt     pgtype.Timestamptz `json:"expires_at"`
+	MaxViews      pgtype.Int4        `json:"max_views"`
+	ViewCount     int32              `json:"view_count"`
+	AllowDownload bool               `json:"allow_download"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+type User struct {
+	ID                  uuid.UUID          `json:"id"`
+	Username            string             `json:"username"`
+	Email               string             `json:"email"`
+	PasswordHash        string             `json:"password_hash"`
+	CreatedAt           pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt           pgtype.Timestamptz `json:"updated_at"`
+	TimeZone            string             `json:"time_zone"`
+	EmailVerifiedAt     pgtype.Timestamptz `json:"email_verified_at"`
+	DeletionScheduledAt pgtype.Timestamptz `json:"deletion_scheduled_at"`
+	ErasureStartedAt    pgtype.Timestamptz `json:"erasure_started_at"`
+	TotpSecret          pgtype.Text        `json:"totp_secret"`
+	TotpEnabledAt       pgtype.Timestamptz `json:"totp_enabled_at"`
 }
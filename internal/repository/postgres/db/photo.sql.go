@@ -12,6 +12,217 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addPhotoTags = `-- name: AddPhotoTags :one
+UPDATE photos
+SET tags = ARRAY(
+        SELECT DISTINCT tag FROM unnest(tags || $1::text[]) AS tag
+        ORDER BY tag
+    ),
+    updated_at = $2,
+    version = version + 1
+WHERE id = $3
+RETURNING id, user_id, title, description, file_name, file_size, content_type, storage_path, public_url, created_at, updated_at, visibility, album_id, tags, version, latitude, longitude, place_name, country_code, country_name, taken_at, custom_fields
+`
+
+type AddPhotoTagsParams struct {
+	Tags      []string           `json:"tags"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	ID        uuid.UUID          `json:"id"`
+}
+
+func (q *Queries) AddPhotoTags(ctx context.Context, arg AddPhotoTagsParams) (Photo, error) {
+	row := q.db.QueryRow(ctx, addPhotoTags, arg.Tags, arg.UpdatedAt, arg.ID)
+	var i Photo
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Title,
+		&i.Description,
+		&i.FileName,
+		&i.FileSize,
+		&i.ContentType,
+		&i.StoragePath,
+		&i.PublicUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Visibility,
+		&i.AlbumID,
+		&i.Tags,
+		&i.Version,
+		&i.Latitude,
+		&i.Longitude,
+		&i.PlaceName,
+		&i.CountryCode,
+		&i.CountryName,
+		&i.TakenAt,
+		&i.CustomFields,
+	)
+	return i, err
+}
+
+const clusterPhotosInBoundingBox = `-- name: ClusterPhotosInBoundingBox :many
+SELECT
+    floor(longitude / $1::float8)::bigint AS cell_x,
+    floor(latitude / $1::float8)::bigint AS cell_y,
+    COUNT(*) AS count,
+    AVG(latitude)::float8 AS latitude,
+    AVG(longitude)::float8 AS longitude,
+    (array_agg(id ORDER BY created_at DESC))[1]::uuid AS photo_id
+FROM photos
+WHERE user_id = $2
+  AND latitude BETWEEN $3::float8 AND $4::float8
+  AND CASE
+        WHEN $5::float8 <= $6::float8
+            THEN longitude BETWEEN $5::float8 AND $6::float8
+        ELSE longitude >= $5::float8 OR longitude <= $6::float8
+      END
+GROUP BY cell_x, cell_y
+`
+
+type ClusterPhotosInBoundingBoxParams struct {
+	CellSize float64   `json:"cell_size"`
+	UserID   uuid.UUID `json:"user_id"`
+	MinLat   float64   `json:"min_lat"`
+	MaxLat   float64   `json:"max_lat"`
+	MinLng   float64   `json:"min_lng"`
+	MaxLng   float64   `json:"max_lng"`
+}
+
+type ClusterPhotosInBoundingBoxRow struct {
+	CellX     int64     `json:"cell_x"`
+	CellY     int64     `json:"cell_y"`
+	Count     int64     `json:"count"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	PhotoID   uuid.UUID `json:"photo_id"`
+}
+
+func (q *Queries) ClusterPhotosInBoundingBox(ctx context.Context, arg ClusterPhotosInBoundingBoxParams) ([]ClusterPhotosInBoundingBoxRow, error) {
+	rows, err := q.db.Query(ctx, clusterPhotosInBoundingBox,
+		arg.CellSize,
+		arg.UserID,
+		arg.MinLat,
+		arg.MaxLat,
+		arg.MinLng,
+		arg.MaxLng,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ClusterPhotosInBoundingBoxRow{}
+	for rows.Next() {
+		var i ClusterPhotosInBoundingBoxRow
+		if err := rows.Scan(
+			&i.CellX,
+			&i.CellY,
+			&i.Count,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PhotoID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countPhotosByAlbumID = `-- name: CountPhotosByAlbumID :one
+SELECT COUNT(*) FROM photos
+WHERE album_id = $1
+`
+
+func (q *Queries) CountPhotosByAlbumID(ctx context.Context, albumID *uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countPhotosByAlbumID, albumID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countPhotosByCustomFields = `-- name: CountPhotosByCustomFields :one
+SELECT COUNT(*) FROM photos
+WHERE user_id = $1
+  AND custom_fields @> $2::jsonb
+`
+
+type CountPhotosByCustomFieldsParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	Filter []byte    `json:"filter"`
+}
+
+func (q *Queries) CountPhotosByCustomFields(ctx context.Context, arg CountPhotosByCustomFieldsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countPhotosByCustomFields, arg.UserID, arg.Filter)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countPhotosByDateBucket = `-- name: CountPhotosByDateBucket :many
+SELECT
+    date_trunc($1::text, COALESCE(taken_at, created_at), $2::text)::timestamptz AS bucket,
+    COUNT(*) AS count
+FROM photos
+WHERE user_id = $3
+GROUP BY bucket
+ORDER BY bucket DESC
+`
+
+type CountPhotosByDateBucketParams struct {
+	Granularity string    `json:"granularity"`
+	TimeZone    string    `json:"time_zone"`
+	UserID      uuid.UUID `json:"user_id"`
+}
+
+type CountPhotosByDateBucketRow struct {
+	Bucket pgtype.Timestamptz `json:"bucket"`
+	Count  int64              `json:"count"`
+}
+
+func (q *Queries) CountPhotosByDateBucket(ctx context.Context, arg CountPhotosByDateBucketParams) ([]CountPhotosByDateBucketRow, error) {
+	rows, err := q.db.Query(ctx, countPhotosByDateBucket, arg.Granularity, arg.TimeZone, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CountPhotosByDateBucketRow{}
+	for rows.Next() {
+		var i CountPhotosByDateBucketRow
+		if err := rows.Scan(
+			&i.Bucket,
+			&i.Count,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countPhotosByPlace = `-- name: CountPhotosByPlace :one
+SELECT COUNT(*) FROM photos
+WHERE user_id = $1
+  AND (place_name ILIKE $2::text OR country_name ILIKE $2::text)
+`
+
+type CountPhotosByPlaceParams struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Pattern string    `json:"pattern"`
+}
+
+func (q *Queries) CountPhotosByPlace(ctx context.Context, arg CountPhotosByPlaceParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countPhotosByPlace, arg.UserID, arg.Pattern)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countPhotosByUserID = `-- name: CountPhotosByUserID :one
 SELECT COUNT(*) FROM photos
 WHERE user_id = $1
@@ -24,24 +235,80 @@ func (q *Queries) CountPhotosByUserID(ctx context.Context, userID uuid.UUID) (in
 	return count, err
 }
 
+const countPhotosSharedWithUser = `-- name: CountPhotosSharedWithUser :one
+SELECT COUNT(*) FROM photos
+WHERE id IN (
+    SELECT photo_id FROM access_grants
+    WHERE grantee_id = $1 AND photo_id IS NOT NULL
+) OR album_id IN (
+    SELECT album_id FROM access_grants
+    WHERE grantee_id = $1 AND album_id IS NOT NULL
+)
+`
+
+func (q *Queries) CountPhotosSharedWithUser(ctx context.Context, granteeID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countPhotosSharedWithUser, granteeID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countPhotosTakenSince = `-- name: CountPhotosTakenSince :one
+SELECT COUNT(*) FROM photos
+WHERE user_id = $1
+  AND COALESCE(taken_at, created_at) >= $2::timestamptz
+`
+
+type CountPhotosTakenSinceParams struct {
+	UserID uuid.UUID          `json:"user_id"`
+	Since  pgtype.Timestamptz `json:"since"`
+}
+
+func (q *Queries) CountPhotosTakenSince(ctx context.Context, arg CountPhotosTakenSinceParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countPhotosTakenSince, arg.UserID, arg.Since)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countPublicPhotosByUserID = `-- name: CountPublicPhotosByUserID :one
+SELECT COUNT(*) FROM photos
+WHERE user_id = $1 AND visibility = 'public'
+`
+
+func (q *Queries) CountPublicPhotosByUserID(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countPublicPhotosByUserID, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createPhoto = `-- name: CreatePhoto :one
-INSERT INTO photos (id, user_id, title, description, file_name, file_size, content_type, storage_path, public_URL, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-RETURNING id, user_id, title, description, file_name, file_size, content_type, storage_path, public_url, created_at, updated_at
+INSERT INTO photos (id, user_id, title, description, file_name, file_size, content_type, storage_path, public_URL, visibility, latitude, longitude, place_name, country_code, country_name, taken_at, custom_fields, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+RETURNING id, user_id, title, description, file_name, file_size, content_type, storage_path, public_url, created_at, updated_at, visibility, album_id, tags, version, latitude, longitude, place_name, country_code, country_name, taken_at, custom_fields
 `
 
 type CreatePhotoParams struct {
-	ID          uuid.UUID          `json:"id"`
-	UserID      uuid.UUID          `json:"user_id"`
-	Title       string             `json:"title"`
-	Description pgtype.Text        `json:"description"`
-	FileName    string             `json:"file_name"`
-	FileSize    int64              `json:"file_size"`
-	ContentType string             `json:"content_type"`
-	StoragePath string             `json:"storage_path"`
-	PublicUrl   pgtype.Text        `json:"public_url"`
-	CreatedAt   pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	ID           uuid.UUID          `json:"id"`
+	UserID       uuid.UUID          `json:"user_id"`
+	Title        string             `json:"title"`
+	Description  pgtype.Text        `json:"description"`
+	FileName     string             `json:"file_name"`
+	FileSize     int64              `json:"file_size"`
+	ContentType  string             `json:"content_type"`
+	StoragePath  string             `json:"storage_path"`
+	PublicUrl    pgtype.Text        `json:"public_url"`
+	Visibility   string             `json:"visibility"`
+	Latitude     pgtype.Float8      `json:"latitude"`
+	Longitude    pgtype.Float8      `json:"longitude"`
+	PlaceName    pgtype.Text        `json:"place_name"`
+	CountryCode  pgtype.Text        `json:"country_code"`
+	CountryName  pgtype.Text        `json:"country_name"`
+	TakenAt      pgtype.Timestamptz `json:"taken_at"`
+	CustomFields []byte             `json:"custom_fields"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
 }
 
 func (q *Queries) CreatePhoto(ctx context.Context, arg CreatePhotoParams) (Photo, error) {
@@ -55,6 +322,14 @@ func (q *Queries) CreatePhoto(ctx context.Context, arg CreatePhotoParams) (Photo
 		arg.ContentType,
 		arg.StoragePath,
 		arg.PublicUrl,
+		arg.Visibility,
+		arg.Latitude,
+		arg.Longitude,
+		arg.PlaceName,
+		arg.CountryCode,
+		arg.CountryName,
+		arg.TakenAt,
+		arg.CustomFields,
 		arg.CreatedAt,
 		arg.UpdatedAt,
 	)
@@ -71,6 +346,17 @@ func (q *Queries) CreatePhoto(ctx context.Context, arg CreatePhotoParams) (Photo
 		&i.PublicUrl,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Visibility,
+		&i.AlbumID,
+		&i.Tags,
+		&i.Version,
+		&i.Latitude,
+		&i.Longitude,
+		&i.PlaceName,
+		&i.CountryCode,
+		&i.CountryName,
+		&i.TakenAt,
+		&i.CustomFields,
 	)
 	return i, err
 }
@@ -86,7 +372,7 @@ func (q *Queries) DeletePhoto(ctx context.Context, id uuid.UUID) error {
 }
 
 const getPhotoByID = `-- name: GetPhotoByID :one
-SELECT id, user_id, title, description, file_name, file_size, content_type, storage_path, public_url, created_at, updated_at FROM photos
+SELECT id, user_id, title, description, file_name, file_size, content_type, storage_path, public_url, created_at, updated_at, visibility, album_id, tags, version, latitude, longitude, place_name, country_code, country_name, taken_at, custom_fields FROM photos
 WHERE id = $1
 LIMIT 1
 `
@@ -106,25 +392,54 @@ func (q *Queries) GetPhotoByID(ctx context.Context, id uuid.UUID) (Photo, error)
 		&i.PublicUrl,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Visibility,
+		&i.AlbumID,
+		&i.Tags,
+		&i.Version,
+		&i.Latitude,
+		&i.Longitude,
+		&i.PlaceName,
+		&i.CountryCode,
+		&i.CountryName,
+		&i.TakenAt,
+		&i.CustomFields,
 	)
 	return i, err
 }
 
-const listPhotosByUserID = `-- name: ListPhotosByUserID :many
-SELECT id, user_id, title, description, file_name, file_size, content_type, storage_path, public_url, created_at, updated_at FROM photos
-WHERE user_id = $1
+const getStorageUsageByUserID = `-- name: GetStorageUsageByUserID :one
+SELECT COALESCE(SUM(file_size), 0)::bigint AS storage_used
+FROM (
+    SELECT file_size FROM photos WHERE user_id = $1
+    UNION ALL
+    SELECT v.file_size FROM photo_versions v
+    JOIN photos p ON p.id = v.photo_id
+    WHERE p.user_id = $1
+) AS files
+`
+
+func (q *Queries) GetStorageUsageByUserID(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, getStorageUsageByUserID, userID)
+	var storageUsed int64
+	err := row.Scan(&storageUsed)
+	return storageUsed, err
+}
+
+const listPhotosByAlbumID = `-- name: ListPhotosByAlbumID :many
+SELECT id, user_id, title, description, file_name, file_size, content_type, storage_path, public_url, created_at, updated_at, visibility, album_id, tags, version, latitude, longitude, place_name, country_code, country_name, taken_at, custom_fields FROM photos
+WHERE album_id = $1
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
 `
 
-type ListPhotosByUserIDParams struct {
-	UserID uuid.UUID `json:"user_id"`
-	Limit  int32     `json:"limit"`
-	Offset int32     `json:"offset"`
+type ListPhotosByAlbumIDParams struct {
+	AlbumID *uuid.UUID `json:"album_id"`
+	Limit   int32      `json:"limit"`
+	Offset  int32      `json:"offset"`
 }
 
-func (q *Queries) ListPhotosByUserID(ctx context.Context, arg ListPhotosByUserIDParams) ([]Photo, error) {
-	rows, err := q.db.Query(ctx, listPhotosByUserID, arg.UserID, arg.Limit, arg.Offset)
+func (q *Queries) ListPhotosByAlbumID(ctx context.Context, arg ListPhotosByAlbumIDParams) ([]Photo, error) {
+	rows, err := q.db.Query(ctx, listPhotosByAlbumID, arg.AlbumID, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -144,6 +459,17 @@ func (q *Queries) ListPhotosByUserID(ctx context.Context, arg ListPhotosByUserID
 			&i.PublicUrl,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Visibility,
+			&i.AlbumID,
+			&i.Tags,
+			&i.Version,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.CountryCode,
+			&i.CountryName,
+			&i.TakenAt,
+			&i.CustomFields,
 		); err != nil {
 			return nil, err
 		}
@@ -155,68 +481,903 @@ func (q *Queries) ListPhotosByUserID(ctx context.Context, arg ListPhotosByUserID
 	return items, nil
 }
 
-const updatePhoto = `-- name: UpdatePhoto :one
-UPDATE photos
-SET title = $2,
-    description = $3,
-    updated_at = $4
-WHERE id = $1
-RETURNING id, user_id, title, description, file_name, file_size, content_type, storage_path, public_url, created_at, updated_at
+const listPhotosByCustomFields = `-- name: ListPhotosByCustomFields :many
+SELECT id, user_id, title, description, file_name, file_size, content_type, storage_path, public_url, created_at, updated_at, visibility, album_id, tags, version, latitude, longitude, place_name, country_code, country_name, taken_at, custom_fields FROM photos
+WHERE user_id = $1
+  AND custom_fields @> $2::jsonb
+ORDER BY COALESCE(taken_at, created_at) DESC, id DESC
+LIMIT $3 OFFSET $4
 `
 
-type UpdatePhotoParams struct {
-	ID          uuid.UUID          `json:"id"`
-	Title       string             `json:"title"`
-	Description pgtype.Text        `json:"description"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+type ListPhotosByCustomFieldsParams struct {
+	UserID     uuid.UUID `json:"user_id"`
+	Filter     []byte    `json:"filter"`
+	PageSize   int32     `json:"page_size"`
+	PageOffset int32     `json:"page_offset"`
 }
 
-func (q *Queries) UpdatePhoto(ctx context.Context, arg UpdatePhotoParams) (Photo, error) {
-	row := q.db.QueryRow(ctx, updatePhoto,
-		arg.ID,
-		arg.Title,
-		arg.Description,
-		arg.UpdatedAt,
-	)
-	var i Photo
-	err := row.Scan(
-		&i.ID,
-		&i.UserID,
-		&i.Title,
-		&i.Description,
-		&i.FileName,
-		&i.FileSize,
-		&i.ContentType,
-		&i.StoragePath,
-		&i.PublicUrl,
-		&i.CreatedAt,
-		&i.UpdatedAt,
+func (q *Queries) ListPhotosByCustomFields(ctx context.Context, arg ListPhotosByCustomFieldsParams) ([]Photo, error) {
+	rows, err := q.db.Query(ctx, listPhotosByCustomFields,
+		arg.UserID,
+		arg.Filter,
+		arg.PageSize,
+		arg.PageOffset,
 	)
-	return i, err
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Photo{}
+	for rows.Next() {
+		var i Photo
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Description,
+			&i.FileName,
+			&i.FileSize,
+			&i.ContentType,
+			&i.StoragePath,
+			&i.PublicUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Visibility,
+			&i.AlbumID,
+			&i.Tags,
+			&i.Version,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.CountryCode,
+			&i.CountryName,
+			&i.TakenAt,
+			&i.CustomFields,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const updatePhotoStorageInfo = `-- name: UpdatePhotoStorageInfo :one
-UPDATE photos
-SET storage_path = $2,
-    public_URL = $3,
-    updated_at = $4
-WHERE id = $1
-RETURNING id, user_id, title, description, file_name, file_size, content_type, storage_path, public_url, created_at, updated_at
+const listPhotosByPlace = `-- name: ListPhotosByPlace :many
+SELECT id, user_id, title, description, file_name, file_size, content_type, storage_path, public_url, created_at, updated_at, visibility, album_id, tags, version, latitude, longitude, place_name, country_code, country_name, taken_at, custom_fields FROM photos
+WHERE user_id = $1
+  AND (place_name ILIKE $2::text OR country_name ILIKE $2::text)
+ORDER BY created_at DESC
+LIMIT $3 OFFSET $4
 `
 
-type UpdatePhotoStorageInfoParams struct {
-	ID          uuid.UUID          `json:"id"`
-	StoragePath string             `json:"storage_path"`
-	PublicUrl   pgtype.Text        `json:"public_url"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+type ListPhotosByPlaceParams struct {
+	UserID     uuid.UUID `json:"user_id"`
+	Pattern    string    `json:"pattern"`
+	PageSize   int32     `json:"page_size"`
+	PageOffset int32     `json:"page_offset"`
 }
 
-func (q *Queries) UpdatePhotoStorageInfo(ctx context.Context, arg UpdatePhotoStorageInfoParams) (Photo, error) {
-	row := q.db.QueryRow(ctx, updatePhotoStorageInfo,
-		arg.ID,
+func (q *Queries) ListPhotosByPlace(ctx context.Context, arg ListPhotosByPlaceParams) ([]Photo, error) {
+	rows, err := q.db.Query(ctx, listPhotosByPlace,
+		arg.UserID,
+		arg.Pattern,
+		arg.PageSize,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Photo{}
+	for rows.Next() {
+		var i Photo
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Description,
+			&i.FileName,
+			&i.FileSize,
+			&i.ContentType,
+			&i.StoragePath,
+			&i.PublicUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Visibility,
+			&i.AlbumID,
+			&i.Tags,
+			&i.Version,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.CountryCode,
+			&i.CountryName,
+			&i.TakenAt,
+			&i.CustomFields,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPhotosByUserID = `-- name: ListPhotosByUserID :many
+SELECT id, user_id, title, description, file_name, file_size, content_type, storage_path, public_url, created_at, updated_at, visibility, album_id, tags, version, latitude, longitude, place_name, country_code, country_name, taken_at, custom_fields FROM photos
+WHERE user_id = $1
+ORDER BY COALESCE(taken_at, created_at) DESC, id DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListPhotosByUserIDParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	Limit  int32     `json:"limit"`
+	Offset int32     `json:"offset"`
+}
+
+func (q *Queries) ListPhotosByUserID(ctx context.Context, arg ListPhotosByUserIDParams) ([]Photo, error) {
+	rows, err := q.db.Query(ctx, listPhotosByUserID, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Photo{}
+	for rows.Next() {
+		var i Photo
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Description,
+			&i.FileName,
+			&i.FileSize,
+			&i.ContentType,
+			&i.StoragePath,
+			&i.PublicUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Visibility,
+			&i.AlbumID,
+			&i.Tags,
+			&i.Version,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.CountryCode,
+			&i.CountryName,
+			&i.TakenAt,
+			&i.CustomFields,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPhotosInBoundingBox = `-- name: ListPhotosInBoundingBox :many
+SELECT id, user_id, title, description, file_name, file_size, content_type, storage_path, public_url, created_at, updated_at, visibility, album_id, tags, version, latitude, longitude, place_name, country_code, country_name, taken_at, custom_fields FROM photos
+WHERE user_id = $1
+  AND latitude BETWEEN $2::float8 AND $3::float8
+  AND CASE
+        WHEN $4::float8 <= $5::float8
+            THEN longitude BETWEEN $4::float8 AND $5::float8
+        ELSE longitude >= $4::float8 OR longitude <= $5::float8
+      END
+ORDER BY created_at DESC
+LIMIT $6
+`
+
+type ListPhotosInBoundingBoxParams struct {
+	UserID   uuid.UUID `json:"user_id"`
+	MinLat   float64   `json:"min_lat"`
+	MaxLat   float64   `json:"max_lat"`
+	MinLng   float64   `json:"min_lng"`
+	MaxLng   float64   `json:"max_lng"`
+	PageSize int32     `json:"page_size"`
+}
+
+func (q *Queries) ListPhotosInBoundingBox(ctx context.Context, arg ListPhotosInBoundingBoxParams) ([]Photo, error) {
+	rows, err := q.db.Query(ctx, listPhotosInBoundingBox,
+		arg.UserID,
+		arg.MinLat,
+		arg.MaxLat,
+		arg.MinLng,
+		arg.MaxLng,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Photo{}
+	for rows.Next() {
+		var i Photo
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Description,
+			&i.FileName,
+			&i.FileSize,
+			&i.ContentType,
+			&i.StoragePath,
+			&i.PublicUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Visibility,
+			&i.AlbumID,
+			&i.Tags,
+			&i.Version,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.CountryCode,
+			&i.CountryName,
+			&i.TakenAt,
+			&i.CustomFields,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPhotosMissingPlace = `-- name: ListPhotosMissingPlace :many
+SELECT id, user_id, title, description, file_name, file_size, content_type, storage_path, public_url, created_at, updated_at, visibility, album_id, tags, version, latitude, longitude, place_name, country_code, country_name, taken_at, custom_fields FROM photos
+WHERE latitude IS NOT NULL
+  AND place_name IS NULL
+  AND id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListPhotosMissingPlaceParams struct {
+	AfterID  uuid.UUID `json:"after_id"`
+	PageSize int32     `json:"page_size"`
+}
+
+func (q *Queries) ListPhotosMissingPlace(ctx context.Context, arg ListPhotosMissingPlaceParams) ([]Photo, error) {
+	rows, err := q.db.Query(ctx, listPhotosMissingPlace, arg.AfterID, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Photo{}
+	for rows.Next() {
+		var i Photo
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Description,
+			&i.FileName,
+			&i.FileSize,
+			&i.ContentType,
+			&i.StoragePath,
+			&i.PublicUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Visibility,
+			&i.AlbumID,
+			&i.Tags,
+			&i.Version,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.CountryCode,
+			&i.CountryName,
+			&i.TakenAt,
+			&i.CustomFields,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPhotosNear = `-- name: ListPhotosNear :many
+SELECT id, user_id, title, description, file_name, file_size, content_type, storage_path, public_url, created_at, updated_at, visibility, album_id, tags, version, latitude, longitude, place_name, country_code, country_name, taken_at, custom_fields FROM photos
+WHERE user_id = $1
+  AND latitude BETWEEN $2::float8 AND $3::float8
+  AND CASE
+        WHEN $4::float8 <= $5::float8
+            THEN longitude BETWEEN $4::float8 AND $5::float8
+        ELSE longitude >= $4::float8 OR longitude <= $5::float8
+      END
+  AND 12742000 * asin(sqrt(
+        power(sin(radians(latitude - $6::float8) / 2), 2)
+        + cos(radians($6::float8)) * cos(radians(latitude))
+        * power(sin(radians(longitude - $7::float8) / 2), 2)
+      )) <= $8::float8
+ORDER BY power(sin(radians(latitude - $6::float8) / 2), 2)
+        + cos(radians($6::float8)) * cos(radians(latitude))
+        * power(sin(radians(longitude - $7::float8) / 2), 2)
+LIMIT $9
+`
+
+type ListPhotosNearParams struct {
+	UserID       uuid.UUID `json:"user_id"`
+	MinLat       float64   `json:"min_lat"`
+	MaxLat       float64   `json:"max_lat"`
+	MinLng       float64   `json:"min_lng"`
+	MaxLng       float64   `json:"max_lng"`
+	Lat          float64   `json:"lat"`
+	Lng          float64   `json:"lng"`
+	RadiusMeters float64   `json:"radius_meters"`
+	PageSize     int32     `json:"page_size"`
+}
+
+func (q *Queries) ListPhotosNear(ctx context.Context, arg ListPhotosNearParams) ([]Photo, error) {
+	rows, err := q.db.Query(ctx, listPhotosNear,
+		arg.UserID,
+		arg.MinLat,
+		arg.MaxLat,
+		arg.MinLng,
+		arg.MaxLng,
+		arg.Lat,
+		arg.Lng,
+		arg.RadiusMeters,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Photo{}
+	for rows.Next() {
+		var i Photo
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Description,
+			&i.FileName,
+			&i.FileSize,
+			&i.ContentType,
+			&i.StoragePath,
+			&i.PublicUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Visibility,
+			&i.AlbumID,
+			&i.Tags,
+			&i.Version,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.CountryCode,
+			&i.CountryName,
+			&i.TakenAt,
+			&i.CustomFields,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPhotosSharedWithUser = `-- name: ListPhotosSharedWithUser :many
+SELECT id, user_id, title, description, file_name, file_size, content_type, storage_path, public_url, created_at, updated_at, visibility, album_id, tags, version, latitude, longitude, place_name, country_code, country_name, taken_at, custom_fields FROM photos
+WHERE id IN (
+    SELECT photo_id FROM access_grants
+    WHERE grantee_id = $1 AND photo_id IS NOT NULL
+) OR album_id IN (
+    SELECT album_id FROM access_grants
+    WHERE grantee_id = $1 AND album_id IS NOT NULL
+)
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListPhotosSharedWithUserParams struct {
+	GranteeID  uuid.UUID `json:"grantee_id"`
+	PageSize   int32     `json:"page_size"`
+	PageOffset int32     `json:"page_offset"`
+}
+
+func (q *Queries) ListPhotosSharedWithUser(ctx context.Context, arg ListPhotosSharedWithUserParams) ([]Photo, error) {
+	rows, err := q.db.Query(ctx, listPhotosSharedWithUser, arg.GranteeID, arg.PageSize, arg.PageOffset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Photo{}
+	for rows.Next() {
+		var i Photo
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Description,
+			&i.FileName,
+			&i.FileSize,
+			&i.ContentType,
+			&i.StoragePath,
+			&i.PublicUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Visibility,
+			&i.AlbumID,
+			&i.Tags,
+			&i.Version,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.CountryCode,
+			&i.CountryName,
+			&i.TakenAt,
+			&i.CustomFields,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPlacesByUserID = `-- name: ListPlacesByUserID :many
+SELECT
+    place_name::text AS place_name,
+    country_code::text AS country_code,
+    country_name::text AS country_name,
+    COUNT(*) AS photo_count,
+    (array_agg(id ORDER BY created_at DESC))[1:1]::uuid[] AS photo_ids
+FROM photos
+WHERE user_id = $1
+  AND place_name IS NOT NULL
+  AND (place_name ILIKE $2::text OR country_name ILIKE $2::text)
+GROUP BY place_name, country_code, country_name
+ORDER BY photo_count DESC, place_name
+LIMIT $3
+`
+
+type ListPlacesByUserIDParams struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Pattern  string    `json:"pattern"`
+	PageSize int32     `json:"page_size"`
+}
+
+type ListPlacesByUserIDRow struct {
+	PlaceName   string      `json:"place_name"`
+	CountryCode string      `json:"country_code"`
+	CountryName string      `json:"country_name"`
+	PhotoCount  int64       `json:"photo_count"`
+	PhotoIds    []uuid.UUID `json:"photo_ids"`
+}
+
+func (q *Queries) ListPlacesByUserID(ctx context.Context, arg ListPlacesByUserIDParams) ([]ListPlacesByUserIDRow, error) {
+	rows, err := q.db.Query(ctx, listPlacesByUserID, arg.UserID, arg.Pattern, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListPlacesByUserIDRow{}
+	for rows.Next() {
+		var i ListPlacesByUserIDRow
+		if err := rows.Scan(
+			&i.PlaceName,
+			&i.CountryCode,
+			&i.CountryName,
+			&i.PhotoCount,
+			&i.PhotoIds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPublicPhotos = `-- name: ListPublicPhotos :many
+SELECT id, user_id, title, description, file_name, file_size, content_type, storage_path, public_url, created_at, updated_at, visibility, album_id, tags, version, latitude, longitude, place_name, country_code, country_name, taken_at, custom_fields FROM photos
+WHERE visibility = 'public'
+  AND ($1::timestamptz IS NULL
+       OR (created_at, id) < ($1::timestamptz, $2::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT $3
+`
+
+type ListPublicPhotosParams struct {
+	CursorCreatedAt pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorID        *uuid.UUID         `json:"cursor_id"`
+	PageSize        int32              `json:"page_size"`
+}
+
+func (q *Queries) ListPublicPhotos(ctx context.Context, arg ListPublicPhotosParams) ([]Photo, error) {
+	rows, err := q.db.Query(ctx, listPublicPhotos, arg.CursorCreatedAt, arg.CursorID, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Photo{}
+	for rows.Next() {
+		var i Photo
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Description,
+			&i.FileName,
+			&i.FileSize,
+			&i.ContentType,
+			&i.StoragePath,
+			&i.PublicUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Visibility,
+			&i.AlbumID,
+			&i.Tags,
+			&i.Version,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.CountryCode,
+			&i.CountryName,
+			&i.TakenAt,
+			&i.CustomFields,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPublicPhotosByUserID = `-- name: ListPublicPhotosByUserID :many
+SELECT id, user_id, title, description, file_name, file_size, content_type, storage_path, public_url, created_at, updated_at, visibility, album_id, tags, version, latitude, longitude, place_name, country_code, country_name, taken_at, custom_fields FROM photos
+WHERE user_id = $1 AND visibility = 'public'
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListPublicPhotosByUserIDParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	Limit  int32     `json:"limit"`
+	Offset int32     `json:"offset"`
+}
+
+func (q *Queries) ListPublicPhotosByUserID(ctx context.Context, arg ListPublicPhotosByUserIDParams) ([]Photo, error) {
+	rows, err := q.db.Query(ctx, listPublicPhotosByUserID, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Photo{}
+	for rows.Next() {
+		var i Photo
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Description,
+			&i.FileName,
+			&i.FileSize,
+			&i.ContentType,
+			&i.StoragePath,
+			&i.PublicUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Visibility,
+			&i.AlbumID,
+			&i.Tags,
+			&i.Version,
+			&i.Latitude,
+			&i.Longitude,
+			&i.PlaceName,
+			&i.CountryCode,
+			&i.CountryName,
+			&i.TakenAt,
+			&i.CustomFields,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnalbumedPlacesByUserID = `-- name: ListUnalbumedPlacesByUserID :many
+SELECT
+    place_name::text AS place_name,
+    country_code::text AS country_code,
+    country_name::text AS country_name,
+    COUNT(*) AS photo_count,
+    (array_agg(id ORDER BY created_at DESC))[1:100]::uuid[] AS photo_ids
+FROM photos
+WHERE user_id = $1
+  AND place_name IS NOT NULL
+  AND album_id IS NULL
+GROUP BY place_name, country_code, country_name
+HAVING COUNT(*) >= $2::bigint
+ORDER BY photo_count DESC, place_name
+LIMIT $3
+`
+
+type ListUnalbumedPlacesByUserIDParams struct {
+	UserID    uuid.UUID `json:"user_id"`
+	MinPhotos int64     `json:"min_photos"`
+	PageSize  int32     `json:"page_size"`
+}
+
+type ListUnalbumedPlacesByUserIDRow struct {
+	PlaceName   string      `json:"place_name"`
+	CountryCode string      `json:"country_code"`
+	CountryName string      `json:"country_name"`
+	PhotoCount  int64       `json:"photo_count"`
+	PhotoIds    []uuid.UUID `json:"photo_ids"`
+}
+
+func (q *Queries) ListUnalbumedPlacesByUserID(ctx context.Context, arg ListUnalbumedPlacesByUserIDParams) ([]ListUnalbumedPlacesByUserIDRow, error) {
+	rows, err := q.db.Query(ctx, listUnalbumedPlacesByUserID, arg.UserID, arg.MinPhotos, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUnalbumedPlacesByUserIDRow{}
+	for rows.Next() {
+		var i ListUnalbumedPlacesByUserIDRow
+		if err := rows.Scan(
+			&i.PlaceName,
+			&i.CountryCode,
+			&i.CountryName,
+			&i.PhotoCount,
+			&i.PhotoIds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updatePhoto = `-- name: UpdatePhoto :one
+UPDATE photos
+SET title = $2,
+    description = $3,
+    visibility = $4,
+    latitude = $5,
+    longitude = $6,
+    place_name = $7,
+    country_code = $8,
+    country_name = $9,
+    taken_at = $10,
+    custom_fields = $11,
+    updated_at = $12,
+    version = version + 1
+WHERE id = $1 AND version = $13
+RETURNING id, user_id, title, description, file_name, file_size, content_type, storage_path, public_url, created_at, updated_at, visibility, album_id, tags, version, latitude, longitude, place_name, country_code, country_name, taken_at, custom_fields
+`
+
+type UpdatePhotoParams struct {
+	ID           uuid.UUID          `json:"id"`
+	Title        string             `json:"title"`
+	Description  pgtype.Text        `json:"description"`
+	Visibility   string             `json:"visibility"`
+	Latitude     pgtype.Float8      `json:"latitude"`
+	Longitude    pgtype.Float8      `json:"longitude"`
+	PlaceName    pgtype.Text        `json:"place_name"`
+	CountryCode  pgtype.Text        `json:"country_code"`
+	CountryName  pgtype.Text        `json:"country_name"`
+	TakenAt      pgtype.Timestamptz `json:"taken_at"`
+	CustomFields []byte             `json:"custom_fields"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	Version      int32              `json:"version"`
+}
+
+func (q *Queries) UpdatePhoto(ctx context.Context, arg UpdatePhotoParams) (Photo, error) {
+	row := q.db.QueryRow(ctx, updatePhoto,
+		arg.ID,
+		arg.Title,
+		arg.Description,
+		arg.Visibility,
+		arg.Latitude,
+		arg.Longitude,
+		arg.PlaceName,
+		arg.CountryCode,
+		arg.CountryName,
+		arg.TakenAt,
+		arg.CustomFields,
+		arg.UpdatedAt,
+		arg.Version,
+	)
+	var i Photo
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Title,
+		&i.Description,
+		&i.FileName,
+		&i.FileSize,
+		&i.ContentType,
+		&i.StoragePath,
+		&i.PublicUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Visibility,
+		&i.AlbumID,
+		&i.Tags,
+		&i.Version,
+		&i.Latitude,
+		&i.Longitude,
+		&i.PlaceName,
+		&i.CountryCode,
+		&i.CountryName,
+		&i.TakenAt,
+		&i.CustomFields,
+	)
+	return i, err
+}
+
+const updatePhotoAlbum = `-- name: UpdatePhotoAlbum :one
+UPDATE photos
+SET album_id = $2,
+    updated_at = $3,
+    version = version + 1
+WHERE id = $1
+RETURNING id, user_id, title, description, file_name, file_size, content_type, storage_path, public_url, created_at, updated_at, visibility, album_id, tags, version, latitude, longitude, place_name, country_code, country_name, taken_at, custom_fields
+`
+
+type UpdatePhotoAlbumParams struct {
+	ID        uuid.UUID          `json:"id"`
+	AlbumID   *uuid.UUID         `json:"album_id"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+func (q *Queries) UpdatePhotoAlbum(ctx context.Context, arg UpdatePhotoAlbumParams) (Photo, error) {
+	row := q.db.QueryRow(ctx, updatePhotoAlbum, arg.ID, arg.AlbumID, arg.UpdatedAt)
+	var i Photo
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Title,
+		&i.Description,
+		&i.FileName,
+		&i.FileSize,
+		&i.ContentType,
+		&i.StoragePath,
+		&i.PublicUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Visibility,
+		&i.AlbumID,
+		&i.Tags,
+		&i.Version,
+		&i.Latitude,
+		&i.Longitude,
+		&i.PlaceName,
+		&i.CountryCode,
+		&i.CountryName,
+		&i.TakenAt,
+		&i.CustomFields,
+	)
+	return i, err
+}
+
+const updatePhotoPlace = `-- name: UpdatePhotoPlace :one
+UPDATE photos
+SET place_name = $2,
+    country_code = $3,
+    country_name = $4,
+    updated_at = $5,
+    version = version + 1
+WHERE id = $1
+RETURNING id, user_id, title, description, file_name, file_size, content_type, storage_path, public_url, created_at, updated_at, visibility, album_id, tags, version, latitude, longitude, place_name, country_code, country_name, taken_at, custom_fields
+`
+
+type UpdatePhotoPlaceParams struct {
+	ID          uuid.UUID          `json:"id"`
+	PlaceName   pgtype.Text        `json:"place_name"`
+	CountryCode pgtype.Text        `json:"country_code"`
+	CountryName pgtype.Text        `json:"country_name"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+func (q *Queries) UpdatePhotoPlace(ctx context.Context, arg UpdatePhotoPlaceParams) (Photo, error) {
+	row := q.db.QueryRow(ctx, updatePhotoPlace,
+		arg.ID,
+		arg.PlaceName,
+		arg.CountryCode,
+		arg.CountryName,
+		arg.UpdatedAt,
+	)
+	var i Photo
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Title,
+		&i.Description,
+		&i.FileName,
+		&i.FileSize,
+		&i.ContentType,
+		&i.StoragePath,
+		&i.PublicUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Visibility,
+		&i.AlbumID,
+		&i.Tags,
+		&i.Version,
+		&i.Latitude,
+		&i.Longitude,
+		&i.PlaceName,
+		&i.CountryCode,
+		&i.CountryName,
+		&i.TakenAt,
+		&i.CustomFields,
+	)
+	return i, err
+}
+
+const updatePhotoStorageInfo = `-- name: UpdatePhotoStorageInfo :one
+UPDATE photos
+SET file_name = $2,
+    file_size = $3,
+    content_type = $4,
+    storage_path = $5,
+    public_URL = $6,
+    updated_at = $7,
+    version = version + 1
+WHERE id = $1 AND version = $8
+RETURNING id, user_id, title, description, file_name, file_size, content_type, storage_path, public_url, created_at, updated_at, visibility, album_id, tags, version, latitude, longitude, place_name, country_code, country_name, taken_at, custom_fields
+`
+
+type UpdatePhotoStorageInfoParams struct {
+	ID          uuid.UUID          `json:"id"`
+	FileName    string             `json:"file_name"`
+	FileSize    int64              `json:"file_size"`
+	ContentType string             `json:"content_type"`
+	StoragePath string             `json:"storage_path"`
+	PublicUrl   pgtype.Text        `json:"public_url"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	Version     int32              `json:"version"`
+}
+
+func (q *Queries) UpdatePhotoStorageInfo(ctx context.Context, arg UpdatePhotoStorageInfoParams) (Photo, error) {
+	row := q.db.QueryRow(ctx, updatePhotoStorageInfo,
+		arg.ID,
+		arg.FileName,
+		arg.FileSize,
+		arg.ContentType,
 		arg.StoragePath,
 		arg.PublicUrl,
 		arg.UpdatedAt,
+		arg.Version,
 	)
 	var i Photo
 	err := row.Scan(
@@ -231,6 +1392,17 @@ func (q *Queries) UpdatePhotoStorageInfo(ctx context.Context, arg UpdatePhotoSto
 		&i.PublicUrl,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Visibility,
+		&i.AlbumID,
+		&i.Tags,
+		&i.Version,
+		&i.Latitude,
+		&i.Longitude,
+		&i.PlaceName,
+		&i.CountryCode,
+		&i.CountryName,
+		&i.TakenAt,
+		&i.CustomFields,
 	)
 	return i, err
 }
@@ -11,20 +11,106 @@ import (
 )
 
 type Querier interface {
+	AddPhotoTags(ctx context.Context, arg AddPhotoTagsParams) (Photo, error)
+	CancelUserDeletion(ctx context.Context, arg CancelUserDeletionParams) (int64, error)
+	ClaimUsersForErasure(ctx context.Context, arg ClaimUsersForErasureParams) ([]User, error)
+	ClusterPhotosInBoundingBox(ctx context.Context, arg ClusterPhotosInBoundingBoxParams) ([]ClusterPhotosInBoundingBoxRow, error)
+	CopyCustomFieldDefinitions(ctx context.Context, arg CopyCustomFieldDefinitionsParams) error
+	CountCustomFieldDefinitionsByUserID(ctx context.Context, userID uuid.UUID) (int64, error)
+	CountPhotosByAlbumID(ctx context.Context, albumID *uuid.UUID) (int64, error)
+	CountPhotosByCustomFields(ctx context.Context, arg CountPhotosByCustomFieldsParams) (int64, error)
+	CountPhotosByDateBucket(ctx context.Context, arg CountPhotosByDateBucketParams) ([]CountPhotosByDateBucketRow, error)
+	CountPhotosByPlace(ctx context.Context, arg CountPhotosByPlaceParams) (int64, error)
 	CountPhotosByUserID(ctx context.Context, userID uuid.UUID) (int64, error)
+	CountPhotosSharedWithUser(ctx context.Context, granteeID uuid.UUID) (int64, error)
+	CountPhotosTakenSince(ctx context.Context, arg CountPhotosTakenSinceParams) (int64, error)
+	CountPublicPhotosByUserID(ctx context.Context, userID uuid.UUID) (int64, error)
+	CreateAlbum(ctx context.Context, arg CreateAlbumParams) (Album, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) error
+	CreateCustomFieldDefinition(ctx context.Context, arg CreateCustomFieldDefinitionParams) (CustomFieldDefinition, error)
+	CreateExportJob(ctx context.Context, arg CreateExportJobParams) (ExportJob, error)
+	CreateOwnershipTransfer(ctx context.Context, arg CreateOwnershipTransferParams) (OwnershipTransfer, error)
 	CreatePhoto(ctx context.Context, arg CreatePhotoParams) (Photo, error)
+	CreatePhotoVersion(ctx context.Context, arg CreatePhotoVersionParams) (PhotoVersion, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error
+	CreateShareLink(ctx context.Context, arg CreateShareLinkParams) (ShareLink, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	DeleteAlbum(ctx context.Context, id uuid.UUID) error
+	DeleteCustomFieldDefinition(ctx context.Context, id uuid.UUID) error
+	DeleteGrant(ctx context.Context, id uuid.UUID) error
+	DeleteGrantsToUser(ctx context.Context, arg DeleteGrantsToUserParams) error
 	DeletePhoto(ctx context.Context, id uuid.UUID) error
+	DeletePhotoVersion(ctx context.Context, id uuid.UUID) error
+	DeleteRecoveryCodes(ctx context.Context, userID uuid.UUID) error
+	DeleteShareLink(ctx context.Context, id uuid.UUID) error
 	DeleteUser(ctx context.Context, id uuid.UUID) error
+	FailStaleExportJobs(ctx context.Context, arg FailStaleExportJobsParams) ([]ExportJob, error)
+	FailStaleOwnershipTransfers(ctx context.Context, arg FailStaleOwnershipTransfersParams) ([]OwnershipTransfer, error)
+	GetAlbumByID(ctx context.Context, id uuid.UUID) (Album, error)
+	GetAlbumRoleForUser(ctx context.Context, arg GetAlbumRoleForUserParams) (string, error)
+	GetCustomFieldDefinitionByID(ctx context.Context, id uuid.UUID) (CustomFieldDefinition, error)
+	GetExportJobByID(ctx context.Context, id uuid.UUID) (ExportJob, error)
+	GetGrantByID(ctx context.Context, id uuid.UUID) (AccessGrant, error)
+	GetLatestExportJobByKind(ctx context.Context, arg GetLatestExportJobByKindParams) (ExportJob, error)
+	GetOwnershipTransferByID(ctx context.Context, id uuid.UUID) (OwnershipTransfer, error)
 	GetPhotoByID(ctx context.Context, id uuid.UUID) (Photo, error)
+	GetPhotoVersion(ctx context.Context, arg GetPhotoVersionParams) (PhotoVersion, error)
+	GetShareLinkByID(ctx context.Context, id uuid.UUID) (ShareLink, error)
+	GetShareLinkByToken(ctx context.Context, token string) (ShareLink, error)
+	GetStorageUsageByUserID(ctx context.Context, userID uuid.UUID) (int64, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (User, error)
 	GetUserByUserName(ctx context.Context, username string) (User, error)
+	IncrementShareLinkViewCount(ctx context.Context, id uuid.UUID) (ShareLink, error)
+	ListAlbumsByUserID(ctx context.Context, userID uuid.UUID) ([]Album, error)
+	ListAlbumsSharedWithUser(ctx context.Context, granteeID uuid.UUID) ([]Album, error)
+	ListAuditEventsBySubject(ctx context.Context, arg ListAuditEventsBySubjectParams) ([]AuditEvent, error)
+	ListAuditEventsByUserID(ctx context.Context, userID *uuid.UUID) ([]AuditEvent, error)
+	ListCustomFieldDefinitionsByUserID(ctx context.Context, userID uuid.UUID) ([]CustomFieldDefinition, error)
+	ListExpiredExportJobs(ctx context.Context, arg ListExpiredExportJobsParams) ([]ExportJob, error)
+	ListGrantsByAlbumID(ctx context.Context, albumID *uuid.UUID) ([]AccessGrant, error)
+	ListGrantsByPhotoID(ctx context.Context, photoID *uuid.UUID) ([]AccessGrant, error)
+	ListGrantsByUserID(ctx context.Context, userID uuid.UUID) ([]AccessGrant, error)
+	ListOwnershipTransfersByUserID(ctx context.Context, arg ListOwnershipTransfersByUserIDParams) ([]OwnershipTransfer, error)
+	ListPhotoRolesForUser(ctx context.Context, arg ListPhotoRolesForUserParams) ([]string, error)
+	ListPhotoVersionsBeyondUserLimit(ctx context.Context, arg ListPhotoVersionsBeyondUserLimitParams) ([]PhotoVersion, error)
+	ListPhotoVersionsByPhotoID(ctx context.Context, photoID uuid.UUID) ([]PhotoVersion, error)
+	ListPhotoVersionsByUserID(ctx context.Context, userID uuid.UUID) ([]PhotoVersion, error)
+	ListPhotosByAlbumID(ctx context.Context, arg ListPhotosByAlbumIDParams) ([]Photo, error)
+	ListPhotosByCustomFields(ctx context.Context, arg ListPhotosByCustomFieldsParams) ([]Photo, error)
+	ListPhotosByPlace(ctx context.Context, arg ListPhotosByPlaceParams) ([]Photo, error)
 	ListPhotosByUserID(ctx context.Context, arg ListPhotosByUserIDParams) ([]Photo, error)
+	ListPhotosInBoundingBox(ctx context.Context, arg ListPhotosInBoundingBoxParams) ([]Photo, error)
+	ListPhotosMissingPlace(ctx context.Context, arg ListPhotosMissingPlaceParams) ([]Photo, error)
+	ListPhotosNear(ctx context.Context, arg ListPhotosNearParams) ([]Photo, error)
+	ListPhotosSharedWithUser(ctx context.Context, arg ListPhotosSharedWithUserParams) ([]Photo, error)
+	ListPlacesByUserID(ctx context.Context, arg ListPlacesByUserIDParams) ([]ListPlacesByUserIDRow, error)
+	ListPublicPhotos(ctx context.Context, arg ListPublicPhotosParams) ([]Photo, error)
+	ListPublicPhotosByUserID(ctx context.Context, arg ListPublicPhotosByUserIDParams) ([]Photo, error)
+	ListShareLinksByPhotoID(ctx context.Context, photoID uuid.UUID) ([]ShareLink, error)
+	ListShareLinksByUserID(ctx context.Context, userID uuid.UUID) ([]ShareLink, error)
+	ListUnalbumedPlacesByUserID(ctx context.Context, arg ListUnalbumedPlacesByUserIDParams) ([]ListUnalbumedPlacesByUserIDRow, error)
+	RemovePhotoCustomField(ctx context.Context, arg RemovePhotoCustomFieldParams) error
+	ScheduleUserDeletion(ctx context.Context, arg ScheduleUserDeletionParams) error
+	TransferAlbum(ctx context.Context, arg TransferAlbumParams) (int64, error)
+	TransferGrants(ctx context.Context, arg TransferGrantsParams) error
+	TransferPhoto(ctx context.Context, arg TransferPhotoParams) (Photo, error)
+	TransferPhotoVersion(ctx context.Context, arg TransferPhotoVersionParams) error
+	TransferShareLinks(ctx context.Context, arg TransferShareLinksParams) error
+	UpdateAlbum(ctx context.Context, arg UpdateAlbumParams) (Album, error)
+	UpdateCustomFieldDefinition(ctx context.Context, arg UpdateCustomFieldDefinitionParams) (CustomFieldDefinition, error)
+	UpdateExportJob(ctx context.Context, arg UpdateExportJobParams) (ExportJob, error)
+	UpdateOwnershipTransferStatus(ctx context.Context, arg UpdateOwnershipTransferStatusParams) (OwnershipTransfer, error)
 	UpdatePhoto(ctx context.Context, arg UpdatePhotoParams) (Photo, error)
+	UpdatePhotoAlbum(ctx context.Context, arg UpdatePhotoAlbumParams) (Photo, error)
+	UpdatePhotoPlace(ctx context.Context, arg UpdatePhotoPlaceParams) (Photo, error)
 	UpdatePhotoStorageInfo(ctx context.Context, arg UpdatePhotoStorageInfoParams) (Photo, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
 	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error
+	UpdateUserTOTP(ctx context.Context, arg UpdateUserTOTPParams) error
+	UpsertAlbumGrant(ctx context.Context, arg UpsertAlbumGrantParams) (AccessGrant, error)
+	UpsertPhotoGrant(ctx context.Context, arg UpsertPhotoGrantParams) (AccessGrant, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
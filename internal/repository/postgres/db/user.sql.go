@@ -12,10 +12,84 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const cancelUserDeletion = `-- name: CancelUserDeletion :execrows
+UPDATE users
+SET deletion_scheduled_at = NULL,
+    erasure_started_at = NULL,
+    updated_at = $1
+WHERE id = $2 AND deletion_scheduled_at > $1
+`
+
+type CancelUserDeletionParams struct {
+	Now pgtype.Timestamptz `json:"now"`
+	ID  uuid.UUID          `json:"id"`
+}
+
+func (q *Queries) CancelUserDeletion(ctx context.Context, arg CancelUserDeletionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, cancelUserDeletion, arg.Now, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const claimUsersForErasure = `-- name: ClaimUsersForErasure :many
+UPDATE users
+SET erasure_started_at = $1
+WHERE id IN (
+    SELECT id FROM users
+    WHERE deletion_scheduled_at <= $1
+      AND (erasure_started_at IS NULL OR erasure_started_at < $2)
+    ORDER BY deletion_scheduled_at
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, username, email, password_hash, created_at, updated_at, time_zone, email_verified_at, deletion_scheduled_at, erasure_started_at, totp_secret, totp_enabled_at
+`
+
+type ClaimUsersForErasureParams struct {
+	Now         pgtype.Timestamptz `json:"now"`
+	StaleBefore pgtype.Timestamptz `json:"stale_before"`
+	PageSize    int32              `json:"page_size"`
+}
+
+func (q *Queries) ClaimUsersForErasure(ctx context.Context, arg ClaimUsersForErasureParams) ([]User, error) {
+	rows, err := q.db.Query(ctx, claimUsersForErasure, arg.Now, arg.StaleBefore, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Email,
+			&i.PasswordHash,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TimeZone,
+			&i.EmailVerifiedAt,
+			&i.DeletionScheduledAt,
+			&i.ErasureStartedAt,
+			&i.TotpSecret,
+			&i.TotpEnabledAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (id, username, email, password_hash, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, username, email, password_hash, created_at, updated_at
+INSERT INTO users (id, username, email, password_hash, time_zone, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, username, email, password_hash, created_at, updated_at, time_zone, email_verified_at, deletion_scheduled_at, erasure_started_at, totp_secret, totp_enabled_at
 `
 
 type CreateUserParams struct {
@@ -23,6 +97,7 @@ type CreateUserParams struct {
 	Username     string             `json:"username"`
 	Email        string             `json:"email"`
 	PasswordHash string             `json:"password_hash"`
+	TimeZone     string             `json:"time_zone"`
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
 }
@@ -33,6 +108,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.Username,
 		arg.Email,
 		arg.PasswordHash,
+		arg.TimeZone,
 		arg.CreatedAt,
 		arg.UpdatedAt,
 	)
@@ -44,6 +120,12 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TimeZone,
+		&i.EmailVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.ErasureStartedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
 	)
 	return i, err
 }
@@ -59,7 +141,7 @@ func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, username, email, password_hash, created_at, updated_at FROM users
+SELECT id, username, email, password_hash, created_at, updated_at, time_zone, email_verified_at, deletion_scheduled_at, erasure_started_at, totp_secret, totp_enabled_at FROM users
 WHERE email = $1
 LIMIT 1
 `
@@ -74,12 +156,18 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TimeZone,
+		&i.EmailVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.ErasureStartedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, username, email, password_hash, created_at, updated_at from users
+SELECT id, username, email, password_hash, created_at, updated_at, time_zone, email_verified_at, deletion_scheduled_at, erasure_started_at, totp_secret, totp_enabled_at from users
 WHERE id = $1
 LIMIT 1
 `
@@ -94,12 +182,18 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TimeZone,
+		&i.EmailVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.ErasureStartedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
 	)
 	return i, err
 }
 
 const getUserByUserName = `-- name: GetUserByUserName :one
-SELECT id, username, email, password_hash, created_at, updated_at FROM users
+SELECT id, username, email, password_hash, created_at, updated_at, time_zone, email_verified_at, deletion_scheduled_at, erasure_started_at, totp_secret, totp_enabled_at FROM users
 WHERE username = $1
 LIMIT 1
 `
@@ -114,24 +208,52 @@ func (q *Queries) GetUserByUserName(ctx context.Context, username string) (User,
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TimeZone,
+		&i.EmailVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.ErasureStartedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
 	)
 	return i, err
 }
 
+const scheduleUserDeletion = `-- name: ScheduleUserDeletion :exec
+UPDATE users
+SET deletion_scheduled_at = $2,
+    updated_at = $3
+WHERE id = $1
+`
+
+type ScheduleUserDeletionParams struct {
+	ID                  uuid.UUID          `json:"id"`
+	DeletionScheduledAt pgtype.Timestamptz `json:"deletion_scheduled_at"`
+	UpdatedAt           pgtype.Timestamptz `json:"updated_at"`
+}
+
+func (q *Queries) ScheduleUserDeletion(ctx context.Context, arg ScheduleUserDeletionParams) error {
+	_, err := q.db.Exec(ctx, scheduleUserDeletion, arg.ID, arg.DeletionScheduledAt, arg.UpdatedAt)
+	return err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET username = $2,
     email = $3,
-    updated_at = $4
+    time_zone = $4,
+    email_verified_at = $5,
+    updated_at = $6
 WHERE id = $1
-RETURNING id, username, email, password_hash, created_at, updated_at
+RETURNING id, username, email, password_hash, created_at, updated_at, time_zone, email_verified_at, deletion_scheduled_at, erasure_started_at, totp_secret, totp_enabled_at
 `
 
 type UpdateUserParams struct {
-	ID        uuid.UUID          `json:"id"`
-	Username  string             `json:"username"`
-	Email     string             `json:"email"`
-	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	ID              uuid.UUID          `json:"id"`
+	Username        string             `json:"username"`
+	Email           string             `json:"email"`
+	TimeZone        string             `json:"time_zone"`
+	EmailVerifiedAt pgtype.Timestamptz `json:"email_verified_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
@@ -139,6 +261,8 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		arg.ID,
 		arg.Username,
 		arg.Email,
+		arg.TimeZone,
+		arg.EmailVerifiedAt,
 		arg.UpdatedAt,
 	)
 	var i User
@@ -149,6 +273,12 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TimeZone,
+		&i.EmailVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.ErasureStartedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
 	)
 	return i, err
 }
@@ -170,3 +300,28 @@ func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPassword
 	_, err := q.db.Exec(ctx, updateUserPassword, arg.ID, arg.PasswordHash, arg.UpdatedAt)
 	return err
 }
+
+const updateUserTOTP = `-- name: UpdateUserTOTP :exec
+UPDATE users
+SET totp_secret = $2,
+    totp_enabled_at = $3,
+    updated_at = $4
+WHERE id = $1
+`
+
+type UpdateUserTOTPParams struct {
+	ID            uuid.UUID          `json:"id"`
+	TotpSecret    pgtype.Text        `json:"totp_secret"`
+	TotpEnabledAt pgtype.Timestamptz `json:"totp_enabled_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+}
+
+func (q *Queries) UpdateUserTOTP(ctx context.Context, arg UpdateUserTOTPParams) error {
+	_, err := q.db.Exec(ctx, updateUserTOTP,
+		arg.ID,
+		arg.TotpSecret,
+		arg.TotpEnabledAt,
+		arg.UpdatedAt,
+	)
+	return err
+}
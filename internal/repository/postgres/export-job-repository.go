@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mmd-moradi/goup/internal/domain"
+	"github.com/mmd-moradi/goup/internal/repository/postgres/db"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+)
+
+type ExportJobRepository struct {
+	queries *db.Queries
+	pool    *pgxpool.Pool
+}
+
+func NewExportJobRepository(pool *pgxpool.Pool) *ExportJobRepository {
+	return &ExportJobRepository{
+		queries: db.New(pool),
+		pool:    pool,
+	}
+}
+
+func (r *ExportJobRepository) Create(ctx context.Context, job *domain.ExportJob) error {
+	_, err := r.queries.CreateExportJob(ctx, db.CreateExportJobParams{
+		ID:        job.ID,
+		UserID:    job.UserID,
+		Kind:      string(job.Kind),
+		Status:    string(job.Status),
+		PhotoIds:  job.PhotoIDs,
+		CreatedAt: TimeToTimestamptz(job.CreatedAt),
+		UpdatedAt: TimeToTimestamptz(job.UpdatedAt),
+	})
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return apperrors.New(apperrors.Conflict, "an export of this kind is already in progress")
+		}
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to create export job: %v", err)
+	}
+
+	return nil
+}
+
+func (r *ExportJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.ExportJob, error) {
+	job, err := r.queries.GetExportJobByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperrors.NewWithFormat(apperrors.NotFound, "export with id %s not found", id)
+		}
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to get export job: %v", err)
+	}
+
+	return toDomainExportJob(job), nil
+}
+
+func (r *ExportJobRepository) GetLatestByKind(ctx context.Context, userID uuid.UUID, kind domain.ExportKind) (*domain.ExportJob, error) {
+	job, err := r.queries.GetLatestExportJobByKind(ctx, db.GetLatestExportJobByKindParams{
+		UserID: userID,
+		Kind:   string(kind),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperrors.NewWithFormat(apperrors.NotFound, "no %s export found", kind)
+		}
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to get export job: %v", err)
+	}
+
+	return toDomainExportJob(job), nil
+}
+
+func (r *ExportJobRepository) Update(ctx context.Context, job *domain.ExportJob) error {
+	_, err := r.queries.UpdateExportJob(ctx, db.UpdateExportJobParams{
+		ID:          job.ID,
+		Status:      string(job.Status),
+		StoragePath: pgtype.Text{String: job.StoragePath, Valid: job.StoragePath != ""},
+		Error:       pgtype.Text{String: job.Error, Valid: job.Error != ""},
+		ExpiresAt:   TimePtrToTimestamptz(job.ExpiresAt),
+		UpdatedAt:   TimeToTimestamptz(job.UpdatedAt),
+		PhotoIds:    job.PhotoIDs,
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return apperrors.NewWithFormat(apperrors.NotFound, "export with id %s not found", job.ID)
+		}
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to update export job: %v", err)
+	}
+
+	return nil
+}
+
+func (r *ExportJobRepository) FailStale(ctx context.Context, staleBefore time.Time, message string, now time.Time) ([]*domain.ExportJob, error) {
+	jobs, err := r.queries.FailStaleExportJobs(ctx, db.FailStaleExportJobsParams{
+		Error:       pgtype.Text{String: message, Valid: true},
+		UpdatedAt:   TimeToTimestamptz(now),
+		StaleBefore: TimeToTimestamptz(staleBefore),
+	})
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to fail stale export jobs: %v", err)
+	}
+
+	result := make([]*domain.ExportJob, len(jobs))
+	for i, job := range jobs {
+		result[i] = toDomainExportJob(job)
+	}
+	return result, nil
+}
+
+func (r *ExportJobRepository) GetExpired(ctx context.Context, now time.Time, limit int) ([]*domain.ExportJob, error) {
+	jobs, err := r.queries.ListExpiredExportJobs(ctx, db.ListExpiredExportJobsParams{
+		Now:      TimeToTimestamptz(now),
+		PageSize: int32(limit),
+	})
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list expired export jobs: %v", err)
+	}
+
+	result := make([]*domain.ExportJob, len(jobs))
+	for i, job := range jobs {
+		result[i] = toDomainExportJob(job)
+	}
+	return result, nil
+}
+
+func toDomainExportJob(job db.ExportJob) *domain.ExportJob {
+	return &domain.ExportJob{
+		ID:          job.ID,
+		UserID:      job.UserID,
+		Kind:        domain.ExportKind(job.Kind),
+		Status:      domain.ExportStatus(job.Status),
+		PhotoIDs:    job.PhotoIds,
+		StoragePath: job.StoragePath.String,
+		Error:       job.Error.String,
+		ExpiresAt:   TimestamptzToTimePtr(job.ExpiresAt),
+		CreatedAt:   TimestamptzToTime(job.CreatedAt),
+		UpdatedAt:   TimestamptzToTime(job.UpdatedAt),
+	}
+}
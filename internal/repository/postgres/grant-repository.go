@@ -0,0 +1,161 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mmd-moradi/goup/internal/domain"
+	"github.com/mmd-moradi/goup/internal/repository/postgres/db"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+)
+
+type GrantRepository struct {
+	queries *db.Queries
+	pool    *pgxpool.Pool
+}
+
+func NewGrantRepository(pool *pgxpool.Pool) *GrantRepository {
+	return &GrantRepository{
+		queries: db.New(pool),
+		pool:    pool,
+	}
+}
+
+// Upsert creates the grant, or changes the role of the grantee's existing
+// grant on the same photo or album.
+func (r *GrantRepository) Upsert(ctx context.Context, grant *domain.Grant) (*domain.Grant, error) {
+	var (
+		row db.AccessGrant
+		err error
+	)
+
+	if grant.PhotoID != nil {
+		row, err = r.queries.UpsertPhotoGrant(ctx, db.UpsertPhotoGrantParams{
+			ID:        grant.ID,
+			PhotoID:   grant.PhotoID,
+			GranteeID: grant.GranteeID,
+			GrantedBy: grant.GrantedBy,
+			Role:      string(grant.Role),
+			CreatedAt: TimeToTimestamptz(grant.CreatedAt),
+		})
+	} else {
+		row, err = r.queries.UpsertAlbumGrant(ctx, db.UpsertAlbumGrantParams{
+			ID:        grant.ID,
+			AlbumID:   grant.AlbumID,
+			GranteeID: grant.GranteeID,
+			GrantedBy: grant.GrantedBy,
+			Role:      string(grant.Role),
+			CreatedAt: TimeToTimestamptz(grant.CreatedAt),
+		})
+	}
+
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to save grant: %v", err)
+	}
+
+	return toDomainGrant(row), nil
+}
+
+func (r *GrantRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Grant, error) {
+	grant, err := r.queries.GetGrantByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperrors.NewWithFormat(apperrors.NotFound, "grant with id %s not found", id)
+		}
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to get grant: %v", err)
+	}
+
+	return toDomainGrant(grant), nil
+}
+
+func (r *GrantRepository) GetByPhotoID(ctx context.Context, photoID uuid.UUID) ([]*domain.Grant, error) {
+	grants, err := r.queries.ListGrantsByPhotoID(ctx, &photoID)
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list grants: %v", err)
+	}
+
+	return toDomainGrants(grants), nil
+}
+
+func (r *GrantRepository) GetByAlbumID(ctx context.Context, albumID uuid.UUID) ([]*domain.Grant, error) {
+	grants, err := r.queries.ListGrantsByAlbumID(ctx, &albumID)
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list grants: %v", err)
+	}
+
+	return toDomainGrants(grants), nil
+}
+
+func (r *GrantRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.Grant, error) {
+	grants, err := r.queries.ListGrantsByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list grants: %v", err)
+	}
+
+	return toDomainGrants(grants), nil
+}
+
+func (r *GrantRepository) GetPhotoRoles(ctx context.Context, photoID uuid.UUID, albumID *uuid.UUID, userID uuid.UUID) ([]domain.Role, error) {
+	roles, err := r.queries.ListPhotoRolesForUser(ctx, db.ListPhotoRolesForUserParams{
+		GranteeID: userID,
+		PhotoID:   &photoID,
+		AlbumID:   albumID,
+	})
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to get photo roles: %v", err)
+	}
+
+	result := make([]domain.Role, len(roles))
+	for i, role := range roles {
+		result[i] = domain.Role(role)
+	}
+
+	return result, nil
+}
+
+func (r *GrantRepository) GetAlbumRole(ctx context.Context, albumID uuid.UUID, userID uuid.UUID) (domain.Role, error) {
+	role, err := r.queries.GetAlbumRoleForUser(ctx, db.GetAlbumRoleForUserParams{
+		GranteeID: userID,
+		AlbumID:   &albumID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+		return "", apperrors.NewWithFormat(apperrors.InternalServer, "failed to get album role: %v", err)
+	}
+
+	return domain.Role(role), nil
+}
+
+func (r *GrantRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	err := r.queries.DeleteGrant(ctx, id)
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to delete grant: %v", err)
+	}
+
+	return nil
+}
+
+func toDomainGrant(grant db.AccessGrant) *domain.Grant {
+	return &domain.Grant{
+		ID:        grant.ID,
+		PhotoID:   grant.PhotoID,
+		AlbumID:   grant.AlbumID,
+		GranteeID: grant.GranteeID,
+		GrantedBy: grant.GrantedBy,
+		Role:      domain.Role(grant.Role),
+		CreatedAt: TimestamptzToTime(grant.CreatedAt),
+	}
+}
+
+func toDomainGrants(grants []db.AccessGrant) []*domain.Grant {
+	result := make([]*domain.Grant, len(grants))
+	for i, grant := range grants {
+		result[i] = toDomainGrant(grant)
+	}
+	return result
+}
@@ -1,6 +1,8 @@
 package postgres
 
 import (
+	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -38,3 +40,84 @@ func TimestamptzToTime(ts pgtype.Timestamptz) time.Time {
 	}
 	return ts.Time
 }
+
+// TimePtrToTimestamptz converts an optional time to pgtype.Timestamptz
+func TimePtrToTimestamptz(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{}
+	}
+	return TimeToTimestamptz(*t)
+}
+
+// TimestamptzToTimePtr converts a pgtype.Timestamptz to an optional time
+func TimestamptzToTimePtr(ts pgtype.Timestamptz) *time.Time {
+	if !ts.Valid {
+		return nil
+	}
+	t := ts.Time
+	return &t
+}
+
+// IntPtrToPgInt4 converts an optional int to pgtype.Int4
+func IntPtrToPgInt4(i *int) pgtype.Int4 {
+	if i == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{
+		Int32: int32(*i),
+		Valid: true,
+	}
+}
+
+// PgInt4ToIntPtr converts a pgtype.Int4 to an optional int
+func PgInt4ToIntPtr(i pgtype.Int4) *int {
+	if !i.Valid {
+		return nil
+	}
+	v := int(i.Int32)
+	return &v
+}
+
+// FloatPtrToPgFloat8 converts an optional float64 to pgtype.Float8
+func FloatPtrToPgFloat8(f *float64) pgtype.Float8 {
+	if f == nil {
+		return pgtype.Float8{}
+	}
+	return pgtype.Float8{
+		Float64: *f,
+		Valid:   true,
+	}
+}
+
+// PgFloat8ToFloatPtr converts a pgtype.Float8 to an optional float64
+func PgFloat8ToFloatPtr(f pgtype.Float8) *float64 {
+	if !f.Valid {
+		return nil
+	}
+	v := f.Float64
+	return &v
+}
+
+// containsPattern returns an ILIKE pattern matching values that contain s,
+// with the wildcards of s escaped.
+func containsPattern(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+	return "%" + s + "%"
+}
+
+// CustomFieldsToJSON converts custom field values to a JSONB document
+func CustomFieldsToJSON(fields map[string]interface{}) ([]byte, error) {
+	if fields == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(fields)
+}
+
+// JSONToCustomFields converts a JSONB document to custom field values
+func JSONToCustomFields(data []byte) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if len(data) > 0 {
+		_ = json.Unmarshal(data, &fields)
+	}
+	return fields
+}
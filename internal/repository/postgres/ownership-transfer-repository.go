@@ -0,0 +1,261 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mmd-moradi/goup/internal/domain"
+	"github.com/mmd-moradi/goup/internal/repository/postgres/db"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+)
+
+type OwnershipTransferRepository struct {
+	queries *db.Queries
+	pool    *pgxpool.Pool
+}
+
+func NewOwnershipTransferRepository(pool *pgxpool.Pool) *OwnershipTransferRepository {
+	return &OwnershipTransferRepository{
+		queries: db.New(pool),
+		pool:    pool,
+	}
+}
+
+func (r *OwnershipTransferRepository) Create(ctx context.Context, transfer *domain.OwnershipTransfer) error {
+	_, err := r.queries.CreateOwnershipTransfer(ctx, db.CreateOwnershipTransferParams{
+		ID:         transfer.ID,
+		FromUserID: transfer.FromUserID,
+		ToUserID:   transfer.ToUserID,
+		Status:     string(transfer.Status),
+		Selection:  transfer.Selection,
+		PhotoIds:   transfer.PhotoIDs,
+		AlbumIds:   transfer.AlbumIDs,
+		ExpiresAt:  TimeToTimestamptz(transfer.ExpiresAt),
+		CreatedAt:  TimeToTimestamptz(transfer.CreatedAt),
+		UpdatedAt:  TimeToTimestamptz(transfer.UpdatedAt),
+	})
+
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to create ownership transfer: %v", err)
+	}
+
+	return nil
+}
+
+func (r *OwnershipTransferRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.OwnershipTransfer, error) {
+	transfer, err := r.queries.GetOwnershipTransferByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperrors.NewWithFormat(apperrors.NotFound, "transfer with id %s not found", id)
+		}
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to get ownership transfer: %v", err)
+	}
+
+	return toDomainOwnershipTransfer(transfer), nil
+}
+
+func (r *OwnershipTransferRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*domain.OwnershipTransfer, error) {
+	transfers, err := r.queries.ListOwnershipTransfersByUserID(ctx, db.ListOwnershipTransfersByUserIDParams{
+		UserID:   userID,
+		PageSize: int32(limit),
+	})
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list ownership transfers: %v", err)
+	}
+
+	result := make([]*domain.OwnershipTransfer, len(transfers))
+	for i, transfer := range transfers {
+		result[i] = toDomainOwnershipTransfer(transfer)
+	}
+	return result, nil
+}
+
+func (r *OwnershipTransferRepository) UpdateStatus(ctx context.Context, transfer *domain.OwnershipTransfer, currentStatus domain.TransferStatus) error {
+	return updateTransferStatus(ctx, r.queries, transfer, currentStatus)
+}
+
+func (r *OwnershipTransferRepository) FailStale(ctx context.Context, staleBefore time.Time, message string, now time.Time) ([]*domain.OwnershipTransfer, error) {
+	transfers, err := r.queries.FailStaleOwnershipTransfers(ctx, db.FailStaleOwnershipTransfersParams{
+		Error:       pgtype.Text{String: message, Valid: true},
+		UpdatedAt:   TimeToTimestamptz(now),
+		StaleBefore: TimeToTimestamptz(staleBefore),
+	})
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to fail stale ownership transfers: %v", err)
+	}
+
+	result := make([]*domain.OwnershipTransfer, len(transfers))
+	for i, transfer := range transfers {
+		result[i] = toDomainOwnershipTransfer(transfer)
+	}
+	return result, nil
+}
+
+func (r *OwnershipTransferRepository) Complete(ctx context.Context, transfer *domain.OwnershipTransfer, photos []*domain.TransferredPhoto, albumIDs []uuid.UUID, event *domain.AuditEvent) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to start transfer: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	queries := r.queries.WithTx(tx)
+
+	photoIDs := make([]uuid.UUID, len(photos))
+	for i, moved := range photos {
+		photoIDs[i] = moved.Photo.ID
+	}
+	if albumIDs == nil {
+		albumIDs = []uuid.UUID{}
+	}
+
+	for _, albumID := range albumIDs {
+		_, err := queries.TransferAlbum(ctx, db.TransferAlbumParams{
+			UserID:     transfer.ToUserID,
+			UpdatedAt:  TimeToTimestamptz(event.CreatedAt),
+			ID:         albumID,
+			FromUserID: transfer.FromUserID,
+		})
+		if err != nil {
+			return apperrors.NewWithFormat(apperrors.InternalServer, "failed to transfer album: %v", err)
+		}
+	}
+
+	for _, moved := range photos {
+		_, err := queries.TransferPhoto(ctx, db.TransferPhotoParams{
+			UserID:      transfer.ToUserID,
+			StoragePath: moved.StoragePath,
+			PublicUrl:   pgtype.Text{String: moved.PublicURL, Valid: moved.PublicURL != ""},
+			AlbumID:     moved.AlbumID,
+			UpdatedAt:   TimeToTimestamptz(event.CreatedAt),
+			ID:          moved.Photo.ID,
+			FromUserID:  transfer.FromUserID,
+			Version:     int32(moved.Photo.Version),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return apperrors.NewWithFormat(apperrors.Conflict, "photo with id %s changed during the transfer", moved.Photo.ID)
+			}
+			return apperrors.NewWithFormat(apperrors.InternalServer, "failed to transfer photo: %v", err)
+		}
+
+		for _, version := range moved.Versions {
+			err := queries.TransferPhotoVersion(ctx, db.TransferPhotoVersionParams{
+				ID:          version.Version.ID,
+				StoragePath: version.StoragePath,
+				PublicUrl:   pgtype.Text{String: version.PublicURL, Valid: version.PublicURL != ""},
+			})
+			if err != nil {
+				return apperrors.NewWithFormat(apperrors.InternalServer, "failed to transfer photo version: %v", err)
+			}
+		}
+	}
+
+	// Grants the recipient held on what they now own are redundant, and the
+	// remaining ones are now given by the recipient.
+	err = queries.DeleteGrantsToUser(ctx, db.DeleteGrantsToUserParams{
+		UserID:   transfer.ToUserID,
+		PhotoIds: photoIDs,
+		AlbumIds: albumIDs,
+	})
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to transfer grants: %v", err)
+	}
+	err = queries.TransferGrants(ctx, db.TransferGrantsParams{
+		UserID:   transfer.ToUserID,
+		PhotoIds: photoIDs,
+		AlbumIds: albumIDs,
+	})
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to transfer grants: %v", err)
+	}
+
+	err = queries.TransferShareLinks(ctx, db.TransferShareLinksParams{
+		UserID:   transfer.ToUserID,
+		PhotoIds: photoIDs,
+	})
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to transfer share links: %v", err)
+	}
+
+	err = queries.CopyCustomFieldDefinitions(ctx, db.CopyCustomFieldDefinitionsParams{
+		ToUserID:   transfer.ToUserID,
+		CreatedAt:  TimeToTimestamptz(event.CreatedAt),
+		FromUserID: transfer.FromUserID,
+		PhotoIds:   photoIDs,
+	})
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to copy custom fields: %v", err)
+	}
+
+	err = updateTransferStatus(ctx, queries, transfer, domain.TransferStatusRunning)
+	if err != nil {
+		return err
+	}
+
+	// The storage used by both accounts is recalculated from what they own
+	// once the move is part of the transaction.
+	fromUsage, err := queries.GetStorageUsageByUserID(ctx, transfer.FromUserID)
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to get storage usage: %v", err)
+	}
+	toUsage, err := queries.GetStorageUsageByUserID(ctx, transfer.ToUserID)
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to get storage usage: %v", err)
+	}
+	event.Details["from_storage_used"] = fromUsage
+	event.Details["to_storage_used"] = toUsage
+
+	err = createAuditEvent(ctx, queries, event)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to commit transfer: %v", err)
+	}
+	return nil
+}
+
+func updateTransferStatus(ctx context.Context, queries *db.Queries, transfer *domain.OwnershipTransfer, currentStatus domain.TransferStatus) error {
+	_, err := queries.UpdateOwnershipTransferStatus(ctx, db.UpdateOwnershipTransferStatusParams{
+		Status:        string(transfer.Status),
+		PhotoCount:    int32(transfer.PhotoCount),
+		Error:         pgtype.Text{String: transfer.Error, Valid: transfer.Error != ""},
+		CompletedAt:   TimePtrToTimestamptz(transfer.CompletedAt),
+		UpdatedAt:     TimeToTimestamptz(transfer.UpdatedAt),
+		ID:            transfer.ID,
+		CurrentStatus: string(currentStatus),
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return apperrors.NewWithFormat(apperrors.Conflict, "transfer with id %s is no longer %s", transfer.ID, currentStatus)
+		}
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to update ownership transfer: %v", err)
+	}
+
+	return nil
+}
+
+func toDomainOwnershipTransfer(transfer db.OwnershipTransfer) *domain.OwnershipTransfer {
+	return &domain.OwnershipTransfer{
+		ID:          transfer.ID,
+		FromUserID:  transfer.FromUserID,
+		ToUserID:    transfer.ToUserID,
+		Status:      domain.TransferStatus(transfer.Status),
+		Selection:   transfer.Selection,
+		PhotoIDs:    transfer.PhotoIds,
+		AlbumIDs:    transfer.AlbumIds,
+		PhotoCount:  int(transfer.PhotoCount),
+		Error:       transfer.Error.String,
+		ExpiresAt:   TimestamptzToTime(transfer.ExpiresAt),
+		CompletedAt: TimestamptzToTimePtr(transfer.CompletedAt),
+		CreatedAt:   TimestamptzToTime(transfer.CreatedAt),
+		UpdatedAt:   TimestamptzToTime(transfer.UpdatedAt),
+	}
+}
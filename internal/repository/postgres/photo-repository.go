@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -27,18 +28,31 @@ func NewPhotoRepository(pool *pgxpool.Pool) *PhotoRepository {
 }
 
 func (r *PhotoRepository) Create(ctx context.Context, photo *domain.Photo) error {
-	_, err := r.queries.CreatePhoto(ctx, db.CreatePhotoParams{
-		ID:          photo.ID,
-		UserID:      photo.UserID,
-		Title:       photo.Title,
-		Description: pgtype.Text{String: photo.Description, Valid: photo.Description != ""},
-		FileName:    photo.FileName,
-		FileSize:    photo.FileSize,
-		ContentType: photo.ContentType,
-		StoragePath: photo.StoragePath,
-		PublicUrl:   pgtype.Text{String: photo.PublicURL, Valid: photo.PublicURL != ""},
-		CreatedAt:   TimeToTimestamptz(photo.CreatedAt),
-		UpdatedAt:   TimeToTimestamptz(photo.UpdatedAt),
+	customFields, err := CustomFieldsToJSON(photo.CustomFields)
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to encode custom fields: %v", err)
+	}
+
+	_, err = r.queries.CreatePhoto(ctx, db.CreatePhotoParams{
+		ID:           photo.ID,
+		UserID:       photo.UserID,
+		Title:        photo.Title,
+		Description:  pgtype.Text{String: photo.Description, Valid: photo.Description != ""},
+		FileName:     photo.FileName,
+		FileSize:     photo.FileSize,
+		ContentType:  photo.ContentType,
+		StoragePath:  photo.StoragePath,
+		PublicUrl:    pgtype.Text{String: photo.PublicURL, Valid: photo.PublicURL != ""},
+		Visibility:   string(photo.Visibility),
+		Latitude:     FloatPtrToPgFloat8(photo.Latitude),
+		Longitude:    FloatPtrToPgFloat8(photo.Longitude),
+		PlaceName:    pgtype.Text{String: photo.PlaceName, Valid: photo.PlaceName != ""},
+		CountryCode:  pgtype.Text{String: photo.CountryCode, Valid: photo.CountryCode != ""},
+		CountryName:  pgtype.Text{String: photo.CountryName, Valid: photo.CountryName != ""},
+		TakenAt:      TimePtrToTimestamptz(photo.TakenAt),
+		CustomFields: customFields,
+		CreatedAt:    TimeToTimestamptz(photo.CreatedAt),
+		UpdatedAt:    TimeToTimestamptz(photo.UpdatedAt),
 	})
 
 	if err != nil {
@@ -57,19 +71,15 @@ func (r *PhotoRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Ph
 		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to get photo: %v", err)
 	}
 
-	return &domain.Photo{
-		ID:          photo.ID,
-		UserID:      photo.UserID,
-		Title:       photo.Title,
-		Description: photo.Description.String,
-		FileName:    photo.FileName,
-		FileSize:    photo.FileSize,
-		ContentType: photo.ContentType,
-		StoragePath: photo.StoragePath,
-		PublicURL:   photo.PublicUrl.String,
-		CreatedAt:   TimestamptzToTime(photo.CreatedAt),
-		UpdatedAt:   TimestamptzToTime(photo.UpdatedAt),
-	}, nil
+	return toDomainPhoto(photo), nil
+}
+
+func (r *PhotoRepository) GetStorageUsage(ctx context.Context, userID uuid.UUID) (int64, error) {
+	used, err := r.queries.GetStorageUsageByUserID(ctx, userID)
+	if err != nil {
+		return 0, apperrors.NewWithFormat(apperrors.InternalServer, "failed to get storage usage: %v", err)
+	}
+	return used, nil
 }
 
 func (r *PhotoRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Photo, int, error) {
@@ -87,31 +97,398 @@ func (r *PhotoRepository) GetByUserID(ctx context.Context, userID uuid.UUID, lim
 		return nil, 0, apperrors.NewWithFormat(apperrors.InternalServer, "failed to count photos: %v", err)
 	}
 
-	result := make([]*domain.Photo, len(photos))
-	for i, photo := range photos {
-		result[i] = &domain.Photo{
-			ID:          photo.ID,
-			UserID:      photo.UserID,
-			Title:       photo.Title,
-			Description: photo.Description.String,
-			FileName:    photo.FileName,
-			FileSize:    photo.FileSize,
-			ContentType: photo.ContentType,
-			StoragePath: photo.StoragePath,
-			PublicURL:   photo.PublicUrl.String,
-			CreatedAt:   TimestamptzToTime(photo.CreatedAt),
-			UpdatedAt:   TimestamptzToTime(photo.UpdatedAt),
+	return toDomainPhotos(photos), int(count), nil
+}
+
+func (r *PhotoRepository) GetPublicByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Photo, int, error) {
+	photos, err := r.queries.ListPublicPhotosByUserID(ctx, db.ListPublicPhotosByUserIDParams{
+		UserID: userID,
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		return nil, 0, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list public photos: %v", err)
+	}
+
+	count, err := r.queries.CountPublicPhotosByUserID(ctx, userID)
+	if err != nil {
+		return nil, 0, apperrors.NewWithFormat(apperrors.InternalServer, "failed to count public photos: %v", err)
+	}
+
+	return toDomainPhotos(photos), int(count), nil
+}
+
+func (r *PhotoRepository) ListPublic(ctx context.Context, cursor *domain.PhotoCursor, limit int) ([]*domain.Photo, error) {
+	params := db.ListPublicPhotosParams{
+		PageSize: int32(limit),
+	}
+	if cursor != nil {
+		params.CursorCreatedAt = TimeToTimestamptz(cursor.CreatedAt)
+		params.CursorID = &cursor.ID
+	}
+
+	photos, err := r.queries.ListPublicPhotos(ctx, params)
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list public photos: %v", err)
+	}
+
+	return toDomainPhotos(photos), nil
+}
+
+func (r *PhotoRepository) GetByAlbumID(ctx context.Context, albumID uuid.UUID, limit, offset int) ([]*domain.Photo, int, error) {
+	photos, err := r.queries.ListPhotosByAlbumID(ctx, db.ListPhotosByAlbumIDParams{
+		AlbumID: &albumID,
+		Limit:   int32(limit),
+		Offset:  int32(offset),
+	})
+	if err != nil {
+		return nil, 0, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list album photos: %v", err)
+	}
+
+	count, err := r.queries.CountPhotosByAlbumID(ctx, &albumID)
+	if err != nil {
+		return nil, 0, apperrors.NewWithFormat(apperrors.InternalServer, "failed to count album photos: %v", err)
+	}
+
+	return toDomainPhotos(photos), int(count), nil
+}
+
+func (r *PhotoRepository) GetSharedWithUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Photo, int, error) {
+	photos, err := r.queries.ListPhotosSharedWithUser(ctx, db.ListPhotosSharedWithUserParams{
+		GranteeID:  userID,
+		PageSize:   int32(limit),
+		PageOffset: int32(offset),
+	})
+	if err != nil {
+		return nil, 0, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list shared photos: %v", err)
+	}
+
+	count, err := r.queries.CountPhotosSharedWithUser(ctx, userID)
+	if err != nil {
+		return nil, 0, apperrors.NewWithFormat(apperrors.InternalServer, "failed to count shared photos: %v", err)
+	}
+
+	return toDomainPhotos(photos), int(count), nil
+}
+
+func (r *PhotoRepository) GetInBoundingBox(ctx context.Context, userID uuid.UUID, box domain.BoundingBox, limit int) ([]*domain.Photo, error) {
+	photos, err := r.queries.ListPhotosInBoundingBox(ctx, db.ListPhotosInBoundingBoxParams{
+		UserID:   userID,
+		MinLat:   box.MinLat,
+		MaxLat:   box.MaxLat,
+		MinLng:   box.MinLng,
+		MaxLng:   box.MaxLng,
+		PageSize: int32(limit),
+	})
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list photos in bounding box: %v", err)
+	}
+
+	return toDomainPhotos(photos), nil
+}
+
+func (r *PhotoRepository) GetNear(ctx context.Context, userID uuid.UUID, lat, lng, radiusMeters float64, limit int) ([]*domain.Photo, error) {
+	box := domain.BoundingBoxAround(lat, lng, radiusMeters)
+	photos, err := r.queries.ListPhotosNear(ctx, db.ListPhotosNearParams{
+		UserID:       userID,
+		MinLat:       box.MinLat,
+		MaxLat:       box.MaxLat,
+		MinLng:       box.MinLng,
+		MaxLng:       box.MaxLng,
+		Lat:          lat,
+		Lng:          lng,
+		RadiusMeters: radiusMeters,
+		PageSize:     int32(limit),
+	})
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list nearby photos: %v", err)
+	}
+
+	return toDomainPhotos(photos), nil
+}
+
+func (r *PhotoRepository) GetClusters(ctx context.Context, userID uuid.UUID, box domain.BoundingBox, cellSize float64) ([]*domain.GeoCluster, error) {
+	rows, err := r.queries.ClusterPhotosInBoundingBox(ctx, db.ClusterPhotosInBoundingBoxParams{
+		CellSize: cellSize,
+		UserID:   userID,
+		MinLat:   box.MinLat,
+		MaxLat:   box.MaxLat,
+		MinLng:   box.MinLng,
+		MaxLng:   box.MaxLng,
+	})
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to cluster photos: %v", err)
+	}
+
+	clusters := make([]*domain.GeoCluster, len(rows))
+	for i, row := range rows {
+		clusters[i] = &domain.GeoCluster{
+			Latitude:  row.Latitude,
+			Longitude: row.Longitude,
+			Count:     int(row.Count),
+			PhotoID:   row.PhotoID,
+		}
+	}
+
+	return clusters, nil
+}
+
+func (r *PhotoRepository) GetByPlace(ctx context.Context, userID uuid.UUID, query string, limit, offset int) ([]*domain.Photo, int, error) {
+	pattern := containsPattern(query)
+	photos, err := r.queries.ListPhotosByPlace(ctx, db.ListPhotosByPlaceParams{
+		UserID:     userID,
+		Pattern:    pattern,
+		PageSize:   int32(limit),
+		PageOffset: int32(offset),
+	})
+	if err != nil {
+		return nil, 0, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list photos by place: %v", err)
+	}
+
+	count, err := r.queries.CountPhotosByPlace(ctx, db.CountPhotosByPlaceParams{
+		UserID:  userID,
+		Pattern: pattern,
+	})
+	if err != nil {
+		return nil, 0, apperrors.NewWithFormat(apperrors.InternalServer, "failed to count photos by place: %v", err)
+	}
+
+	return toDomainPhotos(photos), int(count), nil
+}
+
+func (r *PhotoRepository) GetByCustomFields(ctx context.Context, userID uuid.UUID, filter map[string]interface{}, limit, offset int) ([]*domain.Photo, int, error) {
+	document, err := CustomFieldsToJSON(filter)
+	if err != nil {
+		return nil, 0, apperrors.NewWithFormat(apperrors.InternalServer, "failed to encode custom field filter: %v", err)
+	}
+
+	photos, err := r.queries.ListPhotosByCustomFields(ctx, db.ListPhotosByCustomFieldsParams{
+		UserID:     userID,
+		Filter:     document,
+		PageSize:   int32(limit),
+		PageOffset: int32(offset),
+	})
+	if err != nil {
+		return nil, 0, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list photos by custom fields: %v", err)
+	}
+
+	count, err := r.queries.CountPhotosByCustomFields(ctx, db.CountPhotosByCustomFieldsParams{
+		UserID: userID,
+		Filter: document,
+	})
+	if err != nil {
+		return nil, 0, apperrors.NewWithFormat(apperrors.InternalServer, "failed to count photos by custom fields: %v", err)
+	}
+
+	return toDomainPhotos(photos), int(count), nil
+}
+
+func (r *PhotoRepository) GetPlaces(ctx context.Context, userID uuid.UUID, query string, limit int) ([]*domain.PlaceGroup, error) {
+	rows, err := r.queries.ListPlacesByUserID(ctx, db.ListPlacesByUserIDParams{
+		UserID:   userID,
+		Pattern:  containsPattern(query),
+		PageSize: int32(limit),
+	})
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list places: %v", err)
+	}
+
+	places := make([]*domain.PlaceGroup, len(rows))
+	for i, row := range rows {
+		places[i] = &domain.PlaceGroup{
+			Name:        row.PlaceName,
+			CountryCode: row.CountryCode,
+			CountryName: row.CountryName,
+			PhotoCount:  int(row.PhotoCount),
+			PhotoIDs:    row.PhotoIds,
+		}
+	}
+
+	return places, nil
+}
+
+func (r *PhotoRepository) GetUnalbumedPlaces(ctx context.Context, userID uuid.UUID, minPhotos, limit int) ([]*domain.PlaceGroup, error) {
+	rows, err := r.queries.ListUnalbumedPlacesByUserID(ctx, db.ListUnalbumedPlacesByUserIDParams{
+		UserID:    userID,
+		MinPhotos: int64(minPhotos),
+		PageSize:  int32(limit),
+	})
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list unalbumed places: %v", err)
+	}
+
+	places := make([]*domain.PlaceGroup, len(rows))
+	for i, row := range rows {
+		places[i] = &domain.PlaceGroup{
+			Name:        row.PlaceName,
+			CountryCode: row.CountryCode,
+			CountryName: row.CountryName,
+			PhotoCount:  int(row.PhotoCount),
+			PhotoIDs:    row.PhotoIds,
+		}
+	}
+
+	return places, nil
+}
+
+func (r *PhotoRepository) GetMissingPlace(ctx context.Context, afterID uuid.UUID, limit int) ([]*domain.Photo, error) {
+	photos, err := r.queries.ListPhotosMissingPlace(ctx, db.ListPhotosMissingPlaceParams{
+		AfterID:  afterID,
+		PageSize: int32(limit),
+	})
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list photos missing a place: %v", err)
+	}
+
+	return toDomainPhotos(photos), nil
+}
+
+func (r *PhotoRepository) GetDateBuckets(ctx context.Context, userID uuid.UUID, granularity, timeZone string) ([]*domain.DateBucket, error) {
+	rows, err := r.queries.CountPhotosByDateBucket(ctx, db.CountPhotosByDateBucketParams{
+		Granularity: granularity,
+		TimeZone:    timeZone,
+		UserID:      userID,
+	})
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to count photos by date: %v", err)
+	}
+
+	buckets := make([]*domain.DateBucket, len(rows))
+	for i, row := range rows {
+		buckets[i] = &domain.DateBucket{
+			Start: TimestamptzToTime(row.Bucket),
+			Count: int(row.Count),
+		}
+	}
+
+	return buckets, nil
+}
+
+func (r *PhotoRepository) CountSince(ctx context.Context, userID uuid.UUID, since time.Time) (int, error) {
+	count, err := r.queries.CountPhotosTakenSince(ctx, db.CountPhotosTakenSinceParams{
+		UserID: userID,
+		Since:  TimeToTimestamptz(since),
+	})
+	if err != nil {
+		return 0, apperrors.NewWithFormat(apperrors.InternalServer, "failed to count photos: %v", err)
+	}
+
+	return int(count), nil
+}
+
+func (r *PhotoRepository) SetAlbum(ctx context.Context, photo *domain.Photo) error {
+	updated, err := r.queries.UpdatePhotoAlbum(ctx, db.UpdatePhotoAlbumParams{
+		ID:        photo.ID,
+		AlbumID:   photo.AlbumID,
+		UpdatedAt: TimeToTimestamptz(photo.UpdatedAt),
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return apperrors.NewWithFormat(apperrors.NotFound, "photo with id %s not found", photo.ID)
 		}
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to update photo album: %v", err)
 	}
 
-	return result, int(count), nil
+	photo.Version = int(updated.Version)
+	return nil
 }
 
+// AddTags merges tags into the tags of photo and stores the result on photo.
+func (r *PhotoRepository) AddTags(ctx context.Context, photo *domain.Photo, tags []string) error {
+	updated, err := r.queries.AddPhotoTags(ctx, db.AddPhotoTagsParams{
+		ID:        photo.ID,
+		Tags:      tags,
+		UpdatedAt: TimeToTimestamptz(photo.UpdatedAt),
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return apperrors.NewWithFormat(apperrors.NotFound, "photo with id %s not found", photo.ID)
+		}
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to add photo tags: %v", err)
+	}
+
+	photo.Tags = updated.Tags
+	photo.Version = int(updated.Version)
+	return nil
+}
+
+// Update saves the metadata of photo only if the stored photo still has
+// photo.Version, and advances photo.Version on success. A photo changed in
+// the meantime yields a PreconditionFailed error.
 func (r *PhotoRepository) Update(ctx context.Context, photo *domain.Photo) error {
-	_, err := r.queries.UpdatePhoto(ctx, db.UpdatePhotoParams{
+	customFields, err := CustomFieldsToJSON(photo.CustomFields)
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to encode custom fields: %v", err)
+	}
+
+	updated, err := r.queries.UpdatePhoto(ctx, db.UpdatePhotoParams{
+		ID:           photo.ID,
+		Title:        photo.Title,
+		Description:  pgtype.Text{String: photo.Description, Valid: photo.Description != ""},
+		Visibility:   string(photo.Visibility),
+		Latitude:     FloatPtrToPgFloat8(photo.Latitude),
+		Longitude:    FloatPtrToPgFloat8(photo.Longitude),
+		PlaceName:    pgtype.Text{String: photo.PlaceName, Valid: photo.PlaceName != ""},
+		CountryCode:  pgtype.Text{String: photo.CountryCode, Valid: photo.CountryCode != ""},
+		CountryName:  pgtype.Text{String: photo.CountryName, Valid: photo.CountryName != ""},
+		TakenAt:      TimePtrToTimestamptz(photo.TakenAt),
+		CustomFields: customFields,
+		UpdatedAt:    TimeToTimestamptz(photo.UpdatedAt),
+		Version:      int32(photo.Version),
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			_, getErr := r.GetByID(ctx, photo.ID)
+			if getErr != nil {
+				return getErr
+			}
+			return apperrors.NewWithFormat(apperrors.PreconditionFailed, "photo with id %s has been modified", photo.ID)
+		}
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to update photo: %v", err)
+	}
+
+	photo.Version = int(updated.Version)
+	return nil
+}
+
+// UpdateStorageInfo saves the content of photo under the same version check
+// as Update.
+func (r *PhotoRepository) UpdateStorageInfo(ctx context.Context, photo *domain.Photo) error {
+	updated, err := r.queries.UpdatePhotoStorageInfo(ctx, db.UpdatePhotoStorageInfoParams{
 		ID:          photo.ID,
-		Title:       photo.Title,
-		Description: pgtype.Text{String: photo.Description, Valid: photo.Description != ""},
+		FileName:    photo.FileName,
+		FileSize:    photo.FileSize,
+		ContentType: photo.ContentType,
+		StoragePath: photo.StoragePath,
+		PublicUrl:   pgtype.Text{String: photo.PublicURL, Valid: photo.PublicURL != ""},
+		UpdatedAt:   TimeToTimestamptz(photo.UpdatedAt),
+		Version:     int32(photo.Version),
+	})
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			_, getErr := r.GetByID(ctx, photo.ID)
+			if getErr != nil {
+				return getErr
+			}
+			return apperrors.NewWithFormat(apperrors.PreconditionFailed, "photo with id %s has been modified", photo.ID)
+		}
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to update photo storage info: %v", err)
+	}
+
+	photo.Version = int(updated.Version)
+	return nil
+}
+
+func (r *PhotoRepository) UpdatePlace(ctx context.Context, photo *domain.Photo) error {
+	updated, err := r.queries.UpdatePhotoPlace(ctx, db.UpdatePhotoPlaceParams{
+		ID:          photo.ID,
+		PlaceName:   pgtype.Text{String: photo.PlaceName, Valid: photo.PlaceName != ""},
+		CountryCode: pgtype.Text{String: photo.CountryCode, Valid: photo.CountryCode != ""},
+		CountryName: pgtype.Text{String: photo.CountryName, Valid: photo.CountryName != ""},
 		UpdatedAt:   TimeToTimestamptz(photo.UpdatedAt),
 	})
 
@@ -119,9 +496,10 @@ func (r *PhotoRepository) Update(ctx context.Context, photo *domain.Photo) error
 		if errors.Is(err, pgx.ErrNoRows) {
 			return apperrors.NewWithFormat(apperrors.NotFound, "photo with id %s not found", photo.ID)
 		}
-		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to update photo: %v", err)
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to update photo place: %v", err)
 	}
 
+	photo.Version = int(updated.Version)
 	return nil
 }
 
@@ -134,6 +512,82 @@ func (r *PhotoRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// CreateVersion stores version with the next version number of its photo
+// and sets VersionNumber accordingly.
+func (r *PhotoRepository) CreateVersion(ctx context.Context, version *domain.PhotoVersion) error {
+	created, err := r.queries.CreatePhotoVersion(ctx, db.CreatePhotoVersionParams{
+		ID:          version.ID,
+		PhotoID:     version.PhotoID,
+		FileName:    version.FileName,
+		FileSize:    version.FileSize,
+		ContentType: version.ContentType,
+		StoragePath: version.StoragePath,
+		PublicUrl:   pgtype.Text{String: version.PublicURL, Valid: version.PublicURL != ""},
+		CreatedAt:   TimeToTimestamptz(version.CreatedAt),
+	})
+
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to create photo version: %v", err)
+	}
+
+	version.VersionNumber = int(created.VersionNumber)
+	return nil
+}
+
+func (r *PhotoRepository) GetVersion(ctx context.Context, photoID uuid.UUID, versionNumber int) (*domain.PhotoVersion, error) {
+	version, err := r.queries.GetPhotoVersion(ctx, db.GetPhotoVersionParams{
+		PhotoID:       photoID,
+		VersionNumber: int32(versionNumber),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperrors.NewWithFormat(apperrors.NotFound, "version %d of photo %s not found", versionNumber, photoID)
+		}
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to get photo version: %v", err)
+	}
+
+	return toDomainPhotoVersion(version), nil
+}
+
+func (r *PhotoRepository) GetVersions(ctx context.Context, photoID uuid.UUID) ([]*domain.PhotoVersion, error) {
+	versions, err := r.queries.ListPhotoVersionsByPhotoID(ctx, photoID)
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list photo versions: %v", err)
+	}
+
+	return toDomainPhotoVersions(versions), nil
+}
+
+func (r *PhotoRepository) GetVersionsByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.PhotoVersion, error) {
+	versions, err := r.queries.ListPhotoVersionsByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list photo versions: %v", err)
+	}
+
+	return toDomainPhotoVersions(versions), nil
+}
+
+func (r *PhotoRepository) GetVersionsBeyondLimit(ctx context.Context, userID uuid.UUID, keep int) ([]*domain.PhotoVersion, error) {
+	versions, err := r.queries.ListPhotoVersionsBeyondUserLimit(ctx, db.ListPhotoVersionsBeyondUserLimitParams{
+		UserID: userID,
+		Keep:   int32(keep),
+	})
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list photo versions: %v", err)
+	}
+
+	return toDomainPhotoVersions(versions), nil
+}
+
+func (r *PhotoRepository) DeleteVersion(ctx context.Context, id uuid.UUID) error {
+	err := r.queries.DeletePhotoVersion(ctx, id)
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to delete photo version: %v", err)
+	}
+
+	return nil
+}
+
 func (r *PhotoRepository) WithTx(ctx context.Context, txOptions pgx.TxOptions, fn func(repositories.PhotoRepository) error) error {
 	tx, err := r.pool.BeginTx(ctx, txOptions)
 	if err != nil {
@@ -153,3 +607,60 @@ func (r *PhotoRepository) WithTx(ctx context.Context, txOptions pgx.TxOptions, f
 	return tx.Commit(ctx)
 
 }
+
+func toDomainPhoto(photo db.Photo) *domain.Photo {
+	return &domain.Photo{
+		ID:           photo.ID,
+		UserID:       photo.UserID,
+		Title:        photo.Title,
+		Description:  photo.Description.String,
+		FileName:     photo.FileName,
+		FileSize:     photo.FileSize,
+		ContentType:  photo.ContentType,
+		StoragePath:  photo.StoragePath,
+		PublicURL:    photo.PublicUrl.String,
+		Visibility:   domain.Visibility(photo.Visibility),
+		AlbumID:      photo.AlbumID,
+		Tags:         photo.Tags,
+		Latitude:     PgFloat8ToFloatPtr(photo.Latitude),
+		Longitude:    PgFloat8ToFloatPtr(photo.Longitude),
+		PlaceName:    photo.PlaceName.String,
+		CountryCode:  photo.CountryCode.String,
+		CountryName:  photo.CountryName.String,
+		TakenAt:      TimestamptzToTimePtr(photo.TakenAt),
+		CustomFields: JSONToCustomFields(photo.CustomFields),
+		Version:      int(photo.Version),
+		CreatedAt:    TimestamptzToTime(photo.CreatedAt),
+		UpdatedAt:    TimestamptzToTime(photo.UpdatedAt),
+	}
+}
+
+func toDomainPhotos(photos []db.Photo) []*domain.Photo {
+	result := make([]*domain.Photo, len(photos))
+	for i, photo := range photos {
+		result[i] = toDomainPhoto(photo)
+	}
+	return result
+}
+
+func toDomainPhotoVersion(version db.PhotoVersion) *domain.PhotoVersion {
+	return &domain.PhotoVersion{
+		ID:            version.ID,
+		PhotoID:       version.PhotoID,
+		VersionNumber: int(version.VersionNumber),
+		FileName:      version.FileName,
+		FileSize:      version.FileSize,
+		ContentType:   version.ContentType,
+		StoragePath:   version.StoragePath,
+		PublicURL:     version.PublicUrl.String,
+		CreatedAt:     TimestamptzToTime(version.CreatedAt),
+	}
+}
+
+func toDomainPhotoVersions(versions []db.PhotoVersion) []*domain.PhotoVersion {
+	result := make([]*domain.PhotoVersion, len(versions))
+	for i, version := range versions {
+		result[i] = toDomainPhotoVersion(version)
+	}
+	return result
+}
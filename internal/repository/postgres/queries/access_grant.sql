@@ -0,0 +1,47 @@
+-- name: UpsertPhotoGrant :one
+INSERT INTO access_grants (id, photo_id, grantee_id, granted_by, role, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (photo_id, grantee_id) WHERE photo_id IS NOT NULL
+DO UPDATE SET role = EXCLUDED.role, granted_by = EXCLUDED.granted_by
+RETURNING *;
+
+-- name: UpsertAlbumGrant :one
+INSERT INTO access_grants (id, album_id, grantee_id, granted_by, role, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (album_id, grantee_id) WHERE album_id IS NOT NULL
+DO UPDATE SET role = EXCLUDED.role, granted_by = EXCLUDED.granted_by
+RETURNING *;
+
+-- name: GetGrantByID :one
+SELECT * FROM access_grants
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListGrantsByPhotoID :many
+SELECT * FROM access_grants
+WHERE photo_id = $1
+ORDER BY created_at DESC;
+
+-- name: ListGrantsByAlbumID :many
+SELECT * FROM access_grants
+WHERE album_id = $1
+ORDER BY created_at DESC;
+
+-- name: ListGrantsByUserID :many
+SELECT * FROM access_grants
+WHERE granted_by = sqlc.arg(user_id) OR grantee_id = sqlc.arg(user_id)
+ORDER BY created_at DESC;
+
+-- name: ListPhotoRolesForUser :many
+SELECT role FROM access_grants
+WHERE grantee_id = sqlc.arg(grantee_id)
+  AND (photo_id = sqlc.arg(photo_id) OR album_id = sqlc.narg(album_id));
+
+-- name: GetAlbumRoleForUser :one
+SELECT role FROM access_grants
+WHERE grantee_id = $1 AND album_id = $2
+LIMIT 1;
+
+-- name: DeleteGrant :exec
+DELETE FROM access_grants
+WHERE id = $1;
@@ -0,0 +1,34 @@
+-- name: CreateAlbum :one
+INSERT INTO albums (id, user_id, title, description, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetAlbumByID :one
+SELECT * FROM albums
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListAlbumsByUserID :many
+SELECT * FROM albums
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: ListAlbumsSharedWithUser :many
+SELECT * FROM albums
+WHERE id IN (
+    SELECT album_id FROM access_grants
+    WHERE grantee_id = $1 AND album_id IS NOT NULL
+)
+ORDER BY created_at DESC;
+
+-- name: UpdateAlbum :one
+UPDATE albums
+SET title = $2,
+    description = $3,
+    updated_at = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteAlbum :exec
+DELETE FROM albums
+WHERE id = $1;
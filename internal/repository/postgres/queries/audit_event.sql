@@ -0,0 +1,14 @@
+-- name: CreateAuditEvent :exec
+INSERT INTO audit_events (id, actor_id, action, subject_type, subject_id, details, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: ListAuditEventsBySubject :many
+SELECT * FROM audit_events
+WHERE subject_type = $1 AND subject_id = $2
+ORDER BY created_at;
+
+-- name: ListAuditEventsByUserID :many
+SELECT * FROM audit_events
+WHERE actor_id = sqlc.arg(user_id)
+   OR (subject_type = 'user' AND subject_id = sqlc.arg(user_id))
+ORDER BY created_at;
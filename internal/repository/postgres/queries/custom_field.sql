@@ -0,0 +1,38 @@
+-- name: CreateCustomFieldDefinition :one
+INSERT INTO custom_field_definitions (id, user_id, name, type, required, allowed_values, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetCustomFieldDefinitionByID :one
+SELECT * FROM custom_field_definitions
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListCustomFieldDefinitionsByUserID :many
+SELECT * FROM custom_field_definitions
+WHERE user_id = $1
+ORDER BY name;
+
+-- name: CountCustomFieldDefinitionsByUserID :one
+SELECT COUNT(*) FROM custom_field_definitions
+WHERE user_id = $1;
+
+-- name: UpdateCustomFieldDefinition :one
+UPDATE custom_field_definitions
+SET required = $2,
+    allowed_values = $3,
+    updated_at = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteCustomFieldDefinition :exec
+DELETE FROM custom_field_definitions
+WHERE id = $1;
+
+-- name: RemovePhotoCustomField :exec
+UPDATE photos
+SET custom_fields = custom_fields - sqlc.arg(name)::text,
+    updated_at = sqlc.arg(updated_at),
+    version = version + 1
+WHERE user_id = sqlc.arg(user_id)
+  AND custom_fields ? sqlc.arg(name)::text;
@@ -0,0 +1,40 @@
+-- name: CreateExportJob :one
+INSERT INTO export_jobs (id, user_id, kind, status, photo_ids, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetExportJobByID :one
+SELECT * FROM export_jobs
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetLatestExportJobByKind :one
+SELECT * FROM export_jobs
+WHERE user_id = $1 AND kind = $2
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: UpdateExportJob :one
+UPDATE export_jobs
+SET status = $2,
+    storage_path = $3,
+    error = $4,
+    expires_at = $5,
+    updated_at = $6,
+    photo_ids = $7
+WHERE id = $1
+RETURNING *;
+
+-- name: FailStaleExportJobs :many
+UPDATE export_jobs
+SET status = 'failed',
+    error = sqlc.arg(error),
+    updated_at = sqlc.arg(updated_at)
+WHERE status IN ('pending', 'running') AND created_at < sqlc.arg(stale_before)
+RETURNING *;
+
+-- name: ListExpiredExportJobs :many
+SELECT * FROM export_jobs
+WHERE status = 'completed' AND expires_at < sqlc.arg(now)
+ORDER BY expires_at
+LIMIT sqlc.arg(page_size);
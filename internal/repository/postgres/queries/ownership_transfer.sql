@@ -0,0 +1,82 @@
+-- name: CreateOwnershipTransfer :one
+INSERT INTO ownership_transfers (id, from_user_id, to_user_id, status, selection, photo_ids, album_ids, expires_at, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: GetOwnershipTransferByID :one
+SELECT * FROM ownership_transfers
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListOwnershipTransfersByUserID :many
+SELECT * FROM ownership_transfers
+WHERE from_user_id = sqlc.arg(user_id) OR to_user_id = sqlc.arg(user_id)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: UpdateOwnershipTransferStatus :one
+UPDATE ownership_transfers
+SET status = sqlc.arg(status),
+    photo_count = sqlc.arg(photo_count),
+    error = sqlc.narg(error),
+    completed_at = sqlc.narg(completed_at),
+    updated_at = sqlc.arg(updated_at)
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(current_status)
+RETURNING *;
+
+-- name: FailStaleOwnershipTransfers :many
+UPDATE ownership_transfers
+SET status = 'failed',
+    error = sqlc.arg(error),
+    updated_at = sqlc.arg(updated_at)
+WHERE status = 'running' AND updated_at < sqlc.arg(stale_before)
+RETURNING *;
+
+-- name: TransferPhoto :one
+UPDATE photos
+SET user_id = sqlc.arg(user_id),
+    storage_path = sqlc.arg(storage_path),
+    public_URL = sqlc.arg(public_url),
+    album_id = sqlc.narg(album_id),
+    updated_at = sqlc.arg(updated_at),
+    version = version + 1
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(from_user_id) AND version = sqlc.arg(version)
+RETURNING *;
+
+-- name: TransferPhotoVersion :exec
+UPDATE photo_versions
+SET storage_path = $2,
+    public_url = $3
+WHERE id = $1;
+
+-- name: TransferAlbum :execrows
+UPDATE albums
+SET user_id = sqlc.arg(user_id),
+    updated_at = sqlc.arg(updated_at)
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(from_user_id);
+
+-- name: DeleteGrantsToUser :exec
+DELETE FROM access_grants
+WHERE grantee_id = sqlc.arg(user_id)
+  AND (photo_id = ANY(sqlc.arg(photo_ids)::uuid[]) OR album_id = ANY(sqlc.arg(album_ids)::uuid[]));
+
+-- name: TransferGrants :exec
+UPDATE access_grants
+SET granted_by = sqlc.arg(user_id)
+WHERE photo_id = ANY(sqlc.arg(photo_ids)::uuid[]) OR album_id = ANY(sqlc.arg(album_ids)::uuid[]);
+
+-- name: TransferShareLinks :exec
+UPDATE share_links
+SET user_id = sqlc.arg(user_id)
+WHERE photo_id = ANY(sqlc.arg(photo_ids)::uuid[]);
+
+-- name: CopyCustomFieldDefinitions :exec
+INSERT INTO custom_field_definitions (id, user_id, name, type, required, allowed_values, created_at, updated_at)
+SELECT uuid_generate_v4(), sqlc.arg(to_user_id)::uuid, d.name, d.type, FALSE, d.allowed_values, sqlc.arg(created_at)::timestamptz, sqlc.arg(created_at)::timestamptz
+FROM custom_field_definitions d
+WHERE d.user_id = sqlc.arg(from_user_id)
+  AND EXISTS (
+      SELECT 1 FROM photos p
+      WHERE p.id = ANY(sqlc.arg(photo_ids)::uuid[]) AND p.custom_fields ? d.name
+  )
+ON CONFLICT (user_id, name) DO NOTHING;
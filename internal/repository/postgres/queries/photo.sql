@@ -1,6 +1,6 @@
 -- name: CreatePhoto :one
-INSERT INTO photos (id, user_id, title, description, file_name, file_size, content_type, storage_path, public_URL, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+INSERT INTO photos (id, user_id, title, description, file_name, file_size, content_type, storage_path, public_URL, visibility, latitude, longitude, place_name, country_code, country_name, taken_at, custom_fields, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 RETURNING *;
 
 -- name: GetPhotoByID :one
@@ -11,29 +11,257 @@ LIMIT 1;
 -- name: ListPhotosByUserID :many
 SELECT * FROM photos
 WHERE user_id = $1
-ORDER BY created_at DESC
+ORDER BY COALESCE(taken_at, created_at) DESC, id DESC
 LIMIT $2 OFFSET $3;
 
 -- name: CountPhotosByUserID :one
 SELECT COUNT(*) FROM photos
 WHERE user_id = $1;
 
+-- name: GetStorageUsageByUserID :one
+SELECT COALESCE(SUM(file_size), 0)::bigint AS storage_used
+FROM (
+    SELECT file_size FROM photos WHERE user_id = sqlc.arg(user_id)
+    UNION ALL
+    SELECT v.file_size FROM photo_versions v
+    JOIN photos p ON p.id = v.photo_id
+    WHERE p.user_id = sqlc.arg(user_id)
+) AS files;
+
+-- name: ListPublicPhotosByUserID :many
+SELECT * FROM photos
+WHERE user_id = $1 AND visibility = 'public'
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountPublicPhotosByUserID :one
+SELECT COUNT(*) FROM photos
+WHERE user_id = $1 AND visibility = 'public';
+
+-- name: ListPublicPhotos :many
+SELECT * FROM photos
+WHERE visibility = 'public'
+  AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+       OR (created_at, id) < (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: ListPhotosByAlbumID :many
+SELECT * FROM photos
+WHERE album_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountPhotosByAlbumID :one
+SELECT COUNT(*) FROM photos
+WHERE album_id = $1;
+
+-- name: ListPhotosSharedWithUser :many
+SELECT * FROM photos
+WHERE id IN (
+    SELECT photo_id FROM access_grants
+    WHERE grantee_id = sqlc.arg(grantee_id) AND photo_id IS NOT NULL
+) OR album_id IN (
+    SELECT album_id FROM access_grants
+    WHERE grantee_id = sqlc.arg(grantee_id) AND album_id IS NOT NULL
+)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: CountPhotosSharedWithUser :one
+SELECT COUNT(*) FROM photos
+WHERE id IN (
+    SELECT photo_id FROM access_grants
+    WHERE grantee_id = $1 AND photo_id IS NOT NULL
+) OR album_id IN (
+    SELECT album_id FROM access_grants
+    WHERE grantee_id = $1 AND album_id IS NOT NULL
+);
+
 -- name: UpdatePhoto :one
 UPDATE photos
 SET title = $2,
     description = $3,
-    updated_at = $4
-WHERE id = $1
+    visibility = $4,
+    latitude = $5,
+    longitude = $6,
+    place_name = $7,
+    country_code = $8,
+    country_name = $9,
+    taken_at = $10,
+    custom_fields = $11,
+    updated_at = $12,
+    version = version + 1
+WHERE id = $1 AND version = $13
 RETURNING *;
 
 -- name: UpdatePhotoStorageInfo :one
 UPDATE photos
-SET storage_path = $2,
-    public_URL = $3,
-    updated_at = $4
+SET file_name = $2,
+    file_size = $3,
+    content_type = $4,
+    storage_path = $5,
+    public_URL = $6,
+    updated_at = $7,
+    version = version + 1
+WHERE id = $1 AND version = $8
+RETURNING *;
+
+-- name: UpdatePhotoAlbum :one
+UPDATE photos
+SET album_id = $2,
+    updated_at = $3,
+    version = version + 1
 WHERE id = $1
 RETURNING *;
 
 -- name: DeletePhoto :exec
 DELETE from photos
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: AddPhotoTags :one
+UPDATE photos
+SET tags = ARRAY(
+        SELECT DISTINCT tag FROM unnest(tags || sqlc.arg(tags)::text[]) AS tag
+        ORDER BY tag
+    ),
+    updated_at = sqlc.arg(updated_at),
+    version = version + 1
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ListPhotosInBoundingBox :many
+SELECT * FROM photos
+WHERE user_id = sqlc.arg(user_id)
+  AND latitude BETWEEN sqlc.arg(min_lat)::float8 AND sqlc.arg(max_lat)::float8
+  AND CASE
+        WHEN sqlc.arg(min_lng)::float8 <= sqlc.arg(max_lng)::float8
+            THEN longitude BETWEEN sqlc.arg(min_lng)::float8 AND sqlc.arg(max_lng)::float8
+        ELSE longitude >= sqlc.arg(min_lng)::float8 OR longitude <= sqlc.arg(max_lng)::float8
+      END
+ORDER BY created_at DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: ListPhotosNear :many
+SELECT * FROM photos
+WHERE user_id = sqlc.arg(user_id)
+  AND latitude BETWEEN sqlc.arg(min_lat)::float8 AND sqlc.arg(max_lat)::float8
+  AND CASE
+        WHEN sqlc.arg(min_lng)::float8 <= sqlc.arg(max_lng)::float8
+            THEN longitude BETWEEN sqlc.arg(min_lng)::float8 AND sqlc.arg(max_lng)::float8
+        ELSE longitude >= sqlc.arg(min_lng)::float8 OR longitude <= sqlc.arg(max_lng)::float8
+      END
+  AND 12742000 * asin(sqrt(
+        power(sin(radians(latitude - sqlc.arg(lat)::float8) / 2), 2)
+        + cos(radians(sqlc.arg(lat)::float8)) * cos(radians(latitude))
+        * power(sin(radians(longitude - sqlc.arg(lng)::float8) / 2), 2)
+      )) <= sqlc.arg(radius_meters)::float8
+ORDER BY power(sin(radians(latitude - sqlc.arg(lat)::float8) / 2), 2)
+        + cos(radians(sqlc.arg(lat)::float8)) * cos(radians(latitude))
+        * power(sin(radians(longitude - sqlc.arg(lng)::float8) / 2), 2)
+LIMIT sqlc.arg(page_size);
+
+-- name: ClusterPhotosInBoundingBox :many
+SELECT
+    floor(longitude / sqlc.arg(cell_size)::float8)::bigint AS cell_x,
+    floor(latitude / sqlc.arg(cell_size)::float8)::bigint AS cell_y,
+    COUNT(*) AS count,
+    AVG(latitude)::float8 AS latitude,
+    AVG(longitude)::float8 AS longitude,
+    (array_agg(id ORDER BY created_at DESC))[1]::uuid AS photo_id
+FROM photos
+WHERE user_id = sqlc.arg(user_id)
+  AND latitude BETWEEN sqlc.arg(min_lat)::float8 AND sqlc.arg(max_lat)::float8
+  AND CASE
+        WHEN sqlc.arg(min_lng)::float8 <= sqlc.arg(max_lng)::float8
+            THEN longitude BETWEEN sqlc.arg(min_lng)::float8 AND sqlc.arg(max_lng)::float8
+        ELSE longitude >= sqlc.arg(min_lng)::float8 OR longitude <= sqlc.arg(max_lng)::float8
+      END
+GROUP BY cell_x, cell_y;
+
+-- name: UpdatePhotoPlace :one
+UPDATE photos
+SET place_name = $2,
+    country_code = $3,
+    country_name = $4,
+    updated_at = $5,
+    version = version + 1
+WHERE id = $1
+RETURNING *;
+
+-- name: ListPhotosMissingPlace :many
+SELECT * FROM photos
+WHERE latitude IS NOT NULL
+  AND place_name IS NULL
+  AND id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(page_size);
+
+-- name: ListPhotosByPlace :many
+SELECT * FROM photos
+WHERE user_id = sqlc.arg(user_id)
+  AND (place_name ILIKE sqlc.arg(pattern)::text OR country_name ILIKE sqlc.arg(pattern)::text)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: CountPhotosByPlace :one
+SELECT COUNT(*) FROM photos
+WHERE user_id = sqlc.arg(user_id)
+  AND (place_name ILIKE sqlc.arg(pattern)::text OR country_name ILIKE sqlc.arg(pattern)::text);
+
+-- name: ListPlacesByUserID :many
+SELECT
+    place_name::text AS place_name,
+    country_code::text AS country_code,
+    country_name::text AS country_name,
+    COUNT(*) AS photo_count,
+    (array_agg(id ORDER BY created_at DESC))[1:1]::uuid[] AS photo_ids
+FROM photos
+WHERE user_id = sqlc.arg(user_id)
+  AND place_name IS NOT NULL
+  AND (place_name ILIKE sqlc.arg(pattern)::text OR country_name ILIKE sqlc.arg(pattern)::text)
+GROUP BY place_name, country_code, country_name
+ORDER BY photo_count DESC, place_name
+LIMIT sqlc.arg(page_size);
+
+-- name: ListUnalbumedPlacesByUserID :many
+SELECT
+    place_name::text AS place_name,
+    country_code::text AS country_code,
+    country_name::text AS country_name,
+    COUNT(*) AS photo_count,
+    (array_agg(id ORDER BY created_at DESC))[1:100]::uuid[] AS photo_ids
+FROM photos
+WHERE user_id = sqlc.arg(user_id)
+  AND place_name IS NOT NULL
+  AND album_id IS NULL
+GROUP BY place_name, country_code, country_name
+HAVING COUNT(*) >= sqlc.arg(min_photos)::bigint
+ORDER BY photo_count DESC, place_name
+LIMIT sqlc.arg(page_size);
+
+-- name: CountPhotosByDateBucket :many
+SELECT
+    date_trunc(sqlc.arg(granularity)::text, COALESCE(taken_at, created_at), sqlc.arg(time_zone)::text)::timestamptz AS bucket,
+    COUNT(*) AS count
+FROM photos
+WHERE user_id = sqlc.arg(user_id)
+GROUP BY bucket
+ORDER BY bucket DESC;
+
+-- name: CountPhotosTakenSince :one
+SELECT COUNT(*) FROM photos
+WHERE user_id = sqlc.arg(user_id)
+  AND COALESCE(taken_at, created_at) >= sqlc.arg(since)::timestamptz;
+
+-- name: ListPhotosByCustomFields :many
+SELECT * FROM photos
+WHERE user_id = sqlc.arg(user_id)
+  AND custom_fields @> sqlc.arg(filter)::jsonb
+ORDER BY COALESCE(taken_at, created_at) DESC, id DESC
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: CountPhotosByCustomFields :one
+SELECT COUNT(*) FROM photos
+WHERE user_id = sqlc.arg(user_id)
+  AND custom_fields @> sqlc.arg(filter)::jsonb;
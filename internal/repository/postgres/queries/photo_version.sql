@@ -0,0 +1,42 @@
+-- name: CreatePhotoVersion :one
+INSERT INTO photo_versions (id, photo_id, version_number, file_name, file_size, content_type, storage_path, public_url, created_at)
+VALUES (
+    sqlc.arg(id),
+    sqlc.arg(photo_id),
+    (SELECT COALESCE(MAX(version_number), 0) + 1 FROM photo_versions WHERE photo_id = sqlc.arg(photo_id)),
+    sqlc.arg(file_name),
+    sqlc.arg(file_size),
+    sqlc.arg(content_type),
+    sqlc.arg(storage_path),
+    sqlc.narg(public_url),
+    sqlc.arg(created_at)
+)
+RETURNING *;
+
+-- name: GetPhotoVersion :one
+SELECT * FROM photo_versions
+WHERE photo_id = $1 AND version_number = $2
+LIMIT 1;
+
+-- name: ListPhotoVersionsByPhotoID :many
+SELECT * FROM photo_versions
+WHERE photo_id = $1
+ORDER BY version_number DESC;
+
+-- name: ListPhotoVersionsByUserID :many
+SELECT * FROM photo_versions
+WHERE photo_id IN (
+    SELECT id FROM photos WHERE user_id = $1
+);
+
+-- name: ListPhotoVersionsBeyondUserLimit :many
+SELECT * FROM photo_versions
+WHERE photo_id IN (
+    SELECT id FROM photos WHERE user_id = sqlc.arg(user_id)
+)
+ORDER BY created_at DESC, version_number DESC
+OFFSET sqlc.arg(keep);
+
+-- name: DeletePhotoVersion :exec
+DELETE FROM photo_versions
+WHERE id = $1;
@@ -0,0 +1,36 @@
+-- name: CreateShareLink :one
+INSERT INTO share_links (id, token, photo_id, user_id, password_hash, expires_at, max_views, allow_download, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetShareLinkByID :one
+SELECT * FROM share_links
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetShareLinkByToken :one
+SELECT * FROM share_links
+WHERE token = $1
+LIMIT 1;
+
+-- name: ListShareLinksByPhotoID :many
+SELECT * FROM share_links
+WHERE photo_id = $1
+ORDER BY created_at DESC;
+
+-- name: ListShareLinksByUserID :many
+SELECT * FROM share_links
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: IncrementShareLinkViewCount :one
+UPDATE share_links
+SET view_count = view_count + 1
+WHERE id = $1
+  AND (max_views IS NULL OR view_count < max_views)
+  AND (expires_at IS NULL OR expires_at > NOW())
+RETURNING *;
+
+-- name: DeleteShareLink :exec
+DELETE FROM share_links
+WHERE id = $1;
@@ -1,7 +1,7 @@
 
 -- name: CreateUser :one
-INSERT INTO users (id, username, email, password_hash, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO users (id, username, email, password_hash, time_zone, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING *;
 
 -- name: GetUserByEmail :one
@@ -23,7 +23,9 @@ LIMIT 1;
 UPDATE users
 SET username = $2,
     email = $3,
-    updated_at = $4
+    time_zone = $4,
+    email_verified_at = $5,
+    updated_at = $6
 WHERE id = $1
 RETURNING *;
 
@@ -35,4 +37,37 @@ WHERE id = $1;
 
 -- name: DeleteUser :exec
 DELETE FROM users
-WHERE id = $1; 
\ No newline at end of file
+WHERE id = $1;
+
+-- name: ScheduleUserDeletion :exec
+UPDATE users
+SET deletion_scheduled_at = $2,
+    updated_at = $3
+WHERE id = $1;
+
+-- name: CancelUserDeletion :execrows
+UPDATE users
+SET deletion_scheduled_at = NULL,
+    erasure_started_at = NULL,
+    updated_at = sqlc.arg(now)
+WHERE id = sqlc.arg(id) AND deletion_scheduled_at > sqlc.arg(now);
+
+-- name: ClaimUsersForErasure :many
+UPDATE users
+SET erasure_started_at = sqlc.arg(now)
+WHERE id IN (
+    SELECT id FROM users
+    WHERE deletion_scheduled_at <= sqlc.arg(now)
+      AND (erasure_started_at IS NULL OR erasure_started_at < sqlc.arg(stale_before))
+    ORDER BY deletion_scheduled_at
+    LIMIT sqlc.arg(page_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: UpdateUserTOTP :exec
+UPDATE users
+SET totp_secret = $2,
+    totp_enabled_at = $3,
+    updated_at = $4
+WHERE id = $1;
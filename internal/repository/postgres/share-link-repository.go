@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mmd-moradi/goup/internal/domain"
+	"github.com/mmd-moradi/goup/internal/repository/postgres/db"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+)
+
+type ShareLinkRepository struct {
+	queries *db.Queries
+	pool    *pgxpool.Pool
+}
+
+func NewShareLinkRepository(pool *pgxpool.Pool) *ShareLinkRepository {
+	return &ShareLinkRepository{
+		queries: db.New(pool),
+		pool:    pool,
+	}
+}
+
+func (r *ShareLinkRepository) Create(ctx context.Context, link *domain.ShareLink) error {
+	_, err := r.queries.CreateShareLink(ctx, db.CreateShareLinkParams{
+		ID:            link.ID,
+		Token:         link.Token,
+		PhotoID:       link.PhotoID,
+		UserID:        link.UserID,
+		PasswordHash:  pgtype.Text{String: link.PasswordHash, Valid: link.PasswordHash != ""},
+		ExpiresAt:     TimePtrToTimestamptz(link.ExpiresAt),
+		MaxViews:      IntPtrToPgInt4(link.MaxViews),
+		AllowDownload: link.AllowDownload,
+		CreatedAt:     TimeToTimestamptz(link.CreatedAt),
+	})
+
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to create share link: %v", err)
+	}
+
+	return nil
+}
+
+func (r *ShareLinkRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.ShareLink, error) {
+	link, err := r.queries.GetShareLinkByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperrors.NewWithFormat(apperrors.NotFound, "share link with id %s not found", id)
+		}
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to get share link: %v", err)
+	}
+
+	return toDomainShareLink(link), nil
+}
+
+func (r *ShareLinkRepository) GetByToken(ctx context.Context, token string) (*domain.ShareLink, error) {
+	link, err := r.queries.GetShareLinkByToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperrors.New(apperrors.NotFound, "share link not found")
+		}
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to get share link: %v", err)
+	}
+
+	return toDomainShareLink(link), nil
+}
+
+func (r *ShareLinkRepository) ListByPhotoID(ctx context.Context, photoID uuid.UUID) ([]*domain.ShareLink, error) {
+	links, err := r.queries.ListShareLinksByPhotoID(ctx, photoID)
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list share links: %v", err)
+	}
+
+	result := make([]*domain.ShareLink, len(links))
+	for i, link := range links {
+		result[i] = toDomainShareLink(link)
+	}
+
+	return result, nil
+}
+
+func (r *ShareLinkRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.ShareLink, error) {
+	links, err := r.queries.ListShareLinksByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list share links: %v", err)
+	}
+
+	result := make([]*domain.ShareLink, len(links))
+	for i, link := range links {
+		result[i] = toDomainShareLink(link)
+	}
+
+	return result, nil
+}
+
+// IncrementViewCount records a view and returns the updated link. It fails with
+// Forbidden when the link is expired or has no views left, so concurrent
+// viewers can never exceed max_views.
+func (r *ShareLinkRepository) IncrementViewCount(ctx context.Context, id uuid.UUID) (*domain.ShareLink, error) {
+	link, err := r.queries.IncrementShareLinkViewCount(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperrors.New(apperrors.Forbidden, "share link has expired or reached its view limit")
+		}
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to record share link view: %v", err)
+	}
+
+	return toDomainShareLink(link), nil
+}
+
+func (r *ShareLinkRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	err := r.queries.DeleteShareLink(ctx, id)
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to delete share link: %v", err)
+	}
+
+	return nil
+}
+
+func toDomainShareLink(link db.ShareLink) *domain.ShareLink {
+	return &domain.ShareLink{
+		ID:            link.ID,
+		Token:         link.Token,
+		PhotoID:       link.PhotoID,
+		UserID:        link.UserID,
+		PasswordHash:  link.PasswordHash.String,
+		ExpiresAt:     TimestamptzToTimePtr(link.ExpiresAt),
+		MaxViews:      PgInt4ToIntPtr(link.MaxViews),
+		ViewCount:     int(link.ViewCount),
+		AllowDownload: link.AllowDownload,
+		CreatedAt:     TimestamptzToTime(link.CreatedAt),
+	}
+}
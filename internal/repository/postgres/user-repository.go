@@ -3,9 +3,12 @@ package postgres
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/mmd-moradi/goup/internal/domain"
 	repositories "github.com/mmd-moradi/goup/internal/repository"
@@ -13,6 +16,9 @@ import (
 	"github.com/mmd-moradi/goup/pkg/apperrors"
 )
 
+// uniqueViolation is the SQLSTATE of a unique constraint violation.
+const uniqueViolation = "23505"
+
 type UserRepository struct {
 	queries *db.Queries
 	pool    *pgxpool.Pool
@@ -31,6 +37,7 @@ func (r *UserRepository) CreateUser(ctx context.Context, user *domain.User) erro
 		Username:     user.Username,
 		Email:        user.Email,
 		PasswordHash: user.PasswordHash,
+		TimeZone:     user.TimeZone,
 		CreatedAt:    TimeToTimestamptz(user.CreatedAt),
 		UpdatedAt:    TimeToTimestamptz(user.UpdatedAt),
 	})
@@ -52,14 +59,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Use
 		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to get user: %v", err)
 	}
 
-	return &domain.User{
-		ID:           user.ID,
-		Username:     user.Username,
-		Email:        user.Email,
-		PasswordHash: user.PasswordHash,
-		CreatedAt:    TimestamptzToTime(user.CreatedAt),
-		UpdatedAt:    TimestamptzToTime(user.UpdatedAt),
-	}, nil
+	return toDomainUser(user), nil
 }
 
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
@@ -71,14 +71,7 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.
 		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to get user: %v", err)
 	}
 
-	return &domain.User{
-		ID:           user.ID,
-		Username:     user.Username,
-		Email:        user.Email,
-		PasswordHash: user.PasswordHash,
-		CreatedAt:    TimestamptzToTime(user.CreatedAt),
-		UpdatedAt:    TimestamptzToTime(user.UpdatedAt),
-	}, nil
+	return toDomainUser(user), nil
 }
 
 func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
@@ -90,34 +83,46 @@ func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*d
 		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to get user: %v", err)
 	}
 
-	return &domain.User{
-		ID:           user.ID,
-		Username:     user.Username,
-		Email:        user.Email,
-		PasswordHash: user.PasswordHash,
-		CreatedAt:    TimestamptzToTime(user.CreatedAt),
-		UpdatedAt:    TimestamptzToTime(user.UpdatedAt),
-	}, nil
+	return toDomainUser(user), nil
 }
 
 func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
 	_, err := r.queries.UpdateUser(ctx, db.UpdateUserParams{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		UpdatedAt: TimeToTimestamptz(user.UpdatedAt),
+		ID:              user.ID,
+		Username:        user.Username,
+		Email:           user.Email,
+		TimeZone:        user.TimeZone,
+		EmailVerifiedAt: TimePtrToTimestamptz(user.EmailVerifiedAt),
+		UpdatedAt:       TimeToTimestamptz(user.UpdatedAt),
 	})
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return apperrors.NewWithFormat(apperrors.NotFound, "user with id %s not found", user.ID)
 		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return apperrors.New(apperrors.Conflict, "username or email already exists")
+		}
 		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to update user: %v", err)
 	}
 
 	return nil
 }
 
+func (r *UserRepository) UpdatePassword(ctx context.Context, user *domain.User) error {
+	err := r.queries.UpdateUserPassword(ctx, db.UpdateUserPasswordParams{
+		ID:           user.ID,
+		PasswordHash: user.PasswordHash,
+		UpdatedAt:    TimeToTimestamptz(user.UpdatedAt),
+	})
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to update user password: %v", err)
+	}
+
+	return nil
+}
+
 func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	err := r.queries.DeleteUser(ctx, id)
 	if err != nil {
@@ -127,6 +132,134 @@ func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+func (r *UserRepository) ScheduleDeletion(ctx context.Context, user *domain.User) error {
+	err := r.queries.ScheduleUserDeletion(ctx, db.ScheduleUserDeletionParams{
+		ID:                  user.ID,
+		DeletionScheduledAt: TimePtrToTimestamptz(user.DeletionScheduledAt),
+		UpdatedAt:           TimeToTimestamptz(user.UpdatedAt),
+	})
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to schedule user deletion: %v", err)
+	}
+
+	return nil
+}
+
+func (r *UserRepository) CancelDeletion(ctx context.Context, id uuid.UUID, now time.Time) error {
+	rows, err := r.queries.CancelUserDeletion(ctx, db.CancelUserDeletionParams{
+		ID:  id,
+		Now: TimeToTimestamptz(now),
+	})
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to cancel user deletion: %v", err)
+	}
+	if rows == 0 {
+		return apperrors.New(apperrors.Conflict, "the account is being deleted")
+	}
+
+	return nil
+}
+
+func (r *UserRepository) ClaimForErasure(ctx context.Context, now, staleBefore time.Time, limit int) ([]*domain.User, error) {
+	users, err := r.queries.ClaimUsersForErasure(ctx, db.ClaimUsersForErasureParams{
+		Now:         TimeToTimestamptz(now),
+		StaleBefore: TimeToTimestamptz(staleBefore),
+		PageSize:    int32(limit),
+	})
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to claim users for erasure: %v", err)
+	}
+
+	result := make([]*domain.User, len(users))
+	for i, user := range users {
+		result[i] = toDomainUser(user)
+	}
+	return result, nil
+}
+
+func (r *UserRepository) SaveTOTP(ctx context.Context, user *domain.User, recoveryCodeHashes []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to start saving two-factor authentication: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	queries := r.queries.WithTx(tx)
+
+	err = queries.UpdateUserTOTP(ctx, db.UpdateUserTOTPParams{
+		ID:            user.ID,
+		TotpSecret:    pgtype.Text{String: user.TOTPSecret, Valid: user.TOTPSecret != ""},
+		TotpEnabledAt: TimePtrToTimestamptz(user.TOTPEnabledAt),
+		UpdatedAt:     TimeToTimestamptz(user.UpdatedAt),
+	})
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to update two-factor authentication: %v", err)
+	}
+
+	if recoveryCodeHashes != nil {
+		err = queries.DeleteRecoveryCodes(ctx, user.ID)
+		if err != nil {
+			return apperrors.NewWithFormat(apperrors.InternalServer, "failed to delete recovery codes: %v", err)
+		}
+		for _, hash := range recoveryCodeHashes {
+			err = queries.CreateRecoveryCode(ctx, db.CreateRecoveryCodeParams{
+				ID:        uuid.New(),
+				UserID:    user.ID,
+				CodeHash:  hash,
+				CreatedAt: TimeToTimestamptz(user.UpdatedAt),
+			})
+			if err != nil {
+				return apperrors.NewWithFormat(apperrors.InternalServer, "failed to create recovery code: %v", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to commit two-factor authentication: %v", err)
+	}
+	return nil
+}
+
+func (r *UserRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string, usedAt time.Time) error {
+	rows, err := r.queries.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{
+		UserID:   userID,
+		CodeHash: codeHash,
+		UsedAt:   TimeToTimestamptz(usedAt),
+	})
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to use recovery code: %v", err)
+	}
+	if rows == 0 {
+		return apperrors.New(apperrors.NotFound, "recovery code not found")
+	}
+
+	return nil
+}
+
+func (r *UserRepository) Erase(ctx context.Context, id uuid.UUID, event *domain.AuditEvent) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to start erasure: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	queries := r.queries.WithTx(tx)
+
+	err = queries.DeleteUser(ctx, id)
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to delete user: %v", err)
+	}
+	err = createAuditEvent(ctx, queries, event)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to commit erasure: %v", err)
+	}
+	return nil
+}
+
 func (r *UserRepository) WithTx(ctx context.Context, txOptions pgx.TxOptions, fn func(repositories.UserRepository) error) error {
 	tx, err := r.pool.BeginTx(ctx, txOptions)
 	if err != nil {
@@ -146,3 +279,19 @@ func (r *UserRepository) WithTx(ctx context.Context, txOptions pgx.TxOptions, fn
 	return tx.Commit(ctx)
 
 }
+
+func toDomainUser(user db.User) *domain.User {
+	return &domain.User{
+		ID:                  user.ID,
+		Username:            user.Username,
+		Email:               user.Email,
+		PasswordHash:        user.PasswordHash,
+		TimeZone:            user.TimeZone,
+		EmailVerifiedAt:     TimestamptzToTimePtr(user.EmailVerifiedAt),
+		DeletionScheduledAt: TimestamptzToTimePtr(user.DeletionScheduledAt),
+		TOTPSecret:          user.TotpSecret.String,
+		TOTPEnabledAt:       TimestamptzToTimePtr(user.TotpEnabledAt),
+		CreatedAt:           TimestamptzToTime(user.CreatedAt),
+		UpdatedAt:           TimestamptzToTime(user.UpdatedAt),
+	}
+}
@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/domain"
+)
+
+type ShareLinkRepository interface {
+	Create(ctx context.Context, link *domain.ShareLink) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.ShareLink, error)
+	GetByToken(ctx context.Context, token string) (*domain.ShareLink, error)
+	ListByPhotoID(ctx context.Context, photoID uuid.UUID) ([]*domain.ShareLink, error)
+	// ListByUserID returns the share links the user created, newest first.
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.ShareLink, error)
+	IncrementViewCount(ctx context.Context, id uuid.UUID) (*domain.ShareLink, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -14,7 +15,27 @@ type UserRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error)
 	GetByUsername(ctx context.Context, username string) (*domain.User, error)
 	Update(ctx context.Context, user *domain.User) error
+	UpdatePassword(ctx context.Context, user *domain.User) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	// ScheduleDeletion saves the DeletionScheduledAt of the user.
+	ScheduleDeletion(ctx context.Context, user *domain.User) error
+	// CancelDeletion clears the scheduled deletion of the user. It fails with
+	// a conflict once the grace period has ended.
+	CancelDeletion(ctx context.Context, id uuid.UUID, now time.Time) error
+	// ClaimForErasure returns up to limit users whose grace period has ended
+	// and marks them as being erased. Users claimed before staleBefore are
+	// returned again, so an erasure that was cut short is retried.
+	ClaimForErasure(ctx context.Context, now, staleBefore time.Time, limit int) ([]*domain.User, error)
+	// SaveTOTP saves the TOTP secret of the user and when it was enabled.
+	// Unless recoveryCodeHashes is nil, the user's recovery codes are
+	// replaced with them in the same transaction.
+	SaveTOTP(ctx context.Context, user *domain.User, recoveryCodeHashes []string) error
+	// UseRecoveryCode marks the unused recovery code of the user with
+	// codeHash as used. It fails with not found if there is none.
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string, usedAt time.Time) error
+	// Erase deletes the user with everything that references them and
+	// records event in the same transaction.
+	Erase(ctx context.Context, id uuid.UUID, event *domain.AuditEvent) error
 
 	WithTx(ctx context.Context, txOption pgx.TxOptions, fn func(UserRepository) error) error
 }
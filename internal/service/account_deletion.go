@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/auth"
+	"github.com/mmd-moradi/goup/internal/domain"
+	repositories "github.com/mmd-moradi/goup/internal/repository"
+	"github.com/mmd-moradi/goup/internal/storage"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/mmd-moradi/goup/pkg/validator"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// accountErasureInterval is how often accounts past their grace period
+	// are looked for.
+	accountErasureInterval = 10 * time.Minute
+	// accountErasureTimeout bounds erasing one account. A claim older than
+	// that is taken to have been cut short and the account is erased again.
+	accountErasureTimeout   = time.Hour
+	accountErasureBatchSize = 10
+)
+
+// Audit actions recorded for account deletions. The erasure record is the
+// only trace of an erased account and holds no personal data.
+const (
+	AuditSubjectUser              = "user"
+	AuditAccountDeletionRequested = "account.deletion_requested"
+	AuditAccountDeletionCanceled  = "account.deletion_canceled"
+	AuditAccountErased            = "account.erased"
+)
+
+type AccountDeleteInput struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type AccountDeletionResponse struct {
+	Message string `json:"message"`
+	// DeletionScheduledAt is when the grace period ends and the account is
+	// erased. Logging in before then cancels the deletion.
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at"`
+}
+
+// DeleteAccount schedules the user's account to be erased once the grace
+// period has passed and logs the user out everywhere.
+func (s *UserService) DeleteAccount(ctx context.Context, userID uuid.UUID, input AccountDeleteInput) (*AccountDeletionResponse, error) {
+	if err := validator.Validate(input); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.BadRequest)
+	}
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !auth.CheckPasswordHash(input.Password, user.PasswordHash) {
+		return nil, apperrors.New(apperrors.Unauthorized, "password is incorrect")
+	}
+
+	now := time.Now()
+	scheduledAt := now.Add(s.deletionGracePeriod)
+	user.DeletionScheduledAt = &scheduledAt
+	user.UpdatedAt = now
+	err = s.repo.ScheduleDeletion(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := s.tokenSvc.RevokeAllSessions(ctx, user.ID, "")
+	if err != nil {
+		return nil, err
+	}
+	err = s.tokenSvc.RevokePasswordResetToken(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, &user.ID, AuditAccountDeletionRequested, user.ID, map[string]interface{}{
+		"deletion_scheduled_at": scheduledAt,
+	})
+
+	s.logger.Info().
+		Str("userID", user.ID.String()).
+		Time("deletionScheduledAt", scheduledAt).
+		Int("revokedSessions", revoked).
+		Msg("account deletion scheduled successfully")
+
+	return &AccountDeletionResponse{
+		Message:             "Your account will be deleted at the end of the grace period. Log in before then to keep it.",
+		DeletionScheduledAt: scheduledAt,
+	}, nil
+}
+
+// cancelDeletion keeps the account of a user who logs in during the grace
+// period of its deletion. After the grace period the account can't be
+// restored and the login fails.
+func (s *UserService) cancelDeletion(ctx context.Context, user *domain.User) error {
+	err := s.repo.CancelDeletion(ctx, user.ID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	s.audit(ctx, &user.ID, AuditAccountDeletionCanceled, user.ID, map[string]interface{}{
+		"deletion_scheduled_at": *user.DeletionScheduledAt,
+	})
+	user.DeletionScheduledAt = nil
+
+	s.logger.Info().
+		Str("userID", user.ID.String()).
+		Msg("account deletion canceled successfully")
+
+	return nil
+}
+
+// audit records an event about a user's account. Failures are logged since
+// the change being audited has already been saved.
+func (s *UserService) audit(ctx context.Context, actorID *uuid.UUID, action string, userID uuid.UUID, details map[string]interface{}) {
+	event := domain.NewAuditEvent(actorID, action, AuditSubjectUser, userID, details)
+	if err := s.auditRepo.Create(ctx, event); err != nil {
+		s.logger.Error().Err(err).Str("userID", userID.String()).Str("action", action).Msg("failed to record audit event")
+	}
+}
+
+// AccountErasureService erases the accounts whose deletion grace period has
+// ended: their stored files, tokens and sessions, and their records.
+type AccountErasureService struct {
+	userRepo  repositories.UserRepository
+	photoRepo repositories.PhotoRepository
+	storage   storage.StorageService
+	tokenSvc  *auth.TokenService
+	logger    zerolog.Logger
+}
+
+func NewAccountErasureService(
+	userRepo repositories.UserRepository,
+	photoRepo repositories.PhotoRepository,
+	storage storage.StorageService,
+	tokenSvc *auth.TokenService,
+	logger zerolog.Logger,
+) *AccountErasureService {
+	return &AccountErasureService{
+		userRepo:  userRepo,
+		photoRepo: photoRepo,
+		storage:   storage,
+		tokenSvc:  tokenSvc,
+		logger:    logger,
+	}
+}
+
+// Run erases due accounts every accountErasureInterval until ctx is done.
+func (s *AccountErasureService) Run(ctx context.Context) {
+	ticker := time.NewTicker(accountErasureInterval)
+	defer ticker.Stop()
+
+	for {
+		s.eraseDueAccounts(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *AccountErasureService) eraseDueAccounts(ctx context.Context) {
+	for {
+		now := time.Now()
+		users, err := s.userRepo.ClaimForErasure(ctx, now, now.Add(-accountErasureTimeout), accountErasureBatchSize)
+		if err != nil {
+			s.logger.Error().Err(err).Msg("failed to claim accounts for erasure")
+			return
+		}
+
+		for _, user := range users {
+			err := s.eraseAccount(ctx, user)
+			if err != nil {
+				// The account stays claimed and is retried once the claim
+				// is stale.
+				s.logger.Error().Err(err).Str("userID", user.ID.String()).Msg("account erasure failed")
+			}
+		}
+
+		if len(users) < accountErasureBatchSize {
+			return
+		}
+	}
+}
+
+// eraseAccount deletes the files of the user before their records, so a
+// failure part way leaves the records to find the remaining files by. Every
+// step can be repeated.
+func (s *AccountErasureService) eraseAccount(ctx context.Context, user *domain.User) error {
+	ctx, cancel := context.WithTimeout(ctx, accountErasureTimeout)
+	defer cancel()
+
+	_, err := s.tokenSvc.RevokeAllSessions(ctx, user.ID, "")
+	if err != nil {
+		return err
+	}
+	err = s.tokenSvc.RevokePasswordResetToken(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	photos, err := collectPages(func(limit, offset int) ([]*domain.Photo, int, error) {
+		return s.photoRepo.GetByUserID(ctx, user.ID, limit, offset)
+	})
+	if err != nil {
+		return err
+	}
+	versions, err := s.photoRepo.GetVersionsByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	// Photos moved in from elsewhere may be stored outside the user's
+	// prefix, so the recorded paths are deleted one by one first.
+	for _, photo := range photos {
+		err := s.storage.DeletePhoto(ctx, photo.StoragePath)
+		if err != nil {
+			return err
+		}
+	}
+	for _, version := range versions {
+		err := s.storage.DeletePhoto(ctx, version.StoragePath)
+		if err != nil {
+			return err
+		}
+	}
+	// Export archives and files no record points to anymore.
+	files, err := s.storage.DeleteUserFiles(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	event := domain.NewAuditEvent(nil, AuditAccountErased, AuditSubjectUser, user.ID, map[string]interface{}{
+		"deletion_scheduled_at": user.DeletionScheduledAt,
+		"account_created_at":    user.CreatedAt,
+		"photo_count":           len(photos),
+		"version_count":         len(versions),
+		"other_file_count":      files,
+	})
+	err = s.userRepo.Erase(ctx, user.ID, event)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info().
+		Str("userID", user.ID.String()).
+		Int("photos", len(photos)).
+		Int("versions", len(versions)).
+		Int("otherFiles", files).
+		Msg("account erased successfully")
+
+	return nil
+}
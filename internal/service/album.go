@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/domain"
+	repositories "github.com/mmd-moradi/goup/internal/repository"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/mmd-moradi/goup/pkg/validator"
+	"github.com/rs/zerolog"
+)
+
+type AlbumService struct {
+	albumRepo repositories.AlbumRepository
+	photoRepo repositories.PhotoRepository
+	authz     *Authorizer
+	logger    zerolog.Logger
+}
+
+type AlbumCreateInput struct {
+	Title       string `json:"title" validate:"required,max=255"`
+	Description string `json:"description" validate:"max=1000"`
+}
+
+type AlbumResponse struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"user_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+const (
+	// minSuggestionPhotos is how many photos without an album a place needs
+	// to be suggested as an album.
+	minSuggestionPhotos = 3
+	maxSuggestions      = 20
+)
+
+// AlbumSuggestionResponse proposes an album for photos taken at the same
+// place. PhotoIDs lists up to 100 of the photos, most recent first.
+type AlbumSuggestionResponse struct {
+	Title      string        `json:"title"`
+	Place      PlaceResponse `json:"place"`
+	PhotoCount int           `json:"photo_count"`
+	PhotoIDs   []string      `json:"photo_ids"`
+}
+
+func NewAlbumService(
+	albumRepo repositories.AlbumRepository,
+	photoRepo repositories.PhotoRepository,
+	authz *Authorizer,
+	logger zerolog.Logger,
+) *AlbumService {
+	return &AlbumService{
+		albumRepo: albumRepo,
+		photoRepo: photoRepo,
+		authz:     authz,
+		logger:    logger,
+	}
+}
+
+func (s *AlbumService) CreateAlbum(ctx context.Context, input AlbumCreateInput, userID uuid.UUID) (*AlbumResponse, error) {
+	if err := validator.Validate(input); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.BadRequest)
+	}
+
+	album := domain.NewAlbum(userID, input.Title, input.Description)
+
+	err := s.albumRepo.Create(ctx, album)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("userID", userID.String()).
+		Str("albumID", album.ID.String()).
+		Msg("album created successfully")
+
+	return newAlbumResponse(album), nil
+}
+
+func (s *AlbumService) GetAlbums(ctx context.Context, userID uuid.UUID) ([]AlbumResponse, error) {
+	albums, err := s.albumRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return newAlbumResponses(albums), nil
+}
+
+// GetSharedWithMe returns the albums other users granted the user access to.
+func (s *AlbumService) GetSharedWithMe(ctx context.Context, userID uuid.UUID) ([]AlbumResponse, error) {
+	albums, err := s.albumRepo.GetSharedWithUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return newAlbumResponses(albums), nil
+}
+
+// GetSuggestions proposes albums for the places where the user took several
+// photos that aren't in an album yet. Places the user already has an album
+// for, going by its title, are left out.
+func (s *AlbumService) GetSuggestions(ctx context.Context, userID uuid.UUID) ([]AlbumSuggestionResponse, error) {
+	albums, err := s.albumRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	titles := make(map[string]bool, len(albums))
+	for _, album := range albums {
+		titles[strings.ToLower(album.Title)] = true
+	}
+
+	places, err := s.photoRepo.GetUnalbumedPlaces(ctx, userID, minSuggestionPhotos, maxSuggestions)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]AlbumSuggestionResponse, 0, len(places))
+	for _, place := range places {
+		title := placeDisplayName(place.Name, place.CountryName)
+		if titles[strings.ToLower(title)] || titles[strings.ToLower(place.Name)] {
+			continue
+		}
+
+		photoIDs := make([]string, len(place.PhotoIDs))
+		for i, id := range place.PhotoIDs {
+			photoIDs[i] = id.String()
+		}
+		suggestions = append(suggestions, AlbumSuggestionResponse{
+			Title:      title,
+			Place:      *newPlaceResponse(place.Name, place.CountryCode, place.CountryName),
+			PhotoCount: place.PhotoCount,
+			PhotoIDs:   photoIDs,
+		})
+	}
+
+	return suggestions, nil
+}
+
+func (s *AlbumService) GetAlbumByID(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*AlbumResponse, error) {
+	album, err := s.getAuthorizedAlbum(ctx, id, userID, ActionView)
+	if err != nil {
+		return nil, err
+	}
+
+	return newAlbumResponse(album), nil
+}
+
+func (s *AlbumService) GetAlbumPhotos(ctx context.Context, id uuid.UUID, userID uuid.UUID, page, pageSize int) (*PhotosResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	if _, err := s.getAuthorizedAlbum(ctx, id, userID, ActionView); err != nil {
+		return nil, err
+	}
+
+	offset := (page - 1) * pageSize
+
+	photos, total, err := s.photoRepo.GetByAlbumID(ctx, id, pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := (total + pageSize - 1) / pageSize
+
+	photoResponses := make([]PhotoResponse, len(photos))
+	for i, photo := range photos {
+		photoResponses[i] = *newPhotoResponse(photo, userID)
+	}
+
+	return &PhotosResponse{
+		Photos:     photoResponses,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// AddPhoto moves a photo into the album. Albums only hold photos of the album
+// owner, so the photo must belong to the same user.
+func (s *AlbumService) AddPhoto(ctx context.Context, albumID uuid.UUID, photoID uuid.UUID, userID uuid.UUID) error {
+	album, err := s.getAuthorizedAlbum(ctx, albumID, userID, ActionEdit)
+	if err != nil {
+		return err
+	}
+
+	photo, err := s.photoRepo.GetByID(ctx, photoID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.authz.AuthorizePhoto(ctx, photo, userID, ActionEdit); err != nil {
+		return err
+	}
+
+	if photo.UserID != album.UserID {
+		return apperrors.New(apperrors.BadRequest, "photo and album must belong to the same user")
+	}
+
+	photo.AlbumID = &album.ID
+	photo.UpdatedAt = time.Now()
+
+	err = s.photoRepo.SetAlbum(ctx, photo)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info().
+		Str("userID", userID.String()).
+		Str("albumID", albumID.String()).
+		Str("photoID", photoID.String()).
+		Msg("photo added to album successfully")
+
+	return nil
+}
+
+func (s *AlbumService) RemovePhoto(ctx context.Context, albumID uuid.UUID, photoID uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.getAuthorizedAlbum(ctx, albumID, userID, ActionEdit); err != nil {
+		return err
+	}
+
+	photo, err := s.photoRepo.GetByID(ctx, photoID)
+	if err != nil {
+		return err
+	}
+
+	if photo.AlbumID == nil || *photo.AlbumID != albumID {
+		return apperrors.NewWithFormat(apperrors.NotFound, "photo with id %s is not in this album", photoID)
+	}
+
+	photo.AlbumID = nil
+	photo.UpdatedAt = time.Now()
+
+	return s.photoRepo.SetAlbum(ctx, photo)
+}
+
+func (s *AlbumService) DeleteAlbum(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.getAuthorizedAlbum(ctx, id, userID, ActionDelete); err != nil {
+		return err
+	}
+
+	err := s.albumRepo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info().
+		Str("userID", userID.String()).
+		Str("albumID", id.String()).
+		Msg("album deleted successfully")
+
+	return nil
+}
+
+func (s *AlbumService) getAuthorizedAlbum(ctx context.Context, id uuid.UUID, userID uuid.UUID, action Action) (*domain.Album, error) {
+	album, err := s.albumRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authz.AuthorizeAlbum(ctx, album, userID, action); err != nil {
+		return nil, err
+	}
+
+	return album, nil
+}
+
+func newAlbumResponse(album *domain.Album) *AlbumResponse {
+	return &AlbumResponse{
+		ID:          album.ID.String(),
+		UserID:      album.UserID.String(),
+		Title:       album.Title,
+		Description: album.Description,
+		CreatedAt:   album.CreatedAt,
+		UpdatedAt:   album.UpdatedAt,
+	}
+}
+
+func newAlbumResponses(albums []*domain.Album) []AlbumResponse {
+	albumResponses := make([]AlbumResponse, len(albums))
+	for i, album := range albums {
+		albumResponses[i] = *newAlbumResponse(album)
+	}
+	return albumResponses
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/domain"
+	repositories "github.com/mmd-moradi/goup/internal/repository"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+)
+
+type Action string
+
+const (
+	ActionView    Action = "view"
+	ActionComment Action = "comment"
+	ActionEdit    Action = "edit"
+	ActionDelete  Action = "delete"
+	ActionShare   Action = "share"
+)
+
+// Capability is something users with an unverified email address may be
+// restricted from doing.
+type Capability string
+
+const (
+	CapabilityUpload Capability = "upload"
+	CapabilityShare  Capability = "share"
+)
+
+// Authorizer is the single place that decides whether a user may perform an
+// action on a photo or an album. Grants are read from the database on every
+// check, so revoking one takes effect on the next request.
+type Authorizer struct {
+	grantRepo repositories.GrantRepository
+	userRepo  repositories.UserRepository
+	// unverified holds the capabilities denied to users who haven't
+	// verified their email address.
+	unverified map[Capability]bool
+}
+
+func NewAuthorizer(grantRepo repositories.GrantRepository, userRepo repositories.UserRepository, unverifiedRestrictions []string) *Authorizer {
+	unverified := make(map[Capability]bool, len(unverifiedRestrictions))
+	for _, restriction := range unverifiedRestrictions {
+		unverified[Capability(restriction)] = true
+	}
+	return &Authorizer{
+		grantRepo:  grantRepo,
+		userRepo:   userRepo,
+		unverified: unverified,
+	}
+}
+
+// AuthorizeCapability checks that the user may use a capability, which
+// depends on whether they verified their email address.
+func (a *Authorizer) AuthorizeCapability(ctx context.Context, userID uuid.UUID, capability Capability) error {
+	if !a.unverified[capability] {
+		return nil
+	}
+
+	user, err := a.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt == nil {
+		switch capability {
+		case CapabilityUpload:
+			return apperrors.New(apperrors.Forbidden, "verify your email address to upload photos")
+		default:
+			return apperrors.New(apperrors.Forbidden, "verify your email address to share photos")
+		}
+	}
+	return nil
+}
+
+// AuthorizePhoto checks an action on a photo. Owners may do anything. Anyone,
+// including anonymous callers (uuid.Nil), may view photos that are not private.
+// Other users need a grant on the photo or on its album.
+func (a *Authorizer) AuthorizePhoto(ctx context.Context, photo *domain.Photo, userID uuid.UUID, action Action) error {
+	if userID != uuid.Nil && photo.UserID == userID {
+		return nil
+	}
+
+	if action == ActionView && photo.Visibility != domain.VisibilityPrivate {
+		return nil
+	}
+
+	if userID != uuid.Nil {
+		roles, err := a.grantRepo.GetPhotoRoles(ctx, photo.ID, photo.AlbumID, userID)
+		if err != nil {
+			return err
+		}
+		for _, role := range roles {
+			if roleAllows(role, action) {
+				return nil
+			}
+		}
+	}
+
+	return apperrors.New(apperrors.Forbidden, "You don't have access to this photo")
+}
+
+// AuthorizeAlbum checks an action on an album. Owners may do anything, other
+// users need a grant on the album.
+func (a *Authorizer) AuthorizeAlbum(ctx context.Context, album *domain.Album, userID uuid.UUID, action Action) error {
+	if userID != uuid.Nil && album.UserID == userID {
+		return nil
+	}
+
+	if userID != uuid.Nil {
+		role, err := a.grantRepo.GetAlbumRole(ctx, album.ID, userID)
+		if err != nil {
+			return err
+		}
+		if roleAllows(role, action) {
+			return nil
+		}
+	}
+
+	return apperrors.New(apperrors.Forbidden, "You don't have access to this album")
+}
+
+// roleAllows maps grant roles to actions. Deleting and sharing are reserved for
+// the owner and are never allowed through a grant.
+func roleAllows(role domain.Role, action Action) bool {
+	switch action {
+	case ActionView:
+		return role.Rank() >= domain.RoleViewer.Rank()
+	case ActionComment:
+		return role.Rank() >= domain.RoleCommenter.Rank()
+	case ActionEdit:
+		return role.Rank() >= domain.RoleEditor.Rank()
+	default:
+		return false
+	}
+}
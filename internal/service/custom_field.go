@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/domain"
+	repositories "github.com/mmd-moradi/goup/internal/repository"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/mmd-moradi/goup/pkg/validator"
+	"github.com/rs/zerolog"
+)
+
+// maxCustomFields is how many custom fields a user can define.
+const maxCustomFields = 50
+
+type CustomFieldService struct {
+	customFieldRepo repositories.CustomFieldRepository
+	logger          zerolog.Logger
+}
+
+type CustomFieldCreateInput struct {
+	Name     string `json:"name" validate:"required,max=64,fieldname"`
+	Type     string `json:"type" validate:"required,oneof=text number boolean date"`
+	Required bool   `json:"required"`
+	// AllowedValues restricts the field to a set of values. Not supported
+	// for boolean fields.
+	AllowedValues []string `json:"allowed_values" validate:"max=100,dive,required,max=255"`
+}
+
+// CustomFieldUpdateInput replaces the constraints of a custom field. The name
+// and type of a field can't be changed; delete and recreate it instead.
+type CustomFieldUpdateInput struct {
+	Required      bool     `json:"required"`
+	AllowedValues []string `json:"allowed_values" validate:"max=100,dive,required,max=255"`
+}
+
+type CustomFieldResponse struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Type          string    `json:"type"`
+	Required      bool      `json:"required"`
+	AllowedValues []string  `json:"allowed_values"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func NewCustomFieldService(customFieldRepo repositories.CustomFieldRepository, logger zerolog.Logger) *CustomFieldService {
+	return &CustomFieldService{
+		customFieldRepo: customFieldRepo,
+		logger:          logger,
+	}
+}
+
+func (s *CustomFieldService) CreateCustomField(ctx context.Context, input CustomFieldCreateInput, userID uuid.UUID) (*CustomFieldResponse, error) {
+	if err := validator.Validate(input); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.BadRequest)
+	}
+
+	fieldType := domain.CustomFieldType(input.Type)
+	allowedValues, err := normalizeAllowedValues(fieldType, input.AllowedValues)
+	if err != nil {
+		return nil, err
+	}
+
+	definitions, err := s.customFieldRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(definitions) >= maxCustomFields {
+		return nil, apperrors.NewWithFormat(apperrors.BadRequest, "at most %d custom fields can be defined", maxCustomFields)
+	}
+	for _, definition := range definitions {
+		if definition.Name == input.Name {
+			return nil, apperrors.NewWithFormat(apperrors.Conflict, "custom field %s already exists", input.Name)
+		}
+	}
+
+	definition := domain.NewCustomFieldDefinition(userID, input.Name, fieldType, input.Required, allowedValues)
+
+	err = s.customFieldRepo.Create(ctx, definition)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("userID", userID.String()).
+		Str("customFieldID", definition.ID.String()).
+		Msg("custom field created successfully")
+
+	return newCustomFieldResponse(definition), nil
+}
+
+func (s *CustomFieldService) GetCustomFields(ctx context.Context, userID uuid.UUID) ([]CustomFieldResponse, error) {
+	definitions, err := s.customFieldRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]CustomFieldResponse, len(definitions))
+	for i, definition := range definitions {
+		responses[i] = *newCustomFieldResponse(definition)
+	}
+	return responses, nil
+}
+
+// UpdateCustomField changes whether a field is required and which values it
+// allows. Photos are checked against the new constraints the next time they
+// are saved.
+func (s *CustomFieldService) UpdateCustomField(ctx context.Context, id uuid.UUID, input CustomFieldUpdateInput, userID uuid.UUID) (*CustomFieldResponse, error) {
+	if err := validator.Validate(input); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.BadRequest)
+	}
+
+	definition, err := s.getOwnCustomField(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowedValues, err := normalizeAllowedValues(definition.Type, input.AllowedValues)
+	if err != nil {
+		return nil, err
+	}
+
+	definition.Required = input.Required
+	definition.AllowedValues = allowedValues
+	definition.UpdatedAt = time.Now()
+
+	err = s.customFieldRepo.Update(ctx, definition)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("userID", userID.String()).
+		Str("customFieldID", definition.ID.String()).
+		Msg("custom field updated successfully")
+
+	return newCustomFieldResponse(definition), nil
+}
+
+// DeleteCustomField deletes a field along with its values on the user's
+// photos.
+func (s *CustomFieldService) DeleteCustomField(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	definition, err := s.getOwnCustomField(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	err = s.customFieldRepo.Delete(ctx, definition, time.Now())
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info().
+		Str("userID", userID.String()).
+		Str("customFieldID", definition.ID.String()).
+		Msg("custom field deleted successfully")
+
+	return nil
+}
+
+func (s *CustomFieldService) getOwnCustomField(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*domain.CustomFieldDefinition, error) {
+	definition, err := s.customFieldRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if definition.UserID != userID {
+		return nil, apperrors.NewWithFormat(apperrors.NotFound, "custom field with id %s not found", id)
+	}
+	return definition, nil
+}
+
+// normalizeAllowedValues checks that allowed values are valid values of the
+// field type and removes duplicates. Numbers are rewritten in the form
+// values are compared in.
+func normalizeAllowedValues(fieldType domain.CustomFieldType, values []string) ([]string, error) {
+	if len(values) == 0 {
+		return []string{}, nil
+	}
+	if fieldType == domain.CustomFieldBoolean {
+		return nil, apperrors.New(apperrors.BadRequest, "boolean fields can't restrict their allowed values")
+	}
+
+	seen := make(map[string]bool, len(values))
+	normalized := make([]string, 0, len(values))
+	for _, value := range values {
+		parsed, err := parseCustomFieldValue(fieldType, value)
+		if err != nil {
+			return nil, apperrors.NewWithFormat(apperrors.BadRequest, "invalid allowed value %q: %v", value, err)
+		}
+		if number, ok := parsed.(float64); ok {
+			value = strconv.FormatFloat(number, 'f', -1, 64)
+		}
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+		normalized = append(normalized, value)
+	}
+	return normalized, nil
+}
+
+// parseCustomFieldValue converts the text form of a value, as used in
+// allowed values and list filters, to its JSON value.
+func parseCustomFieldValue(fieldType domain.CustomFieldType, value string) (interface{}, error) {
+	switch fieldType {
+	case domain.CustomFieldNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, apperrors.New(apperrors.BadRequest, "must be a number")
+		}
+		return number, nil
+	case domain.CustomFieldBoolean:
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, apperrors.New(apperrors.BadRequest, "must be true or false")
+		}
+		return boolean, nil
+	case domain.CustomFieldDate:
+		if _, err := time.Parse(validator.DateLayout, value); err != nil {
+			return nil, apperrors.New(apperrors.BadRequest, "must be a date in YYYY-MM-DD format")
+		}
+		return value, nil
+	default:
+		return value, nil
+	}
+}
+
+// customFieldRules converts definitions to the rules ValidateFields checks.
+func customFieldRules(definitions []*domain.CustomFieldDefinition) []validator.FieldRule {
+	rules := make([]validator.FieldRule, len(definitions))
+	for i, definition := range definitions {
+		rules[i] = validator.FieldRule{
+			Name:          definition.Name,
+			Type:          validator.FieldType(definition.Type),
+			Required:      definition.Required,
+			AllowedValues: definition.AllowedValues,
+		}
+	}
+	return rules
+}
+
+// customFieldFilter converts list filters, given as field names and the text
+// form of values, to a JSON document matched against photos' custom fields.
+func customFieldFilter(definitions []*domain.CustomFieldDefinition, filters map[string]string) (map[string]interface{}, error) {
+	byName := make(map[string]*domain.CustomFieldDefinition, len(definitions))
+	for _, definition := range definitions {
+		byName[definition.Name] = definition
+	}
+
+	filter := make(map[string]interface{}, len(filters))
+	for name, value := range filters {
+		definition, ok := byName[name]
+		if !ok {
+			return nil, apperrors.NewWithFormat(apperrors.BadRequest, "unknown custom field %q", name)
+		}
+		parsed, err := parseCustomFieldValue(definition.Type, strings.TrimSpace(value))
+		if err != nil {
+			return nil, apperrors.NewWithFormat(apperrors.BadRequest, "field.%s %v", name, err)
+		}
+		filter[name] = parsed
+	}
+	return filter, nil
+}
+
+func newCustomFieldResponse(definition *domain.CustomFieldDefinition) *CustomFieldResponse {
+	return &CustomFieldResponse{
+		ID:            definition.ID.String(),
+		Name:          definition.Name,
+		Type:          string(definition.Type),
+		Required:      definition.Required,
+		AllowedValues: definition.AllowedValues,
+		CreatedAt:     definition.CreatedAt,
+		UpdatedAt:     definition.UpdatedAt,
+	}
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/domain"
+	"github.com/mmd-moradi/goup/internal/mail"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+)
+
+// exportAccountData is what a data export holds about the account besides
+// its profile and photos. Its members are written to the manifest as they
+// are. Tags lists every tag used on the user's photos, which also carry
+// their own; Grants and Transfers include those the user received.
+type exportAccountData struct {
+	Albums       []AlbumResponse       `json:"albums"`
+	Tags         []string              `json:"tags"`
+	ShareLinks   []ShareLinkResponse   `json:"share_links"`
+	Grants       []exportManifestGrant `json:"grants"`
+	CustomFields []CustomFieldResponse `json:"custom_fields"`
+	Sessions     []SessionResponse     `json:"sessions"`
+	Transfers    []TransferResponse    `json:"transfers"`
+	AuditEvents  []*domain.AuditEvent  `json:"audit_events"`
+}
+
+type exportManifestGrant struct {
+	GrantResponse
+	GrantedBy string `json:"granted_by"`
+}
+
+// CreateDataExport starts a background job that archives everything held
+// about the user: their profile, all their photos with metadata and the
+// rest of their account, in the format described on exportManifest. The
+// data is only collected once the job runs, and the user is emailed a
+// download link when it completes. A user can run one data export at a
+// time, which the database enforces; one cut short by a restart is failed
+// here once it is past its deadline, rather than waiting for Run.
+func (s *ExportService) CreateDataExport(ctx context.Context, userID uuid.UUID) (*ExportJobResponse, error) {
+	latest, err := s.exportRepo.GetLatestByKind(ctx, userID, domain.ExportKindAccount)
+	var appErr apperrors.Error
+	if err != nil && !(errors.As(err, &appErr) && appErr.Type == apperrors.NotFound) {
+		return nil, err
+	}
+	if err == nil && latest.IsActive() {
+		return nil, apperrors.New(apperrors.Conflict, "a data export is already in progress")
+	}
+	if err == nil && (latest.Status == domain.ExportStatusPending || latest.Status == domain.ExportStatusRunning) {
+		latest.Status = domain.ExportStatusFailed
+		latest.Error = exportInterruptedMessage
+		latest.UpdatedAt = time.Now()
+		err = s.exportRepo.Update(ctx, latest)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	job := domain.NewExportJob(userID, domain.ExportKindAccount, []uuid.UUID{})
+
+	err = s.exportRepo.Create(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+
+	go s.runExportJob(job, exportContents{userID: userID})
+
+	s.logger.Info().
+		Str("userID", userID.String()).
+		Str("exportID", job.ID.String()).
+		Msg("data export job created successfully")
+
+	return newExportJobResponse(job, ""), nil
+}
+
+// collectDataExport gathers the contents of the data export job, and
+// records the photos it holds on the job.
+func (s *ExportService) collectDataExport(ctx context.Context, job *domain.ExportJob) (exportContents, error) {
+	user, err := s.userRepo.GetByID(ctx, job.UserID)
+	if err != nil {
+		return exportContents{}, err
+	}
+	photos, err := collectPages(func(limit, offset int) ([]*domain.Photo, int, error) {
+		return s.photoRepo.GetByUserID(ctx, job.UserID, limit, offset)
+	})
+	if err != nil {
+		return exportContents{}, err
+	}
+
+	account, err := s.collectAccountData(ctx, job.UserID, photos)
+	if err != nil {
+		return exportContents{}, err
+	}
+
+	job.PhotoIDs = make([]uuid.UUID, len(photos))
+	for i, photo := range photos {
+		job.PhotoIDs[i] = photo.ID
+	}
+
+	return exportContents{
+		userID:  job.UserID,
+		profile: newUserResponse(user),
+		photos:  photos,
+		account: account,
+	}, nil
+}
+
+// collectAccountData gathers the records of the user's account that go into
+// a data export next to their photos.
+func (s *ExportService) collectAccountData(ctx context.Context, userID uuid.UUID, photos []*domain.Photo) (*exportAccountData, error) {
+	albums, err := s.albumRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	links, err := s.shareRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	grants, err := s.grantRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := s.fieldRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := s.tokenSvc.ListSessions(ctx, userID, "")
+	if err != nil {
+		return nil, err
+	}
+	transfers, err := s.transferRepo.GetByUserID(ctx, userID, math.MaxInt32)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.auditRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &exportAccountData{
+		Albums:       newAlbumResponses(albums),
+		Tags:         []string{},
+		ShareLinks:   make([]ShareLinkResponse, len(links)),
+		Grants:       make([]exportManifestGrant, len(grants)),
+		CustomFields: make([]CustomFieldResponse, len(fields)),
+		Sessions:     newSessionResponses(sessions),
+		Transfers:    make([]TransferResponse, len(transfers)),
+		AuditEvents:  events,
+	}
+
+	seenTags := make(map[string]bool)
+	for _, photo := range photos {
+		for _, tag := range photo.Tags {
+			if !seenTags[tag] {
+				seenTags[tag] = true
+				data.Tags = append(data.Tags, tag)
+			}
+		}
+	}
+	sort.Strings(data.Tags)
+
+	for i, link := range links {
+		data.ShareLinks[i] = *newShareLinkResponse(link)
+	}
+	for i, field := range fields {
+		data.CustomFields[i] = *newCustomFieldResponse(field)
+	}
+
+	usernames := make(map[uuid.UUID]string)
+	for i, grant := range grants {
+		grantee, err := s.username(ctx, usernames, grant.GranteeID)
+		if err != nil {
+			return nil, err
+		}
+		data.Grants[i] = exportManifestGrant{
+			GrantResponse: *newGrantResponse(grant, grantee),
+			GrantedBy:     grant.GrantedBy.String(),
+		}
+	}
+	for i, transfer := range transfers {
+		from, err := s.username(ctx, usernames, transfer.FromUserID)
+		if err != nil {
+			return nil, err
+		}
+		to, err := s.username(ctx, usernames, transfer.ToUserID)
+		if err != nil {
+			return nil, err
+		}
+		data.Transfers[i] = *newTransferResponse(transfer, from, to)
+	}
+
+	return data, nil
+}
+
+// username looks up the username of another user a record of the account
+// refers to. Users that no longer exist have none.
+func (s *ExportService) username(ctx context.Context, cache map[uuid.UUID]string, userID uuid.UUID) (string, error) {
+	if name, ok := cache[userID]; ok {
+		return name, nil
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		var appErr apperrors.Error
+		if !(errors.As(err, &appErr) && appErr.Type == apperrors.NotFound) {
+			return "", err
+		}
+		cache[userID] = ""
+		return "", nil
+	}
+	cache[userID] = user.Username
+	return user.Username, nil
+}
+
+// notifyDataExport emails the user the outcome of their data export, with a
+// download link if it completed.
+func (s *ExportService) notifyDataExport(ctx context.Context, job *domain.ExportJob) {
+	ctx, cancel := context.WithTimeout(ctx, accountMailTimeout)
+	defer cancel()
+
+	user, err := s.userRepo.GetByID(ctx, job.UserID)
+	if err != nil {
+		s.logger.Error().Err(err).Str("exportID", job.ID.String()).Msg("failed to load user for data export email")
+		return
+	}
+
+	message := mail.Message{
+		To:      user.Email,
+		Subject: "Your data export failed",
+		Body: fmt.Sprintf("Hi %s,\r\n\r\n"+
+			"We couldn't build the export of your data. Please request a new one.\r\n",
+			user.Username),
+	}
+	if job.Status == domain.ExportStatusCompleted {
+		link, err := s.downloadURL(ctx, job)
+		if err != nil {
+			s.logger.Error().Err(err).Str("exportID", job.ID.String()).Msg("failed to create data export download URL")
+			return
+		}
+		message.Subject = "Your data export is ready"
+		message.Body = fmt.Sprintf("Hi %s,\r\n\r\n"+
+			"The export of your data is ready. Download it from this link:\r\n\r\n"+
+			"%s\r\n\r\n"+
+			"The link expires in %d hours.\r\n",
+			user.Username, link, int(ExportLinkTTL.Hours()))
+	}
+
+	err = s.mailer.Send(ctx, message)
+	if err != nil {
+		s.logger.Error().Err(err).Str("exportID", job.ID.String()).Msg("failed to send data export email")
+		return
+	}
+
+	s.logger.Info().
+		Str("userID", user.ID.String()).
+		Str("exportID", job.ID.String()).
+		Msg("data export email sent successfully")
+}
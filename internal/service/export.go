@@ -0,0 +1,583 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/auth"
+	"github.com/mmd-moradi/goup/internal/domain"
+	"github.com/mmd-moradi/goup/internal/mail"
+	repositories "github.com/mmd-moradi/goup/internal/repository"
+	"github.com/mmd-moradi/goup/internal/storage"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/mmd-moradi/goup/pkg/validator"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// Exports up to these limits are streamed in the response; larger ones
+	// run as a background job.
+	syncExportMaxPhotos = 50
+	syncExportMaxBytes  = 100 << 20
+
+	// ExportLinkTTL is how long the archive of a background export can be
+	// downloaded after it completed. Run deletes the archive afterwards.
+	ExportLinkTTL = 24 * time.Hour
+
+	exportJobConcurrency = 2
+	// exportJobGrace is how long past its deadline a job has to save its
+	// result. Only jobs still unfinished after that are failed by Run.
+	exportJobGrace = time.Minute
+	// exportRecoveryInterval is how often jobs cut short and expired
+	// archives are looked for.
+	exportRecoveryInterval = 10 * time.Minute
+	exportPageSize         = 100
+	exportManifestName     = "metadata.json"
+	exportManifestFormat   = 1
+
+	exportInterruptedMessage = "the export was interrupted, please request a new one"
+)
+
+const (
+	ExportSelectionPhotos = "photos"
+	ExportSelectionAlbum  = "album"
+	ExportSelectionAll    = "all"
+)
+
+type ExportService struct {
+	exportRepo   repositories.ExportJobRepository
+	photoRepo    repositories.PhotoRepository
+	albumRepo    repositories.AlbumRepository
+	userRepo     repositories.UserRepository
+	shareRepo    repositories.ShareLinkRepository
+	grantRepo    repositories.GrantRepository
+	fieldRepo    repositories.CustomFieldRepository
+	transferRepo repositories.OwnershipTransferRepository
+	auditRepo    repositories.AuditRepository
+	tokenSvc     *auth.TokenService
+	storage      storage.StorageService
+	authz        *Authorizer
+	mailer       mail.Mailer
+	logger       zerolog.Logger
+	jobs         chan struct{}
+}
+
+// ExportCreateInput selects the photos to export: the photos in PhotoIDs,
+// the photos of AlbumID, or all photos of the user. Background forces a
+// background job even for small selections.
+type ExportCreateInput struct {
+	Selection  string   `json:"selection" validate:"required,oneof=photos album all"`
+	PhotoIDs   []string `json:"photo_ids,omitempty" validate:"required_if=Selection photos,omitempty,max=1000,dive,uuid"`
+	AlbumID    string   `json:"album_id,omitempty" validate:"required_if=Selection album,omitempty,uuid"`
+	Background bool     `json:"background,omitempty"`
+}
+
+type ExportJobResponse struct {
+	ID string `json:"id"`
+	// Kind is "photos" for exports of selected photos and "account" for
+	// data exports of the whole account.
+	Kind        string     `json:"kind"`
+	Status      string     `json:"status"`
+	PhotoCount  int        `json:"photo_count"`
+	Error       string     `json:"error,omitempty"`
+	DownloadURL string     `json:"download_url,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Export is the outcome of CreateExport. Either Job is set, or the archive
+// is small enough to be written directly with WriteArchive.
+type Export struct {
+	Job    *ExportJobResponse
+	userID uuid.UUID
+	photos []*domain.Photo
+}
+
+// FileName is the name suggested for a streamed archive.
+func (e *Export) FileName() string {
+	return exportFileName(domain.ExportKindPhotos, time.Now())
+}
+
+// exportContents is what goes into an archive exported by userID. Profile and
+// account are only set for data exports of an account.
+type exportContents struct {
+	userID  uuid.UUID
+	profile *UserResponse
+	photos  []*domain.Photo
+	account *exportAccountData
+}
+
+// exportManifest is written to metadata.json at the root of every archive,
+// next to a photos directory holding the original file of every exported
+// photo:
+//
+//	metadata.json
+//	photos/<photo id><extension>
+//
+// Photos holds the metadata of each photo as the API returns it, with the
+// path of its file in the archive, which is all it takes to upload the
+// photos again. Photos whose file couldn't be read are listed in Missing
+// instead. Profile and the members of exportAccountData are only present in
+// data exports of an account.
+//
+// The layout is versioned by Format and only ever extended: new members are
+// added as optional, and Format is raised only for a change existing readers
+// couldn't handle.
+type exportManifest struct {
+	Format     int                   `json:"format"`
+	ExportedAt time.Time             `json:"exported_at"`
+	Profile    *UserResponse         `json:"profile,omitempty"`
+	Photos     []exportManifestPhoto `json:"photos"`
+	Missing    []exportManifestError `json:"missing,omitempty"`
+	*exportAccountData
+}
+
+type exportManifestPhoto struct {
+	PhotoResponse
+	Path string `json:"path"`
+}
+
+type exportManifestError struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+func NewExportService(
+	exportRepo repositories.ExportJobRepository,
+	photoRepo repositories.PhotoRepository,
+	albumRepo repositories.AlbumRepository,
+	userRepo repositories.UserRepository,
+	shareRepo repositories.ShareLinkRepository,
+	grantRepo repositories.GrantRepository,
+	fieldRepo repositories.CustomFieldRepository,
+	transferRepo repositories.OwnershipTransferRepository,
+	auditRepo repositories.AuditRepository,
+	tokenSvc *auth.TokenService,
+	storage storage.StorageService,
+	authz *Authorizer,
+	mailer mail.Mailer,
+	logger zerolog.Logger,
+) *ExportService {
+	return &ExportService{
+		exportRepo:   exportRepo,
+		photoRepo:    photoRepo,
+		albumRepo:    albumRepo,
+		userRepo:     userRepo,
+		shareRepo:    shareRepo,
+		grantRepo:    grantRepo,
+		fieldRepo:    fieldRepo,
+		transferRepo: transferRepo,
+		auditRepo:    auditRepo,
+		tokenSvc:     tokenSvc,
+		storage:      storage,
+		authz:        authz,
+		mailer:       mailer,
+		logger:       logger,
+		jobs:         make(chan struct{}, exportJobConcurrency),
+	}
+}
+
+// CreateExport resolves the selection of input. Small selections are
+// returned for streaming; larger ones start a background job whose archive
+// is uploaded to storage.
+func (s *ExportService) CreateExport(ctx context.Context, input ExportCreateInput, userID uuid.UUID) (*Export, error) {
+	if err := validator.Validate(input); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.BadRequest)
+	}
+
+	photos, err := s.resolveSelection(ctx, input, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(photos) == 0 {
+		return nil, apperrors.New(apperrors.BadRequest, "the selection contains no photos")
+	}
+
+	var totalSize int64
+	for _, photo := range photos {
+		totalSize += photo.FileSize
+	}
+	if !input.Background && len(photos) <= syncExportMaxPhotos && totalSize <= syncExportMaxBytes {
+		return &Export{userID: userID, photos: photos}, nil
+	}
+
+	photoIDs := make([]uuid.UUID, len(photos))
+	for i, photo := range photos {
+		photoIDs[i] = photo.ID
+	}
+	job := domain.NewExportJob(userID, domain.ExportKindPhotos, photoIDs)
+
+	err = s.exportRepo.Create(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+
+	go s.runExportJob(job, exportContents{userID: userID, photos: photos})
+
+	s.logger.Info().
+		Str("userID", userID.String()).
+		Str("exportID", job.ID.String()).
+		Int("photos", len(photos)).
+		Msg("export job created successfully")
+
+	return &Export{Job: newExportJobResponse(job, "")}, nil
+}
+
+// WriteArchive streams the ZIP archive of a small export to w.
+func (s *ExportService) WriteArchive(ctx context.Context, w io.Writer, export *Export) error {
+	return s.writeArchive(ctx, w, exportContents{userID: export.userID, photos: export.photos})
+}
+
+// GetExport returns the state of a background export, with a download link
+// once its archive is ready.
+func (s *ExportService) GetExport(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*ExportJobResponse, error) {
+	job, err := s.exportRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.UserID != userID {
+		return nil, apperrors.NewWithFormat(apperrors.NotFound, "export with id %s not found", id)
+	}
+
+	if job.Status == domain.ExportStatusExpired {
+		return nil, apperrors.New(apperrors.NotFound, "export has expired")
+	}
+
+	downloadURL := ""
+	if job.Status == domain.ExportStatusCompleted {
+		if job.IsExpired() {
+			return nil, apperrors.New(apperrors.NotFound, "export has expired")
+		}
+		downloadURL, err = s.downloadURL(ctx, job)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newExportJobResponse(job, downloadURL), nil
+}
+
+// downloadURL returns a link to the archive of a completed job that works
+// until the job expires.
+func (s *ExportService) downloadURL(ctx context.Context, job *domain.ExportJob) (string, error) {
+	return s.storage.GetDownloadURL(ctx, job.StoragePath, exportFileName(job.Kind, job.CreatedAt), time.Until(*job.ExpiresAt))
+}
+
+func (s *ExportService) resolveSelection(ctx context.Context, input ExportCreateInput, userID uuid.UUID) ([]*domain.Photo, error) {
+	switch input.Selection {
+	case ExportSelectionPhotos:
+		ids, err := parseBulkIDs(input.PhotoIDs)
+		if err != nil {
+			return nil, err
+		}
+		photos := make([]*domain.Photo, 0, len(ids))
+		for _, id := range ids {
+			photo, err := s.photoRepo.GetByID(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			err = s.authz.AuthorizePhoto(ctx, photo, userID, ActionView)
+			if err != nil {
+				return nil, err
+			}
+			photos = append(photos, photo)
+		}
+		return photos, nil
+
+	case ExportSelectionAlbum:
+		albumID, err := uuid.Parse(input.AlbumID)
+		if err != nil {
+			return nil, apperrors.New(apperrors.BadRequest, "invalid album ID")
+		}
+		album, err := s.albumRepo.GetByID(ctx, albumID)
+		if err != nil {
+			return nil, err
+		}
+		err = s.authz.AuthorizeAlbum(ctx, album, userID, ActionView)
+		if err != nil {
+			return nil, err
+		}
+		return collectPages(func(limit, offset int) ([]*domain.Photo, int, error) {
+			return s.photoRepo.GetByAlbumID(ctx, album.ID, limit, offset)
+		})
+
+	default:
+		return collectPages(func(limit, offset int) ([]*domain.Photo, int, error) {
+			return s.photoRepo.GetByUserID(ctx, userID, limit, offset)
+		})
+	}
+}
+
+// runExportJob builds the archive of job once one of the job slots is free.
+// The contents of data exports are collected here, so contents only needs
+// its userID set for them.
+func (s *ExportService) runExportJob(job *domain.ExportJob, contents exportContents) {
+	ctx, cancel := context.WithDeadline(context.Background(), job.Deadline())
+	defer cancel()
+	// The result is saved even when the deadline cut the job short.
+	saveCtx, cancelSave := context.WithTimeout(context.WithoutCancel(ctx), exportJobGrace)
+	defer cancelSave()
+
+	select {
+	case s.jobs <- struct{}{}:
+		defer func() { <-s.jobs }()
+	case <-ctx.Done():
+		s.finishExportJob(saveCtx, job, "", ctx.Err())
+		return
+	}
+
+	if job.Kind == domain.ExportKindAccount {
+		var err error
+		contents, err = s.collectDataExport(ctx, job)
+		if err != nil {
+			s.finishExportJob(saveCtx, job, "", err)
+			return
+		}
+	}
+
+	job.Status = domain.ExportStatusRunning
+	job.UpdatedAt = time.Now()
+	if err := s.exportRepo.Update(ctx, job); err != nil {
+		s.logger.Error().Err(err).Str("exportID", job.ID.String()).Msg("failed to mark export as running")
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(s.writeArchive(ctx, writer, contents))
+	}()
+
+	storagePath, err := s.storage.UploadExport(ctx, job.UserID, job.ID, reader)
+	reader.CloseWithError(err)
+
+	s.finishExportJob(saveCtx, job, storagePath, err)
+}
+
+// finishExportJob saves the outcome of a job, which failed if err is set,
+// and emails the user about data exports.
+func (s *ExportService) finishExportJob(ctx context.Context, job *domain.ExportJob, storagePath string, err error) {
+	job.UpdatedAt = time.Now()
+	if err != nil {
+		job.Status = domain.ExportStatusFailed
+		job.Error = "failed to build the export archive"
+		s.logger.Error().Err(err).Str("exportID", job.ID.String()).Msg("export job failed")
+	} else {
+		expiresAt := job.UpdatedAt.Add(ExportLinkTTL)
+		job.Status = domain.ExportStatusCompleted
+		job.StoragePath = storagePath
+		job.ExpiresAt = &expiresAt
+	}
+
+	if err := s.exportRepo.Update(ctx, job); err != nil {
+		s.logger.Error().Err(err).Str("exportID", job.ID.String()).Msg("failed to save export result")
+		return
+	}
+
+	if job.Kind == domain.ExportKindAccount {
+		s.notifyDataExport(ctx, job)
+	}
+
+	s.logger.Info().
+		Str("userID", job.UserID.String()).
+		Str("exportID", job.ID.String()).
+		Str("status", string(job.Status)).
+		Msg("export job finished successfully")
+}
+
+// Run fails the export jobs that were cut short, e.g. by a restart, and
+// deletes the archives whose download link expired, every
+// exportRecoveryInterval until ctx is done. Jobs only run in the process
+// that created them, so one that outlived its deadline will never finish
+// and would otherwise stay pending or running for good.
+func (s *ExportService) Run(ctx context.Context) {
+	ticker := time.NewTicker(exportRecoveryInterval)
+	defer ticker.Stop()
+
+	for {
+		s.failStaleJobs(ctx)
+		s.deleteExpiredArchives(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *ExportService) failStaleJobs(ctx context.Context) {
+	now := time.Now()
+	jobs, err := s.exportRepo.FailStale(ctx, now.Add(-domain.ExportJobTimeout-exportJobGrace), exportInterruptedMessage, now)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to fail stale export jobs")
+		return
+	}
+
+	for _, job := range jobs {
+		s.logger.Warn().
+			Str("userID", job.UserID.String()).
+			Str("exportID", job.ID.String()).
+			Msg("stale export job failed")
+
+		if job.Kind == domain.ExportKindAccount {
+			s.notifyDataExport(ctx, job)
+		}
+	}
+}
+
+// deleteExpiredArchives deletes the archives of completed jobs whose link
+// expired and marks the jobs expired. A job whose archive couldn't be
+// deleted stays completed and is retried on the next run.
+func (s *ExportService) deleteExpiredArchives(ctx context.Context) {
+	for {
+		jobs, err := s.exportRepo.GetExpired(ctx, time.Now(), exportPageSize)
+		if err != nil {
+			s.logger.Error().Err(err).Msg("failed to list expired export jobs")
+			return
+		}
+
+		deleted := 0
+		for _, job := range jobs {
+			if err := s.storage.DeleteExport(ctx, job.StoragePath); err != nil {
+				s.logger.Error().Err(err).Str("exportID", job.ID.String()).Msg("failed to delete expired export archive")
+				continue
+			}
+
+			job.Status = domain.ExportStatusExpired
+			job.StoragePath = ""
+			job.UpdatedAt = time.Now()
+			if err := s.exportRepo.Update(ctx, job); err != nil {
+				s.logger.Error().Err(err).Str("exportID", job.ID.String()).Msg("failed to mark export as expired")
+				continue
+			}
+			deleted++
+		}
+
+		if len(jobs) < exportPageSize || deleted < len(jobs) {
+			return
+		}
+	}
+}
+
+// writeArchive writes the photos and the metadata.json manifest as a ZIP to
+// w, copying every file straight from storage. A photo whose file cannot be
+// read is listed under "missing" in the manifest instead of failing the
+// whole archive.
+func (s *ExportService) writeArchive(ctx context.Context, w io.Writer, contents exportContents) error {
+	archive := zip.NewWriter(w)
+	manifest := exportManifest{
+		Format:            exportManifestFormat,
+		ExportedAt:        time.Now().UTC(),
+		Profile:           contents.profile,
+		Photos:            make([]exportManifestPhoto, 0, len(contents.photos)),
+		exportAccountData: contents.account,
+	}
+
+	for _, photo := range contents.photos {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		name := exportEntryName(photo)
+		err := s.writeArchiveEntry(ctx, archive, name, photo)
+		if err != nil {
+			if _, ok := err.(apperrors.Error); !ok {
+				// The archive writer itself failed, nothing more can be written.
+				return err
+			}
+			manifest.Missing = append(manifest.Missing, exportManifestError{
+				ID:    photo.ID.String(),
+				Error: err.Error(),
+			})
+			continue
+		}
+
+		manifest.Photos = append(manifest.Photos, exportManifestPhoto{
+			PhotoResponse: *newPhotoResponse(photo, contents.userID),
+			Path:          name,
+		})
+	}
+
+	entry, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     exportManifestName,
+		Method:   zip.Deflate,
+		Modified: manifest.ExportedAt,
+	})
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(entry)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+func (s *ExportService) writeArchiveEntry(ctx context.Context, archive *zip.Writer, name string, photo *domain.Photo) error {
+	file, err := s.storage.OpenPhoto(ctx, photo.StoragePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Photos are already compressed, so they are stored as is.
+	entry, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Store,
+		Modified: photo.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(entry, file)
+	return err
+}
+
+// collectPages calls list until every page has been read.
+func collectPages(list func(limit, offset int) ([]*domain.Photo, int, error)) ([]*domain.Photo, error) {
+	var photos []*domain.Photo
+	for offset := 0; ; offset += exportPageSize {
+		page, total, err := list(exportPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		photos = append(photos, page...)
+		if len(page) < exportPageSize || len(photos) >= total {
+			return photos, nil
+		}
+	}
+}
+
+func exportEntryName(photo *domain.Photo) string {
+	return "photos/" + photo.ID.String() + path.Ext(photo.FileName)
+}
+
+func exportFileName(kind domain.ExportKind, t time.Time) string {
+	if kind == domain.ExportKindAccount {
+		return fmt.Sprintf("goup-data-export-%s.zip", t.UTC().Format("20060102-150405"))
+	}
+	return fmt.Sprintf("goup-export-%s.zip", t.UTC().Format("20060102-150405"))
+}
+
+func newExportJobResponse(job *domain.ExportJob, downloadURL string) *ExportJobResponse {
+	return &ExportJobResponse{
+		ID:          job.ID.String(),
+		Kind:        string(job.Kind),
+		Status:      string(job.Status),
+		PhotoCount:  len(job.PhotoIDs),
+		Error:       job.Error,
+		DownloadURL: downloadURL,
+		ExpiresAt:   job.ExpiresAt,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+	}
+}
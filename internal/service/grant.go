@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/domain"
+	repositories "github.com/mmd-moradi/goup/internal/repository"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/mmd-moradi/goup/pkg/validator"
+	"github.com/rs/zerolog"
+)
+
+// GrantService manages the access grants owners give other users on their
+// photos and albums.
+type GrantService struct {
+	grantRepo repositories.GrantRepository
+	photoRepo repositories.PhotoRepository
+	albumRepo repositories.AlbumRepository
+	userRepo  repositories.UserRepository
+	authz     *Authorizer
+	logger    zerolog.Logger
+}
+
+type GrantInput struct {
+	// User is the username or email address of the grantee.
+	User string `json:"user" validate:"required"`
+	Role string `json:"role" validate:"required,oneof=viewer commenter editor"`
+}
+
+type GrantResponse struct {
+	ID              string    `json:"id"`
+	PhotoID         *string   `json:"photo_id,omitempty"`
+	AlbumID         *string   `json:"album_id,omitempty"`
+	GranteeID       string    `json:"grantee_id"`
+	GranteeUsername string    `json:"grantee_username"`
+	Role            string    `json:"role"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func NewGrantService(
+	grantRepo repositories.GrantRepository,
+	photoRepo repositories.PhotoRepository,
+	albumRepo repositories.AlbumRepository,
+	userRepo repositories.UserRepository,
+	authz *Authorizer,
+	logger zerolog.Logger,
+) *GrantService {
+	return &GrantService{
+		grantRepo: grantRepo,
+		photoRepo: photoRepo,
+		albumRepo: albumRepo,
+		userRepo:  userRepo,
+		authz:     authz,
+		logger:    logger,
+	}
+}
+
+func (s *GrantService) GrantPhotoAccess(ctx context.Context, photoID uuid.UUID, input GrantInput, userID uuid.UUID) (*GrantResponse, error) {
+	if err := validator.Validate(input); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.BadRequest)
+	}
+
+	photo, err := s.photoRepo.GetByID(ctx, photoID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authz.AuthorizePhoto(ctx, photo, userID, ActionShare); err != nil {
+		return nil, err
+	}
+	if err := s.authz.AuthorizeCapability(ctx, userID, CapabilityShare); err != nil {
+		return nil, err
+	}
+
+	grantee, err := s.findGrantee(ctx, input.User, photo.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	grant, err := s.grantRepo.Upsert(ctx, domain.NewPhotoGrant(photoID, grantee.ID, userID, domain.Role(input.Role)))
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("userID", userID.String()).
+		Str("photoID", photoID.String()).
+		Str("granteeID", grantee.ID.String()).
+		Str("role", input.Role).
+		Msg("photo access granted successfully")
+
+	return newGrantResponse(grant, grantee.Username), nil
+}
+
+func (s *GrantService) GrantAlbumAccess(ctx context.Context, albumID uuid.UUID, input GrantInput, userID uuid.UUID) (*GrantResponse, error) {
+	if err := validator.Validate(input); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.BadRequest)
+	}
+
+	album, err := s.albumRepo.GetByID(ctx, albumID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authz.AuthorizeAlbum(ctx, album, userID, ActionShare); err != nil {
+		return nil, err
+	}
+	if err := s.authz.AuthorizeCapability(ctx, userID, CapabilityShare); err != nil {
+		return nil, err
+	}
+
+	grantee, err := s.findGrantee(ctx, input.User, album.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	grant, err := s.grantRepo.Upsert(ctx, domain.NewAlbumGrant(albumID, grantee.ID, userID, domain.Role(input.Role)))
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("userID", userID.String()).
+		Str("albumID", albumID.String()).
+		Str("granteeID", grantee.ID.String()).
+		Str("role", input.Role).
+		Msg("album access granted successfully")
+
+	return newGrantResponse(grant, grantee.Username), nil
+}
+
+func (s *GrantService) ListPhotoGrants(ctx context.Context, photoID uuid.UUID, userID uuid.UUID) ([]GrantResponse, error) {
+	photo, err := s.photoRepo.GetByID(ctx, photoID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authz.AuthorizePhoto(ctx, photo, userID, ActionShare); err != nil {
+		return nil, err
+	}
+
+	grants, err := s.grantRepo.GetByPhotoID(ctx, photoID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.newGrantResponses(ctx, grants)
+}
+
+func (s *GrantService) ListAlbumGrants(ctx context.Context, albumID uuid.UUID, userID uuid.UUID) ([]GrantResponse, error) {
+	album, err := s.albumRepo.GetByID(ctx, albumID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authz.AuthorizeAlbum(ctx, album, userID, ActionShare); err != nil {
+		return nil, err
+	}
+
+	grants, err := s.grantRepo.GetByAlbumID(ctx, albumID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.newGrantResponses(ctx, grants)
+}
+
+func (s *GrantService) RevokePhotoGrant(ctx context.Context, photoID uuid.UUID, grantID uuid.UUID, userID uuid.UUID) error {
+	photo, err := s.photoRepo.GetByID(ctx, photoID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.authz.AuthorizePhoto(ctx, photo, userID, ActionShare); err != nil {
+		return err
+	}
+
+	grant, err := s.grantRepo.GetByID(ctx, grantID)
+	if err != nil {
+		return err
+	}
+
+	if grant.PhotoID == nil || *grant.PhotoID != photoID {
+		return apperrors.NewWithFormat(apperrors.NotFound, "grant with id %s not found", grantID)
+	}
+
+	return s.revoke(ctx, grant, userID)
+}
+
+func (s *GrantService) RevokeAlbumGrant(ctx context.Context, albumID uuid.UUID, grantID uuid.UUID, userID uuid.UUID) error {
+	album, err := s.albumRepo.GetByID(ctx, albumID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.authz.AuthorizeAlbum(ctx, album, userID, ActionShare); err != nil {
+		return err
+	}
+
+	grant, err := s.grantRepo.GetByID(ctx, grantID)
+	if err != nil {
+		return err
+	}
+
+	if grant.AlbumID == nil || *grant.AlbumID != albumID {
+		return apperrors.NewWithFormat(apperrors.NotFound, "grant with id %s not found", grantID)
+	}
+
+	return s.revoke(ctx, grant, userID)
+}
+
+func (s *GrantService) revoke(ctx context.Context, grant *domain.Grant, userID uuid.UUID) error {
+	err := s.grantRepo.Delete(ctx, grant.ID)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info().
+		Str("userID", userID.String()).
+		Str("grantID", grant.ID.String()).
+		Str("granteeID", grant.GranteeID.String()).
+		Msg("access grant revoked successfully")
+
+	return nil
+}
+
+// findGrantee resolves a username or email address to the user receiving a
+// grant. Owners can't grant access to themselves.
+func (s *GrantService) findGrantee(ctx context.Context, user string, ownerID uuid.UUID) (*domain.User, error) {
+	user = strings.TrimSpace(user)
+
+	var (
+		grantee *domain.User
+		err     error
+	)
+	if strings.Contains(user, "@") {
+		grantee, err = s.userRepo.GetByEmail(ctx, user)
+	} else {
+		grantee, err = s.userRepo.GetByUsername(ctx, user)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if grantee.ID == ownerID {
+		return nil, apperrors.New(apperrors.BadRequest, "you can't grant access to the owner")
+	}
+
+	return grantee, nil
+}
+
+func (s *GrantService) newGrantResponses(ctx context.Context, grants []*domain.Grant) ([]GrantResponse, error) {
+	grantResponses := make([]GrantResponse, len(grants))
+	for i, grant := range grants {
+		grantee, err := s.userRepo.GetByID(ctx, grant.GranteeID)
+		if err != nil {
+			return nil, err
+		}
+		grantResponses[i] = *newGrantResponse(grant, grantee.Username)
+	}
+	return grantResponses, nil
+}
+
+func newGrantResponse(grant *domain.Grant, granteeUsername string) *GrantResponse {
+	var photoID, albumID *string
+	if grant.PhotoID != nil {
+		id := grant.PhotoID.String()
+		photoID = &id
+	}
+	if grant.AlbumID != nil {
+		id := grant.AlbumID.String()
+		albumID = &id
+	}
+
+	return &GrantResponse{
+		ID:              grant.ID.String(),
+		PhotoID:         photoID,
+		AlbumID:         albumID,
+		GranteeID:       grant.GranteeID.String(),
+		GranteeUsername: granteeUsername,
+		Role:            string(grant.Role),
+		CreatedAt:       grant.CreatedAt,
+	}
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/auth"
+	"github.com/mmd-moradi/goup/internal/domain"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/mmd-moradi/goup/pkg/validator"
+)
+
+// Audit actions recorded for two-factor authentication.
+const (
+	AuditMFAEnabled  = "account.mfa_enabled"
+	AuditMFADisabled = "account.mfa_disabled"
+)
+
+// Wrong two-factor authentication codes a user may enter, across confirming,
+// disabling and logging in, before they are locked out of entering codes
+// for MFALockout. Challenges are also revoked after a few wrong codes, but
+// a user can start new ones with their password.
+const (
+	MFAMaxFailures = 10
+	MFALockout     = 15 * time.Minute
+)
+
+// MFACodeInput holds a code from the user's authenticator app or, where a
+// recovery code is accepted, one of those.
+type MFACodeInput struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+type MFAVerifyInput struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required,max=32"`
+}
+
+// MFAEnrollmentResponse holds a new TOTP secret. URI is an otpauth:// URI
+// for authenticator apps, usually shown as a QR code; Secret is for typing
+// in by hand.
+type MFAEnrollmentResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// RecoveryCodesResponse holds single-use codes that replace an
+// authenticator code. They are only shown once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAChallengeResponse is returned by a login with the correct password for
+// a user with two-factor authentication. MFAToken is sent with a code to
+// /auth/mfa/verify before ExpiresAt to finish the login.
+type MFAChallengeResponse struct {
+	MFARequired bool      `json:"mfa_required"`
+	MFAToken    string    `json:"mfa_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// EnrollMFA creates a new TOTP secret for the user. Two-factor
+// authentication is only enabled once a code for it is confirmed with
+// ConfirmMFA; enrolling again before that replaces the secret.
+func (s *UserService) EnrollMFA(ctx context.Context, userID uuid.UUID) (*MFAEnrollmentResponse, error) {
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAEnabled() {
+		return nil, apperrors.New(apperrors.Conflict, "two-factor authentication is already enabled")
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to generate TOTP secret: %v", err)
+	}
+
+	user.TOTPSecret = secret
+	user.UpdatedAt = time.Now()
+	err = s.repo.SaveTOTP(ctx, user, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("userID", user.ID.String()).
+		Msg("two-factor authentication enrollment started successfully")
+
+	return &MFAEnrollmentResponse{
+		Secret: secret,
+		URI:    s.tokenSvc.TOTPURI(user.Email, secret),
+	}, nil
+}
+
+// ConfirmMFA enables two-factor authentication once the user proves their
+// authenticator works by entering a code from it, and returns their
+// recovery codes.
+func (s *UserService) ConfirmMFA(ctx context.Context, userID uuid.UUID, input MFACodeInput) (*RecoveryCodesResponse, error) {
+	if err := validator.Validate(input); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.BadRequest)
+	}
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAEnabled() {
+		return nil, apperrors.New(apperrors.Conflict, "two-factor authentication is already enabled")
+	}
+	if user.TOTPSecret == "" {
+		return nil, apperrors.New(apperrors.BadRequest, "two-factor authentication enrollment has not been started")
+	}
+
+	err = s.limitMFAFailures(ctx, user.ID, func() error {
+		return s.checkTOTP(ctx, user, input.Code)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := s.tokenSvc.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user.TOTPEnabledAt = &now
+	user.UpdatedAt = now
+	err = s.repo.SaveTOTP(ctx, user, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, &user.ID, AuditMFAEnabled, user.ID, nil)
+
+	s.logger.Info().
+		Str("userID", user.ID.String()).
+		Msg("two-factor authentication enabled successfully")
+
+	return &RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableMFA turns two-factor authentication off, which takes a code from
+// the user's authenticator or a recovery code.
+func (s *UserService) DisableMFA(ctx context.Context, userID uuid.UUID, input MFACodeInput) error {
+	if err := validator.Validate(input); err != nil {
+		return apperrors.Wrap(err, apperrors.BadRequest)
+	}
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.MFAEnabled() {
+		return apperrors.New(apperrors.BadRequest, "two-factor authentication is not enabled")
+	}
+
+	err = s.limitMFAFailures(ctx, user.ID, func() error {
+		return s.checkMFACode(ctx, user, input.Code)
+	})
+	if err != nil {
+		return err
+	}
+
+	user.TOTPSecret = ""
+	user.TOTPEnabledAt = nil
+	user.UpdatedAt = time.Now()
+	err = s.repo.SaveTOTP(ctx, user, []string{})
+	if err != nil {
+		return err
+	}
+
+	s.audit(ctx, &user.ID, AuditMFADisabled, user.ID, nil)
+
+	s.logger.Info().
+		Str("userID", user.ID.String()).
+		Msg("two-factor authentication disabled successfully")
+
+	return nil
+}
+
+// VerifyMFA finishes the login of a user with two-factor authentication,
+// exchanging the challenge from Login and a code for a token pair. A
+// challenge is revoked after too many wrong codes.
+func (s *UserService) VerifyMFA(ctx context.Context, input MFAVerifyInput, client auth.ClientInfo) (*AuthResponse, error) {
+	if err := validator.Validate(input); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.BadRequest)
+	}
+
+	userID, err := s.tokenSvc.CheckMFAChallenge(ctx, input.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.limitMFAFailures(ctx, user.ID, func() error {
+		return s.checkMFACode(ctx, user, input.Code)
+	})
+	if err != nil {
+		var appErr apperrors.Error
+		if errors.As(err, &appErr) && appErr.Type == apperrors.Unauthorized {
+			if failErr := s.tokenSvc.FailMFAChallenge(ctx, input.MFAToken); failErr != nil {
+				return nil, failErr
+			}
+		}
+		return nil, err
+	}
+
+	err = s.tokenSvc.ConsumeMFAChallenge(ctx, input.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.completeLogin(ctx, user, client)
+}
+
+// limitMFAFailures runs check on a code the user entered, counting it
+// against the user if it is wrong. Once the user entered too many wrong
+// codes, codes are refused without being checked until the lockout ends.
+func (s *UserService) limitMFAFailures(ctx context.Context, userID uuid.UUID, check func() error) error {
+	key := userID.String()
+	err := s.mfaLimiter.Check(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	err = check()
+	if err != nil {
+		var appErr apperrors.Error
+		if errors.As(err, &appErr) && appErr.Type == apperrors.Unauthorized {
+			if failErr := s.mfaLimiter.Fail(ctx, key); failErr != nil {
+				return failErr
+			}
+		}
+		return err
+	}
+
+	return s.mfaLimiter.Reset(ctx, key)
+}
+
+// checkMFACode accepts a code from the user's authenticator or one of their
+// unused recovery codes, which is used up.
+func (s *UserService) checkMFACode(ctx context.Context, user *domain.User, code string) error {
+	code = strings.TrimSpace(code)
+	if len(code) == 6 && strings.Trim(code, "0123456789") == "" {
+		return s.checkTOTP(ctx, user, code)
+	}
+
+	err := s.repo.UseRecoveryCode(ctx, user.ID, s.tokenSvc.HashRecoveryCode(code), time.Now())
+	if err != nil {
+		var appErr apperrors.Error
+		if errors.As(err, &appErr) && appErr.Type == apperrors.NotFound {
+			return apperrors.New(apperrors.Unauthorized, "invalid two-factor authentication code")
+		}
+		return err
+	}
+
+	s.logger.Info().
+		Str("userID", user.ID.String()).
+		Msg("recovery code used successfully")
+
+	return nil
+}
+
+// checkTOTP accepts a code from the user's authenticator that hasn't been
+// used yet.
+func (s *UserService) checkTOTP(ctx context.Context, user *domain.User, code string) error {
+	invalid := apperrors.New(apperrors.Unauthorized, "invalid two-factor authentication code")
+
+	step, ok := auth.ValidateTOTP(user.TOTPSecret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return invalid
+	}
+	fresh, err := s.tokenSvc.UseTOTPStep(ctx, user.ID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return invalid
+	}
+	return nil
+}
@@ -0,0 +1,676 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/domain"
+	repositories "github.com/mmd-moradi/goup/internal/repository"
+	"github.com/mmd-moradi/goup/internal/storage"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/mmd-moradi/goup/pkg/validator"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// TransferTTL is how long a recipient has to accept a transfer.
+	TransferTTL = 7 * 24 * time.Hour
+
+	transferJobConcurrency  = 2
+	transferCopyConcurrency = 8
+	// transferJobGrace is how long past its deadline a transfer has to save
+	// its result. Only transfers still running after that are failed by Run.
+	transferJobGrace = time.Minute
+	// transferRecoveryInterval is how often transfers cut short are looked
+	// for.
+	transferRecoveryInterval = 10 * time.Minute
+	maxTransfersListed       = 50
+)
+
+// Audit actions recorded for ownership transfers.
+const (
+	AuditSubjectTransfer   = "ownership_transfer"
+	AuditTransferInitiated = "ownership_transfer.initiated"
+	AuditTransferAccepted  = "ownership_transfer.accepted"
+	AuditTransferCompleted = "ownership_transfer.completed"
+	AuditTransferFailed    = "ownership_transfer.failed"
+	AuditTransferDeclined  = "ownership_transfer.declined"
+	AuditTransferCanceled  = "ownership_transfer.canceled"
+)
+
+type OwnershipTransferService struct {
+	transferRepo repositories.OwnershipTransferRepository
+	photoRepo    repositories.PhotoRepository
+	albumRepo    repositories.AlbumRepository
+	userRepo     repositories.UserRepository
+	auditRepo    repositories.AuditRepository
+	storage      storage.StorageService
+	versionSvc   *PhotoVersionService
+	logger       zerolog.Logger
+	jobs         chan struct{}
+}
+
+// TransferCreateInput selects what to give to Recipient, a username or email
+// address: the photos in PhotoIDs, the albums in AlbumIDs with their
+// photos, or all photos and albums of the user.
+type TransferCreateInput struct {
+	Recipient string   `json:"recipient" validate:"required,max=255"`
+	Selection string   `json:"selection" validate:"required,oneof=photos albums all"`
+	PhotoIDs  []string `json:"photo_ids,omitempty" validate:"required_if=Selection photos,omitempty,max=1000,dive,uuid"`
+	AlbumIDs  []string `json:"album_ids,omitempty" validate:"required_if=Selection albums,omitempty,max=100,dive,uuid"`
+}
+
+type TransferResponse struct {
+	ID           string     `json:"id"`
+	FromUserID   string     `json:"from_user_id"`
+	FromUsername string     `json:"from_username"`
+	ToUserID     string     `json:"to_user_id"`
+	ToUsername   string     `json:"to_username"`
+	Status       string     `json:"status"`
+	Selection    string     `json:"selection"`
+	PhotoIDs     []string   `json:"photo_ids"`
+	AlbumIDs     []string   `json:"album_ids"`
+	PhotoCount   int        `json:"photo_count"`
+	Error        string     `json:"error,omitempty"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func NewOwnershipTransferService(
+	transferRepo repositories.OwnershipTransferRepository,
+	photoRepo repositories.PhotoRepository,
+	albumRepo repositories.AlbumRepository,
+	userRepo repositories.UserRepository,
+	auditRepo repositories.AuditRepository,
+	storage storage.StorageService,
+	versionSvc *PhotoVersionService,
+	logger zerolog.Logger,
+) *OwnershipTransferService {
+	return &OwnershipTransferService{
+		transferRepo: transferRepo,
+		photoRepo:    photoRepo,
+		albumRepo:    albumRepo,
+		userRepo:     userRepo,
+		auditRepo:    auditRepo,
+		storage:      storage,
+		versionSvc:   versionSvc,
+		logger:       logger,
+		jobs:         make(chan struct{}, transferJobConcurrency),
+	}
+}
+
+// CreateTransfer offers the selected photos and albums to another user. Only
+// their owner can transfer them, and nothing moves until the recipient
+// accepts.
+func (s *OwnershipTransferService) CreateTransfer(ctx context.Context, input TransferCreateInput, userID uuid.UUID) (*TransferResponse, error) {
+	if err := validator.Validate(input); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.BadRequest)
+	}
+
+	sender, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	recipient, err := s.findRecipient(ctx, input.Recipient)
+	if err != nil {
+		return nil, err
+	}
+	if recipient.ID == userID {
+		return nil, apperrors.New(apperrors.BadRequest, "photos can't be transferred to yourself")
+	}
+
+	var photoIDs, albumIDs []uuid.UUID
+	switch input.Selection {
+	case domain.TransferSelectionPhotos:
+		photoIDs, err = parseBulkIDs(input.PhotoIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range photoIDs {
+			photo, err := s.photoRepo.GetByID(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			if photo.UserID != userID {
+				return nil, apperrors.NewWithFormat(apperrors.Forbidden, "only the owner can transfer photo %s", id)
+			}
+		}
+	case domain.TransferSelectionAlbums:
+		albumIDs, err = parseBulkIDs(input.AlbumIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range albumIDs {
+			album, err := s.albumRepo.GetByID(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			if album.UserID != userID {
+				return nil, apperrors.NewWithFormat(apperrors.Forbidden, "only the owner can transfer album %s", id)
+			}
+		}
+	}
+
+	transfer := domain.NewOwnershipTransfer(userID, recipient.ID, input.Selection, photoIDs, albumIDs, TransferTTL)
+
+	err = s.transferRepo.Create(ctx, transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, &userID, AuditTransferInitiated, transfer, nil)
+
+	s.logger.Info().
+		Str("userID", userID.String()).
+		Str("transferID", transfer.ID.String()).
+		Str("recipientID", recipient.ID.String()).
+		Msg("ownership transfer created successfully")
+
+	return newTransferResponse(transfer, sender.Username, recipient.Username), nil
+}
+
+// GetTransfers returns the latest transfers the user sent or received.
+func (s *OwnershipTransferService) GetTransfers(ctx context.Context, userID uuid.UUID) ([]TransferResponse, error) {
+	transfers, err := s.transferRepo.GetByUserID(ctx, userID, maxTransfersListed)
+	if err != nil {
+		return nil, err
+	}
+
+	usernames := make(map[uuid.UUID]string)
+	responses := make([]TransferResponse, len(transfers))
+	for i, transfer := range transfers {
+		from, err := s.username(ctx, usernames, transfer.FromUserID)
+		if err != nil {
+			return nil, err
+		}
+		to, err := s.username(ctx, usernames, transfer.ToUserID)
+		if err != nil {
+			return nil, err
+		}
+		responses[i] = *newTransferResponse(transfer, from, to)
+	}
+	return responses, nil
+}
+
+func (s *OwnershipTransferService) GetTransfer(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*TransferResponse, error) {
+	transfer, err := s.getTransfer(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.newResponse(ctx, transfer)
+}
+
+// AcceptTransfer starts moving the photos and albums of a pending transfer to
+// the recipient. The move runs in the background; the transfer reports
+// completed or failed once it is done.
+func (s *OwnershipTransferService) AcceptTransfer(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*TransferResponse, error) {
+	transfer, err := s.getTransfer(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.ToUserID != userID {
+		return nil, apperrors.New(apperrors.Forbidden, "only the recipient can accept a transfer")
+	}
+	if transfer.Status != domain.TransferStatusPending {
+		return nil, apperrors.NewWithFormat(apperrors.Conflict, "transfer is %s", transfer.Status)
+	}
+	if transfer.IsExpired() {
+		return nil, apperrors.New(apperrors.BadRequest, "transfer has expired")
+	}
+
+	transfer.Status = domain.TransferStatusRunning
+	transfer.UpdatedAt = time.Now()
+	err = s.transferRepo.UpdateStatus(ctx, transfer, domain.TransferStatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, &userID, AuditTransferAccepted, transfer, nil)
+
+	go s.runTransfer(transfer)
+
+	s.logger.Info().
+		Str("userID", userID.String()).
+		Str("transferID", transfer.ID.String()).
+		Msg("ownership transfer accepted successfully")
+
+	return s.newResponse(ctx, transfer)
+}
+
+// DeclineTransfer lets the recipient refuse a pending transfer.
+func (s *OwnershipTransferService) DeclineTransfer(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*TransferResponse, error) {
+	transfer, err := s.getTransfer(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.ToUserID != userID {
+		return nil, apperrors.New(apperrors.Forbidden, "only the recipient can decline a transfer")
+	}
+	return s.closeTransfer(ctx, transfer, userID, domain.TransferStatusDeclined, AuditTransferDeclined)
+}
+
+// CancelTransfer lets the sender withdraw a pending transfer.
+func (s *OwnershipTransferService) CancelTransfer(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*TransferResponse, error) {
+	transfer, err := s.getTransfer(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.FromUserID != userID {
+		return nil, apperrors.New(apperrors.Forbidden, "only the sender can cancel a transfer")
+	}
+	return s.closeTransfer(ctx, transfer, userID, domain.TransferStatusCanceled, AuditTransferCanceled)
+}
+
+func (s *OwnershipTransferService) closeTransfer(ctx context.Context, transfer *domain.OwnershipTransfer, userID uuid.UUID, status domain.TransferStatus, action string) (*TransferResponse, error) {
+	if transfer.Status != domain.TransferStatusPending {
+		return nil, apperrors.NewWithFormat(apperrors.Conflict, "transfer is %s", transfer.Status)
+	}
+
+	now := time.Now()
+	transfer.Status = status
+	transfer.CompletedAt = &now
+	transfer.UpdatedAt = now
+	err := s.transferRepo.UpdateStatus(ctx, transfer, domain.TransferStatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, &userID, action, transfer, nil)
+
+	s.logger.Info().
+		Str("userID", userID.String()).
+		Str("transferID", transfer.ID.String()).
+		Str("status", string(status)).
+		Msg("ownership transfer closed successfully")
+
+	return s.newResponse(ctx, transfer)
+}
+
+// runTransfer copies the files of the selected photos under the recipient,
+// moves the records in one transaction and only then deletes the original
+// files, so a failure at any point leaves the sender's photos intact.
+func (s *OwnershipTransferService) runTransfer(transfer *domain.OwnershipTransfer) {
+	ctx, cancel := context.WithDeadline(context.Background(), transfer.Deadline())
+	defer cancel()
+	// The outcome is saved even when the deadline cut the transfer short.
+	saveCtx, cancelSave := context.WithTimeout(context.WithoutCancel(ctx), transferJobGrace)
+	defer cancelSave()
+
+	select {
+	case s.jobs <- struct{}{}:
+		defer func() { <-s.jobs }()
+	case <-ctx.Done():
+		s.failTransfer(saveCtx, transfer, ctx.Err())
+		return
+	}
+
+	photos, albumIDs, err := s.resolveTransfer(ctx, transfer)
+	if err != nil {
+		s.failTransfer(saveCtx, transfer, err)
+		return
+	}
+
+	moved, err := s.copyFiles(ctx, transfer, photos, albumIDs)
+	if err != nil {
+		s.failTransfer(saveCtx, transfer, err)
+		return
+	}
+
+	var totalSize int64
+	for _, photo := range photos {
+		totalSize += photo.FileSize
+	}
+
+	now := time.Now()
+	transfer.Status = domain.TransferStatusCompleted
+	transfer.PhotoCount = len(photos)
+	transfer.CompletedAt = &now
+	transfer.UpdatedAt = now
+	event := domain.NewAuditEvent(nil, AuditTransferCompleted, AuditSubjectTransfer, transfer.ID, map[string]interface{}{
+		"from_user_id": transfer.FromUserID,
+		"to_user_id":   transfer.ToUserID,
+		"photo_count":  len(photos),
+		"album_ids":    albumIDs,
+		"total_size":   totalSize,
+	})
+
+	err = s.transferRepo.Complete(ctx, transfer, moved, albumIDs, event)
+	if err != nil {
+		transfer.PhotoCount = 0
+		transfer.CompletedAt = nil
+		s.failTransfer(saveCtx, transfer, err)
+		return
+	}
+
+	for _, photo := range moved {
+		s.deleteFile(saveCtx, photo.Photo.StoragePath)
+		for _, version := range photo.Versions {
+			s.deleteFile(saveCtx, version.Version.StoragePath)
+		}
+	}
+
+	// The recipient's version history may now exceed their limit.
+	s.versionSvc.pruneVersions(saveCtx, transfer.ToUserID)
+
+	s.logger.Info().
+		Str("transferID", transfer.ID.String()).
+		Int("photos", len(moved)).
+		Int("albums", len(albumIDs)).
+		Msg("ownership transfer finished successfully")
+}
+
+// resolveTransfer returns the photos and albums a transfer moves, leaving
+// out those the sender no longer owns.
+func (s *OwnershipTransferService) resolveTransfer(ctx context.Context, transfer *domain.OwnershipTransfer) ([]*domain.Photo, []uuid.UUID, error) {
+	owner := transfer.FromUserID
+
+	switch transfer.Selection {
+	case domain.TransferSelectionPhotos:
+		var photos []*domain.Photo
+		for _, id := range transfer.PhotoIDs {
+			photo, err := s.photoRepo.GetByID(ctx, id)
+			if err != nil {
+				var appErr apperrors.Error
+				if errors.As(err, &appErr) && appErr.Type == apperrors.NotFound {
+					continue
+				}
+				return nil, nil, err
+			}
+			if photo.UserID == owner {
+				photos = append(photos, photo)
+			}
+		}
+		return photos, []uuid.UUID{}, nil
+
+	case domain.TransferSelectionAlbums:
+		var photos []*domain.Photo
+		albumIDs := make([]uuid.UUID, 0, len(transfer.AlbumIDs))
+		for _, id := range transfer.AlbumIDs {
+			album, err := s.albumRepo.GetByID(ctx, id)
+			if err != nil {
+				var appErr apperrors.Error
+				if errors.As(err, &appErr) && appErr.Type == apperrors.NotFound {
+					continue
+				}
+				return nil, nil, err
+			}
+			if album.UserID != owner {
+				continue
+			}
+			albumIDs = append(albumIDs, album.ID)
+
+			albumPhotos, err := collectPages(func(limit, offset int) ([]*domain.Photo, int, error) {
+				return s.photoRepo.GetByAlbumID(ctx, album.ID, limit, offset)
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, photo := range albumPhotos {
+				if photo.UserID == owner {
+					photos = append(photos, photo)
+				}
+			}
+		}
+		return photos, albumIDs, nil
+
+	default:
+		photos, err := collectPages(func(limit, offset int) ([]*domain.Photo, int, error) {
+			return s.photoRepo.GetByUserID(ctx, owner, limit, offset)
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		albums, err := s.albumRepo.GetByUserID(ctx, owner)
+		if err != nil {
+			return nil, nil, err
+		}
+		albumIDs := make([]uuid.UUID, len(albums))
+		for i, album := range albums {
+			albumIDs[i] = album.ID
+		}
+		return photos, albumIDs, nil
+	}
+}
+
+// copyFiles copies the files of photos and their versions under the
+// recipient, where the copies of the transfer are kept together so they can
+// be deleted if it fails.
+func (s *OwnershipTransferService) copyFiles(ctx context.Context, transfer *domain.OwnershipTransfer, photos []*domain.Photo, albumIDs []uuid.UUID) ([]*domain.TransferredPhoto, error) {
+	movedAlbums := make(map[uuid.UUID]bool, len(albumIDs))
+	for _, id := range albumIDs {
+		movedAlbums[id] = true
+	}
+
+	results := make([]*domain.TransferredPhoto, len(photos))
+	errs := make([]error, len(photos))
+	sem := make(chan struct{}, transferCopyConcurrency)
+	var wg sync.WaitGroup
+
+	for i, photo := range photos {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, photo *domain.Photo) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			moved := &domain.TransferredPhoto{Photo: photo}
+			if photo.AlbumID != nil && movedAlbums[*photo.AlbumID] {
+				moved.AlbumID = photo.AlbumID
+			}
+			results[i] = moved
+
+			var err error
+			moved.StoragePath, moved.PublicURL, err = s.storage.CopyTransferredPhoto(ctx, photo.StoragePath, transfer.ToUserID, transfer.ID, photo.FileName)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			versions, err := s.photoRepo.GetVersions(ctx, photo.ID)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			for _, version := range versions {
+				path, url, err := s.storage.CopyTransferredPhoto(ctx, version.StoragePath, transfer.ToUserID, transfer.ID, version.FileName)
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				moved.Versions = append(moved.Versions, domain.TransferredVersion{
+					Version:     version,
+					StoragePath: path,
+					PublicURL:   url,
+				})
+			}
+		}(i, photo)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// failTransfer marks a running transfer failed and deletes the copies made
+// for it.
+func (s *OwnershipTransferService) failTransfer(ctx context.Context, transfer *domain.OwnershipTransfer, cause error) {
+	s.logger.Error().Err(cause).Str("transferID", transfer.ID.String()).Msg("ownership transfer failed")
+
+	s.deleteTransferFiles(ctx, transfer)
+
+	transfer.Status = domain.TransferStatusFailed
+	transfer.Error = "failed to move the photos, nothing was transferred"
+	var appErr apperrors.Error
+	if errors.As(cause, &appErr) && appErr.Type == apperrors.Conflict {
+		transfer.Error = appErr.Message
+	}
+	transfer.UpdatedAt = time.Now()
+	err := s.transferRepo.UpdateStatus(ctx, transfer, domain.TransferStatusRunning)
+	if err != nil {
+		s.logger.Error().Err(err).Str("transferID", transfer.ID.String()).Msg("failed to save transfer failure")
+	}
+
+	s.audit(ctx, nil, AuditTransferFailed, transfer, map[string]interface{}{"error": cause.Error()})
+}
+
+// Run fails the transfers that were cut short, e.g. by a restart, every
+// transferRecoveryInterval until ctx is done, and deletes the copies they
+// left under the recipient. Transfers only run in the process that accepted
+// them, so one that outlived its deadline will never finish and would
+// otherwise stay running for good.
+func (s *OwnershipTransferService) Run(ctx context.Context) {
+	ticker := time.NewTicker(transferRecoveryInterval)
+	defer ticker.Stop()
+
+	for {
+		s.failStaleTransfers(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *OwnershipTransferService) failStaleTransfers(ctx context.Context) {
+	now := time.Now()
+	transfers, err := s.transferRepo.FailStale(ctx, now.Add(-domain.TransferJobTimeout-transferJobGrace), "the transfer was interrupted, nothing was transferred", now)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to fail stale ownership transfers")
+		return
+	}
+
+	for _, transfer := range transfers {
+		s.logger.Warn().
+			Str("transferID", transfer.ID.String()).
+			Msg("stale ownership transfer failed")
+
+		s.deleteTransferFiles(ctx, transfer)
+		s.audit(ctx, nil, AuditTransferFailed, transfer, map[string]interface{}{"error": transfer.Error})
+	}
+}
+
+// deleteTransferFiles deletes the copies made for a transfer that failed.
+// Failures are logged since the transfer has already failed.
+func (s *OwnershipTransferService) deleteTransferFiles(ctx context.Context, transfer *domain.OwnershipTransfer) {
+	if _, err := s.storage.DeleteTransferFiles(ctx, transfer.ToUserID, transfer.ID); err != nil {
+		s.logger.Error().Err(err).Str("transferID", transfer.ID.String()).Msg("failed to delete transferred photo files")
+	}
+}
+
+func (s *OwnershipTransferService) deleteFile(ctx context.Context, storagePath string) {
+	if err := s.storage.DeletePhoto(ctx, storagePath); err != nil {
+		s.logger.Error().Err(err).Str("storagePath", storagePath).Msg("failed to delete transferred photo file")
+	}
+}
+
+// audit records an event about a transfer. Failures are logged since the
+// change being audited has already been saved.
+func (s *OwnershipTransferService) audit(ctx context.Context, actorID *uuid.UUID, action string, transfer *domain.OwnershipTransfer, details map[string]interface{}) {
+	if details == nil {
+		details = make(map[string]interface{})
+	}
+	details["from_user_id"] = transfer.FromUserID
+	details["to_user_id"] = transfer.ToUserID
+
+	event := domain.NewAuditEvent(actorID, action, AuditSubjectTransfer, transfer.ID, details)
+	if err := s.auditRepo.Create(ctx, event); err != nil {
+		s.logger.Error().Err(err).Str("transferID", transfer.ID.String()).Str("action", action).Msg("failed to record audit event")
+	}
+}
+
+// getTransfer returns a transfer the user sent or received.
+func (s *OwnershipTransferService) getTransfer(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*domain.OwnershipTransfer, error) {
+	transfer, err := s.transferRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.FromUserID != userID && transfer.ToUserID != userID {
+		return nil, apperrors.NewWithFormat(apperrors.NotFound, "transfer with id %s not found", id)
+	}
+	return transfer, nil
+}
+
+func (s *OwnershipTransferService) findRecipient(ctx context.Context, recipient string) (*domain.User, error) {
+	recipient = strings.TrimSpace(recipient)
+	var user *domain.User
+	var err error
+	if strings.Contains(recipient, "@") {
+		user, err = s.userRepo.GetByEmail(ctx, recipient)
+	} else {
+		user, err = s.userRepo.GetByUsername(ctx, recipient)
+	}
+	if err != nil {
+		var appErr apperrors.Error
+		if errors.As(err, &appErr) && appErr.Type == apperrors.NotFound {
+			return nil, apperrors.NewWithFormat(apperrors.NotFound, "recipient %s not found", recipient)
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *OwnershipTransferService) username(ctx context.Context, cache map[uuid.UUID]string, userID uuid.UUID) (string, error) {
+	if name, ok := cache[userID]; ok {
+		return name, nil
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	cache[userID] = user.Username
+	return user.Username, nil
+}
+
+func (s *OwnershipTransferService) newResponse(ctx context.Context, transfer *domain.OwnershipTransfer) (*TransferResponse, error) {
+	usernames := make(map[uuid.UUID]string)
+	from, err := s.username(ctx, usernames, transfer.FromUserID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.username(ctx, usernames, transfer.ToUserID)
+	if err != nil {
+		return nil, err
+	}
+	return newTransferResponse(transfer, from, to), nil
+}
+
+func newTransferResponse(transfer *domain.OwnershipTransfer, fromUsername, toUsername string) *TransferResponse {
+	photoIDs := make([]string, len(transfer.PhotoIDs))
+	for i, id := range transfer.PhotoIDs {
+		photoIDs[i] = id.String()
+	}
+	albumIDs := make([]string, len(transfer.AlbumIDs))
+	for i, id := range transfer.AlbumIDs {
+		albumIDs[i] = id.String()
+	}
+
+	return &TransferResponse{
+		ID:           transfer.ID.String(),
+		FromUserID:   transfer.FromUserID.String(),
+		FromUsername: fromUsername,
+		ToUserID:     transfer.ToUserID.String(),
+		ToUsername:   toUsername,
+		Status:       string(transfer.Status),
+		Selection:    transfer.Selection,
+		PhotoIDs:     photoIDs,
+		AlbumIDs:     albumIDs,
+		PhotoCount:   transfer.PhotoCount,
+		Error:        transfer.Error,
+		ExpiresAt:    transfer.ExpiresAt,
+		CompletedAt:  transfer.CompletedAt,
+		CreatedAt:    transfer.CreatedAt,
+		UpdatedAt:    transfer.UpdatedAt,
+	}
+}
@@ -1,49 +1,139 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/mmd-moradi/goup/internal/domain"
+	"github.com/mmd-moradi/goup/internal/geocoding"
 	repositories "github.com/mmd-moradi/goup/internal/repository"
 	"github.com/mmd-moradi/goup/internal/storage"
 	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/mmd-moradi/goup/pkg/mergepatch"
 	"github.com/mmd-moradi/goup/pkg/validator"
 	"github.com/rs/zerolog"
 )
 
+const (
+	// MaxPhotoSize is the largest file accepted for a single photo.
+	MaxPhotoSize = 10 << 20
+	// batchUploadConcurrency bounds how many files of a batch are uploaded
+	// to storage at the same time.
+	batchUploadConcurrency = 4
+)
+
+const (
+	BatchStatusCreated = "created"
+	BatchStatusFailed  = "failed"
+)
+
 type PhotoService struct {
 	photoRepo repositories.PhotoRepository
 	userRepo  repositories.UserRepository
+	albumRepo repositories.AlbumRepository
+	fieldRepo repositories.CustomFieldRepository
 	storage   storage.StorageService
+	authz     *Authorizer
+	geocoder  *geocoding.Geocoder
 	logger    zerolog.Logger
 }
 
 type PhotoUploadInput struct {
-	Title       string `json:"title" validate:"required,max=255"`
-	Description string `json:"description" validate:"max=1000"`
-	FileName    string `json:"file_name" validate:"required"`
-	FileSize    int64  `json:"file_size" validate:"required"`
-	ContentType string `json:"content_type" validate:"required"`
+	Title       string   `json:"title" validate:"required,max=255"`
+	Description string   `json:"description" validate:"max=1000"`
+	FileName    string   `json:"file_name" validate:"required"`
+	FileSize    int64    `json:"file_size" validate:"required"`
+	ContentType string   `json:"content_type" validate:"required"`
+	Latitude    *float64 `json:"latitude,omitempty" validate:"required_with=Longitude,omitempty,gte=-90,lte=90"`
+	Longitude   *float64 `json:"longitude,omitempty" validate:"required_with=Latitude,omitempty,gte=-180,lte=180"`
+	// TakenAt is when the photo was taken, usually read from its EXIF data
+	// by the client.
+	TakenAt *time.Time `json:"taken_at,omitempty"`
+	// CustomFields holds values of the user's custom fields by name.
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
+}
+
+// PhotoBatchItem is a single file of a batch upload. Open is called once,
+// when the file is about to be processed, so only the files currently being
+// uploaded are held in memory.
+type PhotoBatchItem struct {
+	Input PhotoUploadInput
+	Open  func() (io.ReadCloser, error)
+}
+
+type PhotoBatchResult struct {
+	Index    int              `json:"index"`
+	FileName string           `json:"file_name"`
+	Status   string           `json:"status"`
+	Photo    *PhotoResponse   `json:"photo,omitempty"`
+	Error    *apperrors.Error `json:"error,omitempty"`
+}
+
+type PhotoBatchResponse struct {
+	Results   []PhotoBatchResult `json:"results"`
+	Succeeded int                `json:"succeeded"`
+	Failed    int                `json:"failed"`
 }
 
 type PhotoUpdateInput struct {
 	Title       string `json:"title" validate:"max=255"`
 	Description string `json:"description" validate:"max=1000"`
+	Visibility  string `json:"visibility" validate:"omitempty,oneof=private unlisted public"`
+	// CustomFields replaces the values of the photo's custom fields when
+	// set and leaves them unchanged when omitted.
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
+}
+
+// PhotoPatchDocument is the part of a photo a JSON Merge Patch applies to.
+// Setting latitude and longitude to null removes the photo's location, and
+// setting a custom field to null removes its value.
+type PhotoPatchDocument struct {
+	Title        string                 `json:"title" validate:"required,max=255"`
+	Description  string                 `json:"description" validate:"max=1000"`
+	Visibility   string                 `json:"visibility" validate:"required,oneof=private unlisted public"`
+	Latitude     *float64               `json:"latitude,omitempty" validate:"required_with=Longitude,omitempty,gte=-90,lte=90"`
+	Longitude    *float64               `json:"longitude,omitempty" validate:"required_with=Latitude,omitempty,gte=-180,lte=180"`
+	TakenAt      *time.Time             `json:"taken_at,omitempty"`
+	CustomFields map[string]interface{} `json:"custom_fields"`
 }
 
 type PhotoResponse struct {
-	ID          string    `json:"id"`
-	UserID      string    `json:"user_id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	FileName    string    `json:"file_name"`
-	FileSize    int64     `json:"file_size"`
-	ContentType string    `json:"content_type"`
-	PublicURL   string    `json:"public_url"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string `json:"id"`
+	UserID      string `json:"user_id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	FileName    string `json:"file_name"`
+	FileSize    int64  `json:"file_size"`
+	ContentType string `json:"content_type"`
+	// PublicURL is the permanent address of the file in storage. It is only
+	// set for the owner and for public photos; everyone else downloads the
+	// file from /photos/{id}/content, which checks their access every time.
+	PublicURL    string                 `json:"public_url,omitempty"`
+	Visibility   string                 `json:"visibility"`
+	AlbumID      *string                `json:"album_id,omitempty"`
+	Tags         []string               `json:"tags"`
+	Latitude     *float64               `json:"latitude,omitempty"`
+	Longitude    *float64               `json:"longitude,omitempty"`
+	Place        *PlaceResponse         `json:"place,omitempty"`
+	TakenAt      *time.Time             `json:"taken_at,omitempty"`
+	CustomFields map[string]interface{} `json:"custom_fields"`
+	Version      int                    `json:"version"`
+	CreatedAt    time.Time              `json:"created_at"`
+	UpdatedAt    time.Time              `json:"updated_at"`
+}
+
+// ETag is the entity tag of the photo's current version.
+func (p *PhotoResponse) ETag() string {
+	return photoETag(p.Version)
 }
 
 type PhotosResponse struct {
@@ -52,19 +142,35 @@ type PhotosResponse struct {
 	Page       int             `json:"page"`
 	PageSize   int             `json:"page_size"`
 	TotalPages int             `json:"total_pages"`
+	// BucketIndex is set when jumping to a timeline bucket and is the
+	// index in Photos of the bucket's first photo.
+	BucketIndex *int `json:"bucket_index,omitempty"`
+}
+
+type PhotoFeedResponse struct {
+	Photos     []PhotoResponse `json:"photos"`
+	NextCursor string          `json:"next_cursor,omitempty"`
 }
 
 func NewPhotoService(
 	photoRepo repositories.PhotoRepository,
 	userRepo repositories.UserRepository,
+	albumRepo repositories.AlbumRepository,
+	fieldRepo repositories.CustomFieldRepository,
 	storage storage.StorageService,
+	authz *Authorizer,
+	geocoder *geocoding.Geocoder,
 	logger zerolog.Logger,
 ) *PhotoService {
 
 	return &PhotoService{
 		photoRepo: photoRepo,
 		userRepo:  userRepo,
+		albumRepo: albumRepo,
+		fieldRepo: fieldRepo,
 		storage:   storage,
+		authz:     authz,
+		geocoder:  geocoder,
 		logger:    logger,
 	}
 }
@@ -73,10 +179,21 @@ func (s *PhotoService) UploadPhoto(ctx context.Context, input PhotoUploadInput,
 	if err := validator.Validate(input); err != nil {
 		return nil, apperrors.Wrap(err, apperrors.BadRequest)
 	}
+	if input.FileSize > MaxPhotoSize {
+		return nil, apperrors.NewWithFormat(apperrors.BadRequest, "file exceeds the maximum size of %d bytes", MaxPhotoSize)
+	}
 	_, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
+	err = s.authz.AuthorizeCapability(ctx, userID, CapabilityUpload)
+	if err != nil {
+		return nil, err
+	}
+	err = s.validateCustomFields(ctx, userID, input.CustomFields)
+	if err != nil {
+		return nil, err
+	}
 
 	photo := domain.NewPhoto(
 		userID,
@@ -88,12 +205,20 @@ func (s *PhotoService) UploadPhoto(ctx context.Context, input PhotoUploadInput,
 		"",
 		"",
 	)
+	photo.Latitude = input.Latitude
+	photo.Longitude = input.Longitude
+	photo.TakenAt = input.TakenAt
+	if input.CustomFields != nil {
+		photo.CustomFields = input.CustomFields
+	}
 
 	err = s.storage.UploadPhoto(ctx, data, userID, photo)
 	if err != nil {
 		return nil, err
 	}
 
+	s.attachPlace(photo)
+
 	err = s.photoRepo.Create(ctx, photo)
 	if err != nil {
 		cleanUpErr := s.storage.DeletePhoto(ctx, photo.StoragePath)
@@ -108,41 +233,123 @@ func (s *PhotoService) UploadPhoto(ctx context.Context, input PhotoUploadInput,
 		Str("photoID", photo.ID.String()).
 		Msg("photo uploaded successfully")
 
-	return &PhotoResponse{
-		ID:          photo.ID.String(),
-		UserID:      photo.UserID.String(),
-		Title:       photo.Title,
-		Description: photo.Description,
-		FileName:    photo.FileName,
-		FileSize:    photo.FileSize,
-		ContentType: photo.ContentType,
-		PublicURL:   photo.PublicURL,
-		CreatedAt:   photo.CreatedAt,
-		UpdatedAt:   photo.UpdatedAt,
-	}, nil
+	return newPhotoResponse(photo, userID), nil
+}
+
+// UploadPhotos uploads a batch of files with bounded concurrency. A failing
+// file is reported in its result and never aborts the rest of the batch.
+func (s *PhotoService) UploadPhotos(ctx context.Context, items []PhotoBatchItem, userID uuid.UUID) (*PhotoBatchResponse, error) {
+	_, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.authz.AuthorizeCapability(ctx, userID, CapabilityUpload)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]PhotoBatchResult, len(items))
+	sem := make(chan struct{}, batchUploadConcurrency)
+	var wg sync.WaitGroup
+
+	for i, item := range items {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, item PhotoBatchItem) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result := PhotoBatchResult{
+				Index:    i,
+				FileName: item.Input.FileName,
+				Status:   BatchStatusCreated,
+			}
+			photo, err := s.uploadBatchItem(ctx, item, userID)
+			if err != nil {
+				result.Status = BatchStatusFailed
+				result.Error = toAppError(err)
+			} else {
+				result.Photo = photo
+			}
+			results[i] = result
+		}(i, item)
+	}
+	wg.Wait()
+
+	resp := &PhotoBatchResponse{Results: results}
+	for _, result := range results {
+		if result.Status == BatchStatusCreated {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+		}
+	}
+
+	s.logger.Info().
+		Str("userID", userID.String()).
+		Int("succeeded", resp.Succeeded).
+		Int("failed", resp.Failed).
+		Msg("photo batch uploaded successfully")
+
+	return resp, nil
+}
+
+func (s *PhotoService) uploadBatchItem(ctx context.Context, item PhotoBatchItem, userID uuid.UUID) (*PhotoResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, apperrors.New(apperrors.ServiceUnavailable, "upload cancelled")
+	}
+	if item.Input.FileSize > MaxPhotoSize {
+		return nil, apperrors.NewWithFormat(apperrors.BadRequest, "file exceeds the maximum size of %d bytes", MaxPhotoSize)
+	}
+
+	file, err := item.Open()
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.BadRequest, "failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, MaxPhotoSize+1))
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to read file: %v", err)
+	}
+	if len(data) > MaxPhotoSize {
+		return nil, apperrors.NewWithFormat(apperrors.BadRequest, "file exceeds the maximum size of %d bytes", MaxPhotoSize)
+	}
+
+	return s.UploadPhoto(ctx, item.Input, userID, data)
 }
 
 func (s *PhotoService) GetPhotoByID(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*PhotoResponse, error) {
-	photo, err := s.photoRepo.GetByID(ctx, id)
+	photo, err := s.getAuthorizedPhoto(ctx, id, userID, ActionView)
 	if err != nil {
 		return nil, err
 	}
 
-	if photo.UserID != userID {
-		return nil, apperrors.New(apperrors.Forbidden, "You don't have access to this photo")
+	return newPhotoResponse(photo, userID), nil
+}
+
+// GetPhotoContent returns the current file of a photo. Access is checked on
+// every call, so unlike the public URL it stops working as soon as a grant
+// is revoked or the photo is made private.
+func (s *PhotoService) GetPhotoContent(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*PhotoContent, error) {
+	photo, err := s.getAuthorizedPhoto(ctx, id, userID, ActionView)
+	if err != nil {
+		return nil, err
 	}
 
-	return &PhotoResponse{
-		ID:          photo.ID.String(),
-		UserID:      photo.UserID.String(),
-		Title:       photo.Title,
-		Description: photo.Description,
+	data, contentType, err := s.storage.GetPhoto(ctx, photo.StoragePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if contentType == "" {
+		contentType = photo.ContentType
+	}
+
+	return &PhotoContent{
+		Data:        data,
+		ContentType: contentType,
 		FileName:    photo.FileName,
-		FileSize:    photo.FileSize,
-		ContentType: photo.ContentType,
-		PublicURL:   photo.PublicURL,
-		CreatedAt:   photo.CreatedAt,
-		UpdatedAt:   photo.UpdatedAt,
 	}, nil
 }
 
@@ -165,18 +372,7 @@ func (s *PhotoService) GetPhotosByID(ctx context.Context, userID uuid.UUID, page
 
 	photoResponses := make([]PhotoResponse, len(photos))
 	for i, photo := range photos {
-		photoResponses[i] = PhotoResponse{
-			ID:          photo.ID.String(),
-			UserID:      photo.UserID.String(),
-			Title:       photo.Title,
-			Description: photo.Description,
-			FileName:    photo.FileName,
-			FileSize:    photo.FileSize,
-			ContentType: photo.ContentType,
-			PublicURL:   photo.PublicURL,
-			CreatedAt:   photo.CreatedAt,
-			UpdatedAt:   photo.UpdatedAt,
-		}
+		photoResponses[i] = *newPhotoResponse(photo, userID)
 	}
 
 	return &PhotosResponse{
@@ -189,21 +385,80 @@ func (s *PhotoService) GetPhotosByID(ctx context.Context, userID uuid.UUID, page
 
 }
 
-func (s *PhotoService) UpdatePhoto(ctx context.Context, id uuid.UUID, input PhotoUpdateInput, userID uuid.UUID) (*PhotoResponse, error) {
+// SearchPhotosByCustomFields returns the user's photos whose custom fields
+// have all the values in filters, which maps field names to values in text
+// form, e.g. "42" for a number field or "true" for a boolean one.
+func (s *PhotoService) SearchPhotosByCustomFields(ctx context.Context, userID uuid.UUID, filters map[string]string, page, pageSize int) (*PhotosResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	definitions, err := s.fieldRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := customFieldFilter(definitions, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := (page - 1) * pageSize
+
+	photos, total, err := s.photoRepo.GetByCustomFields(ctx, userID, filter, pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	photoResponses := make([]PhotoResponse, len(photos))
+	for i, photo := range photos {
+		photoResponses[i] = *newPhotoResponse(photo, userID)
+	}
+
+	return &PhotosResponse{
+		Photos:     photoResponses,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: (total + pageSize - 1) / pageSize,
+	}, nil
+}
+
+// UpdatePhoto replaces the metadata of a photo. ifMatch holds the entity
+// tags of an If-Match header; when set, the photo is only updated if its
+// current version matches one of them.
+func (s *PhotoService) UpdatePhoto(ctx context.Context, id uuid.UUID, input PhotoUpdateInput, ifMatch []string, userID uuid.UUID) (*PhotoResponse, error) {
 	if err := validator.Validate(input); err != nil {
 		return nil, apperrors.Wrap(err, apperrors.BadRequest)
 	}
 
-	photo, err := s.photoRepo.GetByID(ctx, id)
+	photo, err := s.getAuthorizedPhoto(ctx, id, userID, ActionEdit)
+	if err != nil {
+		return nil, err
+	}
 
+	err = checkIfMatch(photo, ifMatch)
 	if err != nil {
 		return nil, err
 	}
 
-	if photo.UserID != userID {
-		return nil, apperrors.New(apperrors.Forbidden, "You don't have access to this photo")
+	if input.CustomFields != nil {
+		err = s.validateCustomFields(ctx, photo.UserID, input.CustomFields)
+		if err != nil {
+			return nil, err
+		}
+		photo.CustomFields = input.CustomFields
 	}
 
+	if input.Visibility != "" {
+		err = s.authorizeVisibility(ctx, photo, domain.Visibility(input.Visibility), userID)
+		if err != nil {
+			return nil, err
+		}
+		photo.Visibility = domain.Visibility(input.Visibility)
+	}
 	photo.Title = input.Title
 	photo.Description = input.Description
 	photo.UpdatedAt = time.Now()
@@ -219,29 +474,113 @@ func (s *PhotoService) UpdatePhoto(ctx context.Context, id uuid.UUID, input Phot
 		Str("photoID", photo.ID.String()).
 		Msg("photo updated successfully")
 
-	return &PhotoResponse{
-		ID:          photo.ID.String(),
-		UserID:      photo.UserID.String(),
-		Title:       photo.Title,
-		Description: photo.Description,
-		FileName:    photo.FileName,
-		FileSize:    photo.FileSize,
-		ContentType: photo.ContentType,
-		PublicURL:   photo.PublicURL,
-		CreatedAt:   photo.CreatedAt,
-		UpdatedAt:   photo.UpdatedAt,
-	}, nil
+	return newPhotoResponse(photo, userID), nil
+
+}
+
+// PatchPhoto applies a JSON Merge Patch (RFC 7396) to the metadata of a
+// photo. Members missing from the patch are left unchanged and null resets
+// a member. ifMatch is handled as in UpdatePhoto.
+func (s *PhotoService) PatchPhoto(ctx context.Context, id uuid.UUID, patch []byte, ifMatch []string, userID uuid.UUID) (*PhotoResponse, error) {
+	photo, err := s.getAuthorizedPhoto(ctx, id, userID, ActionEdit)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkIfMatch(photo, ifMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := json.Marshal(PhotoPatchDocument{
+		Title:        photo.Title,
+		Description:  photo.Description,
+		Visibility:   string(photo.Visibility),
+		Latitude:     photo.Latitude,
+		Longitude:    photo.Longitude,
+		TakenAt:      photo.TakenAt,
+		CustomFields: photo.CustomFields,
+	})
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to encode photo: %v", err)
+	}
+
+	patched, err := mergepatch.Apply(current, patch)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.BadRequest)
+	}
+
+	var document PhotoPatchDocument
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&document)
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.BadRequest, "invalid merge patch: %v", err)
+	}
+	if err := validator.Validate(document); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.BadRequest)
+	}
+	err = s.validateCustomFields(ctx, photo.UserID, document.CustomFields)
+	if err != nil {
+		return nil, err
+	}
+	err = s.authorizeVisibility(ctx, photo, domain.Visibility(document.Visibility), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	photo.Title = document.Title
+	photo.Description = document.Description
+	photo.Visibility = domain.Visibility(document.Visibility)
+	photo.Latitude = document.Latitude
+	photo.Longitude = document.Longitude
+	s.attachPlace(photo)
+	photo.TakenAt = document.TakenAt
+	photo.CustomFields = document.CustomFields
+	if photo.CustomFields == nil {
+		photo.CustomFields = map[string]interface{}{}
+	}
+	photo.UpdatedAt = time.Now()
+
+	err = s.photoRepo.Update(ctx, photo)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("userID", userID.String()).
+		Str("photoID", photo.ID.String()).
+		Msg("photo patched successfully")
 
+	return newPhotoResponse(photo, userID), nil
+}
+
+// authorizeVisibility checks that the user may change the visibility of
+// photo to visibility. Editors may change a photo's metadata, but publishing
+// or hiding it is sharing and reserved for the owner.
+func (s *PhotoService) authorizeVisibility(ctx context.Context, photo *domain.Photo, visibility domain.Visibility, userID uuid.UUID) error {
+	if visibility == photo.Visibility {
+		return nil
+	}
+	err := s.authz.AuthorizePhoto(ctx, photo, userID, ActionShare)
+	if err != nil {
+		if appErr := toAppError(err); appErr.Type == apperrors.Forbidden {
+			return apperrors.New(apperrors.Forbidden, "only the owner can change the visibility of a photo")
+		}
+		return err
+	}
+	return nil
 }
 
 func (s *PhotoService) DeletePhoto(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
-	photo, err := s.photoRepo.GetByID(ctx, id)
+	photo, err := s.getAuthorizedPhoto(ctx, id, userID, ActionDelete)
 	if err != nil {
 		return err
 	}
 
-	if photo.UserID != userID {
-		return apperrors.New(apperrors.Forbidden, "You don't have access to this photo")
+	versions, err := s.photoRepo.GetVersions(ctx, id)
+	if err != nil {
+		return err
 	}
 
 	err = s.storage.DeletePhoto(ctx, photo.StoragePath)
@@ -254,6 +593,14 @@ func (s *PhotoService) DeletePhoto(ctx context.Context, id uuid.UUID, userID uui
 		return err
 	}
 
+	// Version rows are removed with the photo, their files are not.
+	for _, version := range versions {
+		err := s.storage.DeletePhoto(ctx, version.StoragePath)
+		if err != nil {
+			s.logger.Error().Err(err).Str("storagePath", version.StoragePath).Msg("failed to delete photo version file")
+		}
+	}
+
 	s.logger.Info().
 		Str("userID", userID.String()).
 		Str("photoID", photo.ID.String()).
@@ -262,3 +609,254 @@ func (s *PhotoService) DeletePhoto(ctx context.Context, id uuid.UUID, userID uui
 	return nil
 
 }
+
+// GetUserGallery returns the public photos of the user with the given username.
+// It does not require authentication.
+func (s *PhotoService) GetUserGallery(ctx context.Context, username string, page, pageSize int) (*PhotosResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	user, err := s.userRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := (page - 1) * pageSize
+
+	photos, total, err := s.photoRepo.GetPublicByUserID(ctx, user.ID, pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := (total + pageSize - 1) / pageSize
+
+	photoResponses := make([]PhotoResponse, len(photos))
+	for i, photo := range photos {
+		photoResponses[i] = *newPhotoResponse(photo, uuid.Nil)
+	}
+
+	return &PhotosResponse{
+		Photos:     photoResponses,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// GetSharedWithMe returns the photos other users granted the user access to,
+// either directly or through a shared album.
+func (s *PhotoService) GetSharedWithMe(ctx context.Context, userID uuid.UUID, page, pageSize int) (*PhotosResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	offset := (page - 1) * pageSize
+
+	photos, total, err := s.photoRepo.GetSharedWithUser(ctx, userID, pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := (total + pageSize - 1) / pageSize
+
+	photoResponses := make([]PhotoResponse, len(photos))
+	for i, photo := range photos {
+		photoResponses[i] = *newPhotoResponse(photo, userID)
+	}
+
+	return &PhotosResponse{
+		Photos:     photoResponses,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// GetPublicFeed returns the most recent public photos of all users. Pass the
+// NextCursor of a previous response to fetch the following page.
+func (s *PhotoService) GetPublicFeed(ctx context.Context, cursor string, limit int) (*PhotoFeedResponse, error) {
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	var after *domain.PhotoCursor
+	if cursor != "" {
+		decoded, err := decodePhotoCursor(cursor)
+		if err != nil {
+			return nil, apperrors.New(apperrors.BadRequest, "invalid cursor")
+		}
+		after = decoded
+	}
+
+	// Fetch one extra row to find out whether there is a next page.
+	photos, err := s.photoRepo.ListPublic(ctx, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	nextCursor := ""
+	if len(photos) > limit {
+		photos = photos[:limit]
+		last := photos[len(photos)-1]
+		nextCursor = encodePhotoCursor(&domain.PhotoCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	photoResponses := make([]PhotoResponse, len(photos))
+	for i, photo := range photos {
+		photoResponses[i] = *newPhotoResponse(photo, uuid.Nil)
+	}
+
+	return &PhotoFeedResponse{
+		Photos:     photoResponses,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+func (s *PhotoService) getAuthorizedPhoto(ctx context.Context, id uuid.UUID, userID uuid.UUID, action Action) (*domain.Photo, error) {
+	photo, err := s.photoRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authz.AuthorizePhoto(ctx, photo, userID, action); err != nil {
+		return nil, err
+	}
+
+	return photo, nil
+}
+
+// newPhotoResponse builds the response for photo as seen by viewerID, which
+// is uuid.Nil for anonymous requests.
+func newPhotoResponse(photo *domain.Photo, viewerID uuid.UUID) *PhotoResponse {
+	var albumID *string
+	if photo.AlbumID != nil {
+		id := photo.AlbumID.String()
+		albumID = &id
+	}
+	tags := photo.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	customFields := photo.CustomFields
+	if customFields == nil {
+		customFields = map[string]interface{}{}
+	}
+
+	return &PhotoResponse{
+		ID:           photo.ID.String(),
+		UserID:       photo.UserID.String(),
+		Title:        photo.Title,
+		Description:  photo.Description,
+		FileName:     photo.FileName,
+		FileSize:     photo.FileSize,
+		ContentType:  photo.ContentType,
+		PublicURL:    photoPublicURL(photo, viewerID),
+		Visibility:   string(photo.Visibility),
+		AlbumID:      albumID,
+		Tags:         tags,
+		Latitude:     photo.Latitude,
+		Longitude:    photo.Longitude,
+		Place:        newPlaceResponse(photo.PlaceName, photo.CountryCode, photo.CountryName),
+		TakenAt:      photo.TakenAt,
+		CustomFields: customFields,
+		Version:      photo.Version,
+		CreatedAt:    photo.CreatedAt,
+		UpdatedAt:    photo.UpdatedAt,
+	}
+}
+
+// photoPublicURL returns the storage URL of photo if viewerID may keep it.
+// The URL can't be revoked, so it is withheld from users who only see the
+// photo through a grant, a share link or an unlisted link.
+func photoPublicURL(photo *domain.Photo, viewerID uuid.UUID) string {
+	if (viewerID != uuid.Nil && photo.UserID == viewerID) || photo.Visibility == domain.VisibilityPublic {
+		return photo.PublicURL
+	}
+	return ""
+}
+
+func encodePhotoCursor(cursor *domain.PhotoCursor) string {
+	raw := cursor.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + cursor.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodePhotoCursor(cursor string) (*domain.PhotoCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	createdAtStr, idStr, found := strings.Cut(string(raw), "|")
+	if !found {
+		return nil, errors.New("malformed cursor")
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtStr)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PhotoCursor{CreatedAt: createdAt, ID: id}, nil
+}
+
+// toAppError converts err into an apperrors.Error, hiding the details of
+// unexpected errors the same way response.Error does.
+func toAppError(err error) *apperrors.Error {
+	var appErr apperrors.Error
+	if errors.As(err, &appErr) {
+		return &appErr
+	}
+	appErr = apperrors.New(apperrors.InternalServer, "An unexpected error occurred")
+	return &appErr
+}
+
+func photoETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// checkIfMatch reports a PreconditionFailed error unless ifMatch is empty,
+// is "*", or contains the current entity tag of photo. Weak entity tags
+// never match, as If-Match uses strong comparison.
+func checkIfMatch(photo *domain.Photo, ifMatch []string) error {
+	if len(ifMatch) == 0 {
+		return nil
+	}
+
+	current := photoETag(photo.Version)
+	for _, tag := range ifMatch {
+		if tag == "*" || tag == current {
+			return nil
+		}
+	}
+
+	return apperrors.New(apperrors.PreconditionFailed, "photo has been modified, fetch it again and retry")
+}
+
+// validateCustomFields checks custom field values against the fields defined
+// by the photo's owner.
+func (s *PhotoService) validateCustomFields(ctx context.Context, ownerID uuid.UUID, values map[string]interface{}) error {
+	definitions, err := s.fieldRepo.GetByUserID(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+
+	err = validator.ValidateFields(customFieldRules(definitions), values)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.BadRequest)
+	}
+	return nil
+}
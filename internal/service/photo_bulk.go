@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/mmd-moradi/goup/internal/domain"
+	repositories "github.com/mmd-moradi/goup/internal/repository"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/mmd-moradi/goup/pkg/validator"
+)
+
+const (
+	BulkOperationDelete           = "delete"
+	BulkOperationUpdateMetadata   = "update-metadata"
+	BulkOperationAddTags          = "add-tags"
+	BulkOperationMoveToAlbum      = "move-to-album"
+	BulkOperationChangeVisibility = "change-visibility"
+)
+
+const (
+	BulkStatusSucceeded = "succeeded"
+	BulkStatusFailed    = "failed"
+)
+
+// PhotoBulkInput describes an operation applied to many photos at once. Only
+// the fields used by Operation are read: Title and Description for
+// update-metadata (nil leaves the field unchanged), Tags for add-tags,
+// AlbumID for move-to-album (nil removes the photos from their album) and
+// Visibility for change-visibility.
+type PhotoBulkInput struct {
+	IDs         []string `json:"ids" validate:"required,min=1,max=100,dive,uuid"`
+	Operation   string   `json:"operation" validate:"required,oneof=delete update-metadata add-tags move-to-album change-visibility"`
+	Title       *string  `json:"title,omitempty" validate:"omitempty,max=255"`
+	Description *string  `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Tags        []string `json:"tags,omitempty" validate:"omitempty,max=20,dive,required,max=64"`
+	AlbumID     *string  `json:"album_id,omitempty" validate:"omitempty,uuid"`
+	Visibility  string   `json:"visibility,omitempty" validate:"omitempty,oneof=private unlisted public"`
+}
+
+type PhotoBulkResult struct {
+	ID     string           `json:"id"`
+	Status string           `json:"status"`
+	Photo  *PhotoResponse   `json:"photo,omitempty"`
+	Error  *apperrors.Error `json:"error,omitempty"`
+}
+
+type PhotoBulkResponse struct {
+	Operation string            `json:"operation"`
+	Results   []PhotoBulkResult `json:"results"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+}
+
+// BulkUpdatePhotos applies one operation to every photo in input.IDs. The
+// database changes run in a single transaction; photos the user does not own
+// or that do not exist are reported as failed and skipped without aborting
+// the others. Files of deleted photos are removed from storage only after the
+// transaction has committed.
+func (s *PhotoService) BulkUpdatePhotos(ctx context.Context, input PhotoBulkInput, userID uuid.UUID) (*PhotoBulkResponse, error) {
+	if err := validator.Validate(input); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.BadRequest)
+	}
+
+	ids, err := parseBulkIDs(input.IDs)
+	if err != nil {
+		return nil, err
+	}
+
+	apply, err := s.bulkOperation(ctx, input, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]PhotoBulkResult, len(ids))
+	var deletedPaths []string
+
+	err = s.photoRepo.WithTx(ctx, pgx.TxOptions{}, func(txRepo repositories.PhotoRepository) error {
+		deletedPaths = nil
+		for i, id := range ids {
+			results[i] = PhotoBulkResult{ID: id.String(), Status: BulkStatusSucceeded}
+
+			photo, err := txRepo.GetByID(ctx, id)
+			if err == nil && photo.UserID != userID {
+				err = apperrors.New(apperrors.Forbidden, "you do not own this photo")
+			}
+			// Version rows are removed with the photo, so their files are
+			// looked up before deleting.
+			var versions []*domain.PhotoVersion
+			if err == nil && input.Operation == BulkOperationDelete {
+				versions, err = txRepo.GetVersions(ctx, id)
+			}
+			if err == nil {
+				err = apply(ctx, txRepo, photo)
+			}
+			if err != nil {
+				appErr := toAppError(err)
+				if appErr.Type == apperrors.InternalServer {
+					return err
+				}
+				results[i].Status = BulkStatusFailed
+				results[i].Error = appErr
+				continue
+			}
+
+			if input.Operation == BulkOperationDelete {
+				deletedPaths = append(deletedPaths, photo.StoragePath)
+				for _, version := range versions {
+					deletedPaths = append(deletedPaths, version.StoragePath)
+				}
+			} else {
+				results[i].Photo = newPhotoResponse(photo, userID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range deletedPaths {
+		if err := s.storage.DeletePhoto(ctx, path); err != nil {
+			s.logger.Error().Err(err).Str("storagePath", path).Msg("failed to delete photo file after bulk delete")
+		}
+	}
+
+	resp := &PhotoBulkResponse{
+		Operation: input.Operation,
+		Results:   results,
+	}
+	for _, result := range results {
+		if result.Status == BulkStatusSucceeded {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+		}
+	}
+
+	s.logger.Info().
+		Str("userID", userID.String()).
+		Str("operation", input.Operation).
+		Int("succeeded", resp.Succeeded).
+		Int("failed", resp.Failed).
+		Msg("bulk photo operation completed successfully")
+
+	return resp, nil
+}
+
+// bulkOperation validates the operation specific fields of input and returns
+// the function applying the operation to a single photo.
+func (s *PhotoService) bulkOperation(ctx context.Context, input PhotoBulkInput, userID uuid.UUID) (func(context.Context, repositories.PhotoRepository, *domain.Photo) error, error) {
+	switch input.Operation {
+	case BulkOperationDelete:
+		return func(ctx context.Context, repo repositories.PhotoRepository, photo *domain.Photo) error {
+			return repo.Delete(ctx, photo.ID)
+		}, nil
+
+	case BulkOperationUpdateMetadata:
+		if input.Title == nil && input.Description == nil {
+			return nil, apperrors.New(apperrors.BadRequest, "title or description is required for update-metadata")
+		}
+		if input.Title != nil && strings.TrimSpace(*input.Title) == "" {
+			return nil, apperrors.New(apperrors.BadRequest, "title cannot be empty")
+		}
+		return func(ctx context.Context, repo repositories.PhotoRepository, photo *domain.Photo) error {
+			if input.Title != nil {
+				photo.Title = *input.Title
+			}
+			if input.Description != nil {
+				photo.Description = *input.Description
+			}
+			photo.UpdatedAt = time.Now()
+			return repo.Update(ctx, photo)
+		}, nil
+
+	case BulkOperationAddTags:
+		tags := normalizeTags(input.Tags)
+		if len(tags) == 0 {
+			return nil, apperrors.New(apperrors.BadRequest, "tags are required for add-tags")
+		}
+		return func(ctx context.Context, repo repositories.PhotoRepository, photo *domain.Photo) error {
+			photo.UpdatedAt = time.Now()
+			return repo.AddTags(ctx, photo, tags)
+		}, nil
+
+	case BulkOperationMoveToAlbum:
+		var albumID *uuid.UUID
+		if input.AlbumID != nil {
+			id, err := uuid.Parse(*input.AlbumID)
+			if err != nil {
+				return nil, apperrors.New(apperrors.BadRequest, "invalid album ID")
+			}
+			album, err := s.albumRepo.GetByID(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			if album.UserID != userID {
+				return nil, apperrors.New(apperrors.Forbidden, "you do not own this album")
+			}
+			albumID = &album.ID
+		}
+		return func(ctx context.Context, repo repositories.PhotoRepository, photo *domain.Photo) error {
+			photo.AlbumID = albumID
+			photo.UpdatedAt = time.Now()
+			return repo.SetAlbum(ctx, photo)
+		}, nil
+
+	case BulkOperationChangeVisibility:
+		if input.Visibility == "" {
+			return nil, apperrors.New(apperrors.BadRequest, "visibility is required for change-visibility")
+		}
+		return func(ctx context.Context, repo repositories.PhotoRepository, photo *domain.Photo) error {
+			photo.Visibility = domain.Visibility(input.Visibility)
+			photo.UpdatedAt = time.Now()
+			return repo.Update(ctx, photo)
+		}, nil
+
+	default:
+		return nil, apperrors.NewWithFormat(apperrors.BadRequest, "unsupported operation %q", input.Operation)
+	}
+}
+
+// parseBulkIDs parses ids and drops duplicates, keeping the request order.
+func parseBulkIDs(ids []string) ([]uuid.UUID, error) {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	result := make([]uuid.UUID, 0, len(ids))
+	for _, raw := range ids {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, apperrors.NewWithFormat(apperrors.BadRequest, "invalid photo ID %q", raw)
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result, nil
+}
+
+// normalizeTags lowercases and trims tags and removes duplicates.
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/domain"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+)
+
+const (
+	defaultGeoSearchLimit = 100
+	maxGeoSearchLimit     = 500
+	defaultNearRadius     = 1000
+	maxNearRadius         = 100000
+	maxClusterZoom        = 22
+	// clusterCellsPerTile is how many grid cells a 256 pixel map tile is
+	// divided into per axis, so a cluster covers roughly 64x64 pixels.
+	clusterCellsPerTile = 4
+)
+
+// PhotoGeoSearchInput selects photos either inside BBox, formatted as
+// "minLng,minLat,maxLng,maxLat", or within Radius meters of Near, formatted
+// as "lat,lng". Exactly one of BBox and Near must be set.
+type PhotoGeoSearchInput struct {
+	BBox   string
+	Near   string
+	Radius float64
+	Limit  int
+}
+
+type PhotoGeoSearchResponse struct {
+	Photos []PhotoResponse `json:"photos"`
+	// Truncated is set when more photos matched than the limit allowed.
+	Truncated bool `json:"truncated"`
+}
+
+// GeoJSONFeatureCollection is a GeoJSON (RFC 7946) FeatureCollection of
+// photo clusters.
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	BBox     []float64        `json:"bbox,omitempty"`
+	Features []GeoJSONFeature `json:"features"`
+}
+
+type GeoJSONFeature struct {
+	Type       string               `json:"type"`
+	Geometry   GeoJSONPoint         `json:"geometry"`
+	Properties GeoClusterProperties `json:"properties"`
+}
+
+// GeoJSONPoint holds its coordinates in GeoJSON order: longitude, latitude.
+type GeoJSONPoint struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+// GeoClusterProperties describes a cluster. PhotoID is the most recent photo
+// of the cluster and Cluster is false when it holds a single photo.
+type GeoClusterProperties struct {
+	Cluster bool   `json:"cluster"`
+	Count   int    `json:"count"`
+	PhotoID string `json:"photo_id"`
+}
+
+// SearchPhotosByLocation returns the user's geotagged photos in a bounding
+// box, newest first, or around a point, nearest first.
+func (s *PhotoService) SearchPhotosByLocation(ctx context.Context, input PhotoGeoSearchInput, userID uuid.UUID) (*PhotoGeoSearchResponse, error) {
+	limit := input.Limit
+	if limit < 1 {
+		limit = defaultGeoSearchLimit
+	}
+	if limit > maxGeoSearchLimit {
+		limit = maxGeoSearchLimit
+	}
+
+	var photos []*domain.Photo
+	switch {
+	case input.BBox != "" && input.Near != "":
+		return nil, apperrors.New(apperrors.BadRequest, "bbox and near cannot be combined")
+
+	case input.BBox != "":
+		box, err := ParseBoundingBox(input.BBox)
+		if err != nil {
+			return nil, err
+		}
+		// One extra photo tells whether the result was truncated.
+		photos, err = s.photoRepo.GetInBoundingBox(ctx, userID, box, limit+1)
+		if err != nil {
+			return nil, err
+		}
+
+	case input.Near != "":
+		lat, lng, err := parseGeoPoint(input.Near)
+		if err != nil {
+			return nil, err
+		}
+		radius := input.Radius
+		if radius == 0 {
+			radius = defaultNearRadius
+		}
+		if radius < 0 || radius > maxNearRadius {
+			return nil, apperrors.NewWithFormat(apperrors.BadRequest, "radius must be between 0 and %d meters", maxNearRadius)
+		}
+		photos, err = s.photoRepo.GetNear(ctx, userID, lat, lng, radius, limit+1)
+		if err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, apperrors.New(apperrors.BadRequest, "bbox or near is required")
+	}
+
+	resp := &PhotoGeoSearchResponse{}
+	if len(photos) > limit {
+		photos = photos[:limit]
+		resp.Truncated = true
+	}
+	resp.Photos = make([]PhotoResponse, len(photos))
+	for i, photo := range photos {
+		resp.Photos[i] = *newPhotoResponse(photo, userID)
+	}
+
+	return resp, nil
+}
+
+// ClusterPhotos groups the user's geotagged photos in bbox for display on a
+// map at the given zoom level. Photos are grouped by a grid whose cells get
+// smaller as the zoom increases, and every cell with photos becomes a point
+// at the average position of its photos.
+func (s *PhotoService) ClusterPhotos(ctx context.Context, bbox string, zoom int, userID uuid.UUID) (*GeoJSONFeatureCollection, error) {
+	box, err := ParseBoundingBox(bbox)
+	if err != nil {
+		return nil, err
+	}
+	if zoom < 0 || zoom > maxClusterZoom {
+		return nil, apperrors.NewWithFormat(apperrors.BadRequest, "zoom must be between 0 and %d", maxClusterZoom)
+	}
+
+	cellSize := 360 / (math.Exp2(float64(zoom)) * clusterCellsPerTile)
+	clusters, err := s.photoRepo.GetClusters(ctx, userID, box, cellSize)
+	if err != nil {
+		return nil, err
+	}
+
+	features := make([]GeoJSONFeature, len(clusters))
+	for i, cluster := range clusters {
+		features[i] = GeoJSONFeature{
+			Type: "Feature",
+			Geometry: GeoJSONPoint{
+				Type:        "Point",
+				Coordinates: []float64{cluster.Longitude, cluster.Latitude},
+			},
+			Properties: GeoClusterProperties{
+				Cluster: cluster.Count > 1,
+				Count:   cluster.Count,
+				PhotoID: cluster.PhotoID.String(),
+			},
+		}
+	}
+
+	return &GeoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		BBox:     []float64{box.MinLng, box.MinLat, box.MaxLng, box.MaxLat},
+		Features: features,
+	}, nil
+}
+
+// ParseBoundingBox parses a box in the GeoJSON bbox order
+// "minLng,minLat,maxLng,maxLat". minLng may be greater than maxLng for boxes
+// crossing the antimeridian.
+func ParseBoundingBox(value string) (domain.BoundingBox, error) {
+	values, err := parseCoordinates(value, 4)
+	if err != nil {
+		return domain.BoundingBox{}, apperrors.New(apperrors.BadRequest, "bbox must be formatted as minLng,minLat,maxLng,maxLat")
+	}
+
+	box := domain.BoundingBox{
+		MinLng: values[0],
+		MinLat: values[1],
+		MaxLng: values[2],
+		MaxLat: values[3],
+	}
+	if !validLatitude(box.MinLat) || !validLatitude(box.MaxLat) || !validLongitude(box.MinLng) || !validLongitude(box.MaxLng) {
+		return domain.BoundingBox{}, apperrors.New(apperrors.BadRequest, "bbox coordinates are out of range")
+	}
+	if box.MinLat > box.MaxLat {
+		return domain.BoundingBox{}, apperrors.New(apperrors.BadRequest, "bbox minimum latitude is greater than its maximum")
+	}
+
+	return box, nil
+}
+
+// parseGeoPoint parses a point formatted as "lat,lng".
+func parseGeoPoint(value string) (float64, float64, error) {
+	values, err := parseCoordinates(value, 2)
+	if err != nil {
+		return 0, 0, apperrors.New(apperrors.BadRequest, "near must be formatted as lat,lng")
+	}
+	if !validLatitude(values[0]) || !validLongitude(values[1]) {
+		return 0, 0, apperrors.New(apperrors.BadRequest, "near coordinates are out of range")
+	}
+	return values[0], values[1], nil
+}
+
+func parseCoordinates(value string, count int) ([]float64, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != count {
+		return nil, strconv.ErrSyntax
+	}
+
+	values := make([]float64, count)
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, err
+		}
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, strconv.ErrSyntax
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func validLatitude(lat float64) bool {
+	return lat >= -90 && lat <= 90
+}
+
+func validLongitude(lng float64) bool {
+	return lng >= -180 && lng <= 180
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mmd-moradi/goup/internal/domain"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+)
+
+// assertBadRequest fails the test unless err is a BadRequest whose message
+// contains wantMessage.
+func assertBadRequest(t *testing.T, err error, wantMessage string) {
+	t.Helper()
+
+	var appErr apperrors.Error
+	if !errors.As(err, &appErr) {
+		t.Fatalf("expected an apperrors.Error, got %T: %v", err, err)
+	}
+	if appErr.Type != apperrors.BadRequest {
+		t.Errorf("error type = %s, want %s", appErr.Type, apperrors.BadRequest)
+	}
+	if !strings.Contains(appErr.Message, wantMessage) {
+		t.Errorf("error message = %q, want it to contain %q", appErr.Message, wantMessage)
+	}
+}
+
+func TestParseBoundingBox(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		want        domain.BoundingBox
+		wantMessage string
+	}{
+		{
+			name:  "valid",
+			value: "13.3,52.4,13.5,52.6",
+			want:  domain.BoundingBox{MinLng: 13.3, MinLat: 52.4, MaxLng: 13.5, MaxLat: 52.6},
+		},
+		{
+			name:  "spaces around values",
+			value: " -10 , -20 , 10 , 20 ",
+			want:  domain.BoundingBox{MinLng: -10, MinLat: -20, MaxLng: 10, MaxLat: 20},
+		},
+		{
+			name:  "whole world",
+			value: "-180,-90,180,90",
+			want:  domain.BoundingBox{MinLng: -180, MinLat: -90, MaxLng: 180, MaxLat: 90},
+		},
+		{
+			name:  "crossing the antimeridian",
+			value: "170,-10,-170,10",
+			want:  domain.BoundingBox{MinLng: 170, MinLat: -10, MaxLng: -170, MaxLat: 10},
+		},
+		{name: "empty", value: "", wantMessage: "must be formatted as"},
+		{name: "too few values", value: "1,2,3", wantMessage: "must be formatted as"},
+		{name: "too many values", value: "1,2,3,4,5", wantMessage: "must be formatted as"},
+		{name: "not a number", value: "a,2,3,4", wantMessage: "must be formatted as"},
+		{name: "empty value", value: "1,,3,4", wantMessage: "must be formatted as"},
+		{name: "NaN", value: "NaN,2,3,4", wantMessage: "must be formatted as"},
+		{name: "infinity", value: "1,2,+Inf,4", wantMessage: "must be formatted as"},
+		{name: "latitude out of range", value: "0,-91,10,10", wantMessage: "out of range"},
+		{name: "longitude out of range", value: "0,0,180.5,10", wantMessage: "out of range"},
+		{name: "latitudes swapped", value: "0,20,10,10", wantMessage: "minimum latitude is greater"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBoundingBox(tt.value)
+			if tt.wantMessage != "" {
+				assertBadRequest(t, err, tt.wantMessage)
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseBoundingBox(%q) failed: %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseBoundingBox(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGeoPoint(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		wantLat     float64
+		wantLng     float64
+		wantMessage string
+	}{
+		{name: "valid", value: "52.52,13.405", wantLat: 52.52, wantLng: 13.405},
+		{name: "spaces around values", value: " -33.9 , 151.2 ", wantLat: -33.9, wantLng: 151.2},
+		{name: "poles and antimeridian", value: "90,-180", wantLat: 90, wantLng: -180},
+		{name: "empty", value: "", wantMessage: "must be formatted as lat,lng"},
+		{name: "one value", value: "52.52", wantMessage: "must be formatted as lat,lng"},
+		{name: "three values", value: "1,2,3", wantMessage: "must be formatted as lat,lng"},
+		{name: "not a number", value: "north,13", wantMessage: "must be formatted as lat,lng"},
+		{name: "NaN", value: "52,NaN", wantMessage: "must be formatted as lat,lng"},
+		{name: "latitude out of range", value: "90.1,0", wantMessage: "out of range"},
+		{name: "longitude out of range", value: "0,-181", wantMessage: "out of range"},
+		{name: "lng,lat order", value: "151.2,-33.9", wantMessage: "out of range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lat, lng, err := parseGeoPoint(tt.value)
+			if tt.wantMessage != "" {
+				assertBadRequest(t, err, tt.wantMessage)
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseGeoPoint(%q) failed: %v", tt.value, err)
+			}
+			if lat != tt.wantLat || lng != tt.wantLng {
+				t.Errorf("parseGeoPoint(%q) = %v,%v, want %v,%v", tt.value, lat, lng, tt.wantLat, tt.wantLng)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"mime"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/urlfetch"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/mmd-moradi/goup/pkg/validator"
+	"github.com/rs/zerolog"
+)
+
+// PhotoImportService saves images fetched from the web as photos.
+type PhotoImportService struct {
+	photoService *PhotoService
+	fetcher      *urlfetch.Fetcher
+	logger       zerolog.Logger
+}
+
+type PhotoImportInput struct {
+	URL         string `json:"url" validate:"required,url,max=2048"`
+	Title       string `json:"title" validate:"max=255"`
+	Description string `json:"description" validate:"max=1000"`
+}
+
+func NewPhotoImportService(photoService *PhotoService, fetcher *urlfetch.Fetcher, logger zerolog.Logger) *PhotoImportService {
+	return &PhotoImportService{
+		photoService: photoService,
+		fetcher:      fetcher,
+		logger:       logger,
+	}
+}
+
+// ImportPhoto fetches the image at input.URL and uploads it like a file sent
+// by the user. The title defaults to the file name of the URL. Users who
+// can't upload are refused before anything is fetched.
+func (s *PhotoImportService) ImportPhoto(ctx context.Context, input PhotoImportInput, userID uuid.UUID) (*PhotoResponse, error) {
+	if err := validator.Validate(input); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.BadRequest)
+	}
+	if err := s.photoService.authz.AuthorizeCapability(ctx, userID, CapabilityUpload); err != nil {
+		return nil, err
+	}
+
+	result, err := s.fetcher.Fetch(ctx, input.URL)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(result.ContentType, "image/") {
+		return nil, apperrors.NewWithFormat(apperrors.BadRequest, "URL does not point to an image, got %s", result.ContentType)
+	}
+
+	fileName := importFileName(result)
+	title := input.Title
+	if title == "" {
+		title = fileName
+	}
+
+	photo, err := s.photoService.UploadPhoto(ctx, PhotoUploadInput{
+		Title:       title,
+		Description: input.Description,
+		FileName:    fileName,
+		FileSize:    int64(len(result.Data)),
+		ContentType: result.ContentType,
+	}, userID, result.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("userID", userID.String()).
+		Str("photoID", photo.ID).
+		Msg("photo imported successfully")
+
+	return photo, nil
+}
+
+// importFileName names an imported file after its URL, adding an extension
+// matching its content type when the URL has none.
+func importFileName(result *urlfetch.Result) string {
+	name := result.FileName
+	if name == "" || name == "/" || name == "." {
+		name = "import"
+	}
+	if !strings.Contains(name, ".") {
+		if exts, err := mime.ExtensionsByType(result.ContentType); err == nil && len(exts) > 0 {
+			name += exts[0]
+		}
+	}
+	if len(name) > 255 {
+		name = name[len(name)-255:]
+	}
+	return name
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/domain"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+)
+
+const (
+	maxPlaces = 100
+	// placeBackfillBatchSize is how many photos BackfillPlaces loads at once.
+	placeBackfillBatchSize = 100
+)
+
+type PlaceResponse struct {
+	Name        string `json:"name"`
+	CountryCode string `json:"country_code"`
+	Country     string `json:"country"`
+	// DisplayName combines the place and country, e.g. "Lisbon, Portugal".
+	DisplayName string `json:"display_name"`
+}
+
+type PhotoPlaceResponse struct {
+	Place        PlaceResponse `json:"place"`
+	PhotoCount   int           `json:"photo_count"`
+	CoverPhotoID string        `json:"cover_photo_id"`
+}
+
+// attachPlace sets the place of a photo to the one nearest to its location.
+// Photos without a location, or far from any known place, have no place.
+func (s *PhotoService) attachPlace(photo *domain.Photo) {
+	photo.PlaceName = ""
+	photo.CountryCode = ""
+	photo.CountryName = ""
+	if s.geocoder == nil || photo.Latitude == nil || photo.Longitude == nil {
+		return
+	}
+
+	place, ok := s.geocoder.Reverse(*photo.Latitude, *photo.Longitude)
+	if !ok {
+		return
+	}
+	photo.PlaceName = place.Name
+	photo.CountryCode = place.CountryCode
+	photo.CountryName = place.CountryName
+}
+
+// BackfillPlaces attaches places to geotagged photos saved without one, such
+// as photos uploaded before reverse geocoding was available. Photos that
+// still have no place afterwards are far from every known place.
+func (s *PhotoService) BackfillPlaces(ctx context.Context) error {
+	updated := 0
+	afterID := uuid.Nil
+	for {
+		photos, err := s.photoRepo.GetMissingPlace(ctx, afterID, placeBackfillBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, photo := range photos {
+			s.attachPlace(photo)
+			if photo.PlaceName == "" {
+				continue
+			}
+			photo.UpdatedAt = time.Now()
+			err := s.photoRepo.UpdatePlace(ctx, photo)
+			if err != nil {
+				return err
+			}
+			updated++
+		}
+
+		if len(photos) < placeBackfillBatchSize {
+			break
+		}
+		afterID = photos[len(photos)-1].ID
+	}
+
+	s.logger.Info().Int("photos", updated).Msg("photo places backfilled successfully")
+	return nil
+}
+
+// SearchPhotosByPlace returns the user's photos whose place or country name
+// contains query, ignoring case.
+func (s *PhotoService) SearchPhotosByPlace(ctx context.Context, userID uuid.UUID, query string, page, pageSize int) (*PhotosResponse, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, apperrors.New(apperrors.BadRequest, "place cannot be empty")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	offset := (page - 1) * pageSize
+
+	photos, total, err := s.photoRepo.GetByPlace(ctx, userID, query, pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	photoResponses := make([]PhotoResponse, len(photos))
+	for i, photo := range photos {
+		photoResponses[i] = *newPhotoResponse(photo, userID)
+	}
+
+	return &PhotosResponse{
+		Photos:     photoResponses,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: (total + pageSize - 1) / pageSize,
+	}, nil
+}
+
+// GetPlaces lists the places of the user's photos, most photographed first.
+// A non-empty query keeps the places whose name or country contains it.
+func (s *PhotoService) GetPlaces(ctx context.Context, userID uuid.UUID, query string) ([]PhotoPlaceResponse, error) {
+	places, err := s.photoRepo.GetPlaces(ctx, userID, strings.TrimSpace(query), maxPlaces)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]PhotoPlaceResponse, len(places))
+	for i, place := range places {
+		responses[i] = PhotoPlaceResponse{
+			Place:      *newPlaceResponse(place.Name, place.CountryCode, place.CountryName),
+			PhotoCount: place.PhotoCount,
+		}
+		if len(place.PhotoIDs) > 0 {
+			responses[i].CoverPhotoID = place.PhotoIDs[0].String()
+		}
+	}
+
+	return responses, nil
+}
+
+func newPlaceResponse(name, countryCode, countryName string) *PlaceResponse {
+	if name == "" {
+		return nil
+	}
+
+	return &PlaceResponse{
+		Name:        name,
+		CountryCode: countryCode,
+		Country:     countryName,
+		DisplayName: placeDisplayName(name, countryName),
+	}
+}
+
+func placeDisplayName(name, countryName string) string {
+	if countryName == "" {
+		return name
+	}
+	return name + ", " + countryName
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+)
+
+const (
+	GranularityYear  = "year"
+	GranularityMonth = "month"
+	GranularityDay   = "day"
+)
+
+// bucketKeyLayouts are the layouts of bucket keys per granularity. Keys are
+// returned by the timeline and accepted by the photo list to jump to a
+// bucket.
+var bucketKeyLayouts = map[string]string{
+	GranularityYear:  "2006",
+	GranularityMonth: "2006-01",
+	GranularityDay:   "2006-01-02",
+}
+
+type TimelineBucket struct {
+	// Key identifies the bucket, e.g. "2024", "2024-03" or "2024-03-15".
+	Key   string    `json:"key"`
+	Start time.Time `json:"start"`
+	Count int       `json:"count"`
+}
+
+type TimelineResponse struct {
+	Granularity string           `json:"granularity"`
+	TimeZone    string           `json:"time_zone"`
+	Total       int              `json:"total"`
+	Buckets     []TimelineBucket `json:"buckets"`
+}
+
+// GetTimeline counts the user's photos per year, month or day, newest first.
+// Photos are dated by when they were taken if known and by their upload time
+// otherwise. Dates are split into buckets in timeZone, or in the time zone of
+// the user's profile when timeZone is empty.
+func (s *PhotoService) GetTimeline(ctx context.Context, userID uuid.UUID, granularity, timeZone string) (*TimelineResponse, error) {
+	if granularity == "" {
+		granularity = GranularityMonth
+	}
+	layout, ok := bucketKeyLayouts[granularity]
+	if !ok {
+		return nil, apperrors.NewWithFormat(apperrors.BadRequest, "granularity must be one of [%s %s %s]", GranularityYear, GranularityMonth, GranularityDay)
+	}
+
+	loc, err := s.userLocation(ctx, userID, timeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets, err := s.photoRepo.GetDateBuckets(ctx, userID, granularity, loc.String())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &TimelineResponse{
+		Granularity: granularity,
+		TimeZone:    loc.String(),
+		Buckets:     make([]TimelineBucket, len(buckets)),
+	}
+	for i, bucket := range buckets {
+		start := bucket.Start.In(loc)
+		resp.Buckets[i] = TimelineBucket{
+			Key:   start.Format(layout),
+			Start: start,
+			Count: bucket.Count,
+		}
+		resp.Total += bucket.Count
+	}
+
+	return resp, nil
+}
+
+// GetPhotosFromBucket returns the page of the user's photo list holding the
+// first photo of a timeline bucket, that is the newest photo dated in it.
+// bucket is a key as returned by GetTimeline, read in the same time zone.
+func (s *PhotoService) GetPhotosFromBucket(ctx context.Context, userID uuid.UUID, bucket, timeZone string, pageSize int) (*PhotosResponse, error) {
+	loc, err := s.userLocation(ctx, userID, timeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := bucketEnd(bucket, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	// The list is ordered newest first, so the bucket starts right after
+	// the photos dated after it.
+	newer, err := s.photoRepo.CountSince(ctx, userID, end)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.GetPhotosByID(ctx, userID, newer/pageSize+1, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	index := newer % pageSize
+	resp.BucketIndex = &index
+	return resp, nil
+}
+
+// userLocation loads timeZone, or the time zone of the user's profile when
+// it is empty.
+func (s *PhotoService) userLocation(ctx context.Context, userID uuid.UUID, timeZone string) (*time.Location, error) {
+	if timeZone == "" {
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		timeZone = user.TimeZone
+	}
+
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.BadRequest, "unknown time zone %q", timeZone)
+	}
+	return loc, nil
+}
+
+// bucketEnd returns the end of the bucket identified by key, which is also
+// the start of the next one.
+func bucketEnd(key string, loc *time.Location) (time.Time, error) {
+	for granularity, layout := range bucketKeyLayouts {
+		if len(key) != len(layout) {
+			continue
+		}
+
+		start, err := time.ParseInLocation(layout, key, loc)
+		if err != nil {
+			break
+		}
+
+		switch granularity {
+		case GranularityYear:
+			return start.AddDate(1, 0, 0), nil
+		case GranularityMonth:
+			return start.AddDate(0, 1, 0), nil
+		default:
+			return start.AddDate(0, 0, 1), nil
+		}
+	}
+
+	return time.Time{}, apperrors.NewWithFormat(apperrors.BadRequest, "invalid bucket %q, expected YYYY, YYYY-MM or YYYY-MM-DD", key)
+}
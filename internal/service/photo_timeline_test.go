@@ -0,0 +1,56 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketEnd(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("failed to load time zone: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		key         string
+		loc         *time.Location
+		want        time.Time
+		wantMessage string
+	}{
+		{name: "year", key: "2024", loc: time.UTC, want: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "month", key: "2024-02", loc: time.UTC, want: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "december", key: "2024-12", loc: time.UTC, want: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "day", key: "2024-02-28", loc: time.UTC, want: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{name: "leap day", key: "2024-02-29", loc: time.UTC, want: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "last day of the year", key: "2024-12-31", loc: time.UTC, want: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "in the user's time zone", key: "2024-07", loc: berlin, want: time.Date(2024, 8, 1, 0, 0, 0, 0, berlin)},
+		// The day clocks go forward has 23 hours; the bucket still ends at
+		// the next local midnight.
+		{name: "day of a DST change", key: "2025-03-30", loc: berlin, want: time.Date(2025, 3, 31, 0, 0, 0, 0, berlin)},
+		{name: "empty", key: "", loc: time.UTC, wantMessage: "invalid bucket"},
+		{name: "short year", key: "24", loc: time.UTC, wantMessage: "invalid bucket"},
+		{name: "invalid month", key: "2024-13", loc: time.UTC, wantMessage: "invalid bucket"},
+		{name: "invalid day", key: "2023-02-29", loc: time.UTC, wantMessage: "invalid bucket"},
+		{name: "unpadded month", key: "2024-2-01", loc: time.UTC, wantMessage: "invalid bucket"},
+		{name: "other separator", key: "2024/02", loc: time.UTC, wantMessage: "invalid bucket"},
+		{name: "not a date", key: "abcd", loc: time.UTC, wantMessage: "invalid bucket"},
+		{name: "with a time", key: "2024-02-28T10:00", loc: time.UTC, wantMessage: "invalid bucket"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := bucketEnd(tt.key, tt.loc)
+			if tt.wantMessage != "" {
+				assertBadRequest(t, err, tt.wantMessage)
+				return
+			}
+			if err != nil {
+				t.Fatalf("bucketEnd(%q) failed: %v", tt.key, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("bucketEnd(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/mmd-moradi/goup/internal/domain"
+	repositories "github.com/mmd-moradi/goup/internal/repository"
+	"github.com/mmd-moradi/goup/internal/storage"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/mmd-moradi/goup/pkg/validator"
+	"github.com/rs/zerolog"
+)
+
+type PhotoVersionService struct {
+	photoRepo          repositories.PhotoRepository
+	storage            storage.StorageService
+	authz              *Authorizer
+	maxVersionsPerUser int
+	logger             zerolog.Logger
+}
+
+type PhotoContentInput struct {
+	FileName    string `json:"file_name" validate:"required"`
+	FileSize    int64  `json:"file_size" validate:"required"`
+	ContentType string `json:"content_type" validate:"required"`
+}
+
+type PhotoVersionResponse struct {
+	VersionNumber int       `json:"version_number"`
+	FileName      string    `json:"file_name"`
+	FileSize      int64     `json:"file_size"`
+	ContentType   string    `json:"content_type"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type PhotoContent struct {
+	Data        []byte
+	ContentType string
+	FileName    string
+}
+
+func NewPhotoVersionService(
+	photoRepo repositories.PhotoRepository,
+	storage storage.StorageService,
+	authz *Authorizer,
+	maxVersionsPerUser int,
+	logger zerolog.Logger,
+) *PhotoVersionService {
+	return &PhotoVersionService{
+		photoRepo:          photoRepo,
+		storage:            storage,
+		authz:              authz,
+		maxVersionsPerUser: maxVersionsPerUser,
+		logger:             logger,
+	}
+}
+
+// ReplaceContent uploads a new binary for a photo. The previous binary is
+// kept as a new version of the photo. ifMatch is handled as in
+// PhotoService.UpdatePhoto, and a photo changed while the file was uploaded
+// yields a PreconditionFailed error.
+func (s *PhotoVersionService) ReplaceContent(ctx context.Context, photoID uuid.UUID, input PhotoContentInput, ifMatch []string, userID uuid.UUID, data []byte) (*PhotoResponse, error) {
+	if err := validator.Validate(input); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.BadRequest)
+	}
+	if input.FileSize > MaxPhotoSize {
+		return nil, apperrors.NewWithFormat(apperrors.BadRequest, "file exceeds the maximum size of %d bytes", MaxPhotoSize)
+	}
+
+	photo, err := s.getAuthorizedPhoto(ctx, photoID, userID, ActionEdit)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkIfMatch(photo, ifMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *photo
+	updated.FileName = input.FileName
+	updated.FileSize = input.FileSize
+	updated.ContentType = input.ContentType
+	updated.UpdatedAt = time.Now()
+
+	// Files are stored under the owner, whoever uploads the new content.
+	err = s.storage.UploadPhoto(ctx, data, photo.UserID, &updated)
+	if err != nil {
+		return nil, err
+	}
+
+	// The photo is updated first so that a concurrent change locks it out
+	// before any version is written.
+	err = s.photoRepo.WithTx(ctx, pgx.TxOptions{}, func(txRepo repositories.PhotoRepository) error {
+		err := txRepo.UpdateStorageInfo(ctx, &updated)
+		if err != nil {
+			return err
+		}
+		return txRepo.CreateVersion(ctx, domain.NewPhotoVersion(photo))
+	})
+	if err != nil {
+		cleanUpErr := s.storage.DeletePhoto(ctx, updated.StoragePath)
+		if cleanUpErr != nil {
+			s.logger.Error().Err(cleanUpErr).Msg("failed to clean up photo content after database error")
+		}
+		return nil, err
+	}
+
+	s.pruneVersions(ctx, photo.UserID)
+
+	s.logger.Info().
+		Str("userID", userID.String()).
+		Str("photoID", photo.ID.String()).
+		Msg("photo content replaced successfully")
+
+	return newPhotoResponse(&updated, userID), nil
+}
+
+// ListVersions lists the previous versions of a photo. Replaced content may
+// have been removed on purpose, so the history is only open to users who can
+// edit the photo, not to everyone who can view it.
+func (s *PhotoVersionService) ListVersions(ctx context.Context, photoID uuid.UUID, userID uuid.UUID) ([]PhotoVersionResponse, error) {
+	photo, err := s.getAuthorizedPhoto(ctx, photoID, userID, ActionEdit)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.photoRepo.GetVersions(ctx, photo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]PhotoVersionResponse, len(versions))
+	for i, version := range versions {
+		responses[i] = *newPhotoVersionResponse(version)
+	}
+
+	return responses, nil
+}
+
+// GetVersionContent returns the file of a previous version of a photo to a
+// user who can edit the photo, as in ListVersions.
+func (s *PhotoVersionService) GetVersionContent(ctx context.Context, photoID uuid.UUID, versionNumber int, userID uuid.UUID) (*PhotoContent, error) {
+	photo, err := s.getAuthorizedPhoto(ctx, photoID, userID, ActionEdit)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := s.photoRepo.GetVersion(ctx, photo.ID, versionNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	data, contentType, err := s.storage.GetPhoto(ctx, version.StoragePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if contentType == "" {
+		contentType = version.ContentType
+	}
+
+	return &PhotoContent{
+		Data:        data,
+		ContentType: contentType,
+		FileName:    version.FileName,
+	}, nil
+}
+
+// RevertToVersion makes a previous version the current content of a photo.
+// The content it replaces is kept as a new version, and the restored version
+// is removed from the history since it is the current content again.
+// ifMatch and concurrent changes are handled as in ReplaceContent.
+func (s *PhotoVersionService) RevertToVersion(ctx context.Context, photoID uuid.UUID, versionNumber int, ifMatch []string, userID uuid.UUID) (*PhotoResponse, error) {
+	photo, err := s.getAuthorizedPhoto(ctx, photoID, userID, ActionEdit)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkIfMatch(photo, ifMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *photo
+	err = s.photoRepo.WithTx(ctx, pgx.TxOptions{}, func(txRepo repositories.PhotoRepository) error {
+		version, err := txRepo.GetVersion(ctx, photo.ID, versionNumber)
+		if err != nil {
+			return err
+		}
+
+		updated.FileName = version.FileName
+		updated.FileSize = version.FileSize
+		updated.ContentType = version.ContentType
+		updated.StoragePath = version.StoragePath
+		updated.PublicURL = version.PublicURL
+		updated.UpdatedAt = time.Now()
+		err = txRepo.UpdateStorageInfo(ctx, &updated)
+		if err != nil {
+			return err
+		}
+
+		err = txRepo.CreateVersion(ctx, domain.NewPhotoVersion(photo))
+		if err != nil {
+			return err
+		}
+
+		return txRepo.DeleteVersion(ctx, version.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.pruneVersions(ctx, photo.UserID)
+
+	s.logger.Info().
+		Str("userID", userID.String()).
+		Str("photoID", photo.ID.String()).
+		Int("version", versionNumber).
+		Msg("photo reverted successfully")
+
+	return newPhotoResponse(&updated, userID), nil
+}
+
+// pruneVersions deletes the oldest versions of the user's photos beyond the
+// configured limit. Failures are logged since the triggering change has
+// already been saved.
+func (s *PhotoVersionService) pruneVersions(ctx context.Context, userID uuid.UUID) {
+	versions, err := s.photoRepo.GetVersionsBeyondLimit(ctx, userID, s.maxVersionsPerUser)
+	if err != nil {
+		s.logger.Error().Err(err).Str("userID", userID.String()).Msg("failed to list photo versions to prune")
+		return
+	}
+
+	for _, version := range versions {
+		err := s.photoRepo.DeleteVersion(ctx, version.ID)
+		if err != nil {
+			s.logger.Error().Err(err).Str("versionID", version.ID.String()).Msg("failed to prune photo version")
+			continue
+		}
+		err = s.storage.DeletePhoto(ctx, version.StoragePath)
+		if err != nil {
+			s.logger.Error().Err(err).Str("storagePath", version.StoragePath).Msg("failed to delete pruned photo version file")
+		}
+	}
+}
+
+func (s *PhotoVersionService) getAuthorizedPhoto(ctx context.Context, id uuid.UUID, userID uuid.UUID, action Action) (*domain.Photo, error) {
+	photo, err := s.photoRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.authz.AuthorizePhoto(ctx, photo, userID, action)
+	if err != nil {
+		return nil, err
+	}
+
+	return photo, nil
+}
+
+func newPhotoVersionResponse(version *domain.PhotoVersion) *PhotoVersionResponse {
+	return &PhotoVersionResponse{
+		VersionNumber: version.VersionNumber,
+		FileName:      version.FileName,
+		FileSize:      version.FileSize,
+		ContentType:   version.ContentType,
+		CreatedAt:     version.CreatedAt,
+	}
+}
@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mmd-moradi/goup/internal/auth"
+	"github.com/mmd-moradi/goup/internal/domain"
+	repositories "github.com/mmd-moradi/goup/internal/repository"
+	"github.com/mmd-moradi/goup/internal/storage"
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/mmd-moradi/goup/pkg/validator"
+	"github.com/rs/zerolog"
+)
+
+const shareTokenBytes = 32
+
+// Wrong passwords a client may try on a share link before it is locked out
+// of the link for SharePasswordLockout.
+const (
+	SharePasswordMaxFailures = 10
+	SharePasswordLockout     = 15 * time.Minute
+)
+
+type ShareService struct {
+	shareRepo       repositories.ShareLinkRepository
+	photoRepo       repositories.PhotoRepository
+	storage         storage.StorageService
+	authz           *Authorizer
+	passwordLimiter *auth.AttemptLimiter
+	logger          zerolog.Logger
+}
+
+type ShareLinkCreateInput struct {
+	ExpiresAt     *time.Time `json:"expires_at"`
+	Password      string     `json:"password" validate:"omitempty,min=8,max=72"`
+	MaxViews      *int       `json:"max_views" validate:"omitempty,gt=0"`
+	AllowDownload bool       `json:"allow_download"`
+}
+
+type ShareLinkResponse struct {
+	ID                string     `json:"id"`
+	PhotoID           string     `json:"photo_id"`
+	Token             string     `json:"token"`
+	URL               string     `json:"url"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+	MaxViews          *int       `json:"max_views,omitempty"`
+	ViewCount         int        `json:"view_count"`
+	AllowDownload     bool       `json:"allow_download"`
+	PasswordProtected bool       `json:"password_protected"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+type SharedPhotoResponse struct {
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	FileName       string     `json:"file_name"`
+	FileSize       int64      `json:"file_size"`
+	ContentType    string     `json:"content_type"`
+	AllowDownload  bool       `json:"allow_download"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	ViewsRemaining *int       `json:"views_remaining,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type SharedPhotoContent struct {
+	Data        []byte
+	ContentType string
+	FileName    string
+}
+
+func NewShareService(
+	shareRepo repositories.ShareLinkRepository,
+	photoRepo repositories.PhotoRepository,
+	storage storage.StorageService,
+	authz *Authorizer,
+	passwordLimiter *auth.AttemptLimiter,
+	logger zerolog.Logger,
+) *ShareService {
+	return &ShareService{
+		shareRepo:       shareRepo,
+		photoRepo:       photoRepo,
+		storage:         storage,
+		authz:           authz,
+		passwordLimiter: passwordLimiter,
+		logger:          logger,
+	}
+}
+
+func (s *ShareService) CreateShareLink(ctx context.Context, photoID uuid.UUID, input ShareLinkCreateInput, userID uuid.UUID) (*ShareLinkResponse, error) {
+	if err := validator.Validate(input); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.BadRequest)
+	}
+
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, apperrors.New(apperrors.BadRequest, "expires_at must be in the future")
+	}
+
+	if _, err := s.getAuthorizedPhoto(ctx, photoID, userID); err != nil {
+		return nil, err
+	}
+	if err := s.authz.AuthorizeCapability(ctx, userID, CapabilityShare); err != nil {
+		return nil, err
+	}
+
+	token, err := generateShareToken()
+	if err != nil {
+		return nil, apperrors.New(apperrors.InternalServer, "failed to generate share token")
+	}
+
+	link := domain.NewShareLink(photoID, userID, token, input.ExpiresAt, input.MaxViews, input.AllowDownload)
+
+	if input.Password != "" {
+		link.PasswordHash, err = auth.HashPassword(input.Password)
+		if err != nil {
+			return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to hash password: %v", err)
+		}
+	}
+
+	if err := s.shareRepo.Create(ctx, link); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("userID", userID.String()).
+		Str("photoID", photoID.String()).
+		Str("shareID", link.ID.String()).
+		Msg("share link created successfully")
+
+	return newShareLinkResponse(link), nil
+}
+
+func (s *ShareService) ListShareLinks(ctx context.Context, photoID uuid.UUID, userID uuid.UUID) ([]ShareLinkResponse, error) {
+	if _, err := s.getAuthorizedPhoto(ctx, photoID, userID); err != nil {
+		return nil, err
+	}
+
+	links, err := s.shareRepo.ListByPhotoID(ctx, photoID)
+	if err != nil {
+		return nil, err
+	}
+
+	linkResponses := make([]ShareLinkResponse, len(links))
+	for i, link := range links {
+		linkResponses[i] = *newShareLinkResponse(link)
+	}
+
+	return linkResponses, nil
+}
+
+func (s *ShareService) RevokeShareLink(ctx context.Context, photoID uuid.UUID, shareID uuid.UUID, userID uuid.UUID) error {
+	link, err := s.shareRepo.GetByID(ctx, shareID)
+	if err != nil {
+		return err
+	}
+
+	if link.PhotoID != photoID {
+		return apperrors.NewWithFormat(apperrors.NotFound, "share link with id %s not found", shareID)
+	}
+
+	if link.UserID != userID {
+		return apperrors.New(apperrors.Forbidden, "You don't have access to this share link")
+	}
+
+	err = s.shareRepo.Delete(ctx, shareID)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info().
+		Str("userID", userID.String()).
+		Str("shareID", shareID.String()).
+		Msg("share link revoked successfully")
+
+	return nil
+}
+
+// GetSharedPhoto resolves a share token to the photo's public metadata. It does
+// not count as a view. clientIP identifies the client for limiting password
+// attempts.
+func (s *ShareService) GetSharedPhoto(ctx context.Context, token, password, clientIP string) (*SharedPhotoResponse, error) {
+	link, err := s.resolveShareLink(ctx, token, password, clientIP)
+	if err != nil {
+		return nil, err
+	}
+
+	photo, err := s.photoRepo.GetByID(ctx, link.PhotoID)
+	if err != nil {
+		return nil, err
+	}
+
+	var viewsRemaining *int
+	if link.MaxViews != nil {
+		remaining := *link.MaxViews - link.ViewCount
+		viewsRemaining = &remaining
+	}
+
+	return &SharedPhotoResponse{
+		Title:          photo.Title,
+		Description:    photo.Description,
+		FileName:       photo.FileName,
+		FileSize:       photo.FileSize,
+		ContentType:    photo.ContentType,
+		AllowDownload:  link.AllowDownload,
+		ExpiresAt:      link.ExpiresAt,
+		ViewsRemaining: viewsRemaining,
+		CreatedAt:      photo.CreatedAt,
+	}, nil
+}
+
+// GetSharedPhotoContent resolves a share token to the photo's binary and
+// counts one view against the link. A download, which the link must allow,
+// is refused before it counts.
+func (s *ShareService) GetSharedPhotoContent(ctx context.Context, token, password, clientIP string, download bool) (*SharedPhotoContent, error) {
+	link, err := s.resolveShareLink(ctx, token, password, clientIP)
+	if err != nil {
+		return nil, err
+	}
+
+	if download && !link.AllowDownload {
+		return nil, apperrors.New(apperrors.Forbidden, "downloads are not allowed for this share link")
+	}
+
+	photo, err := s.photoRepo.GetByID(ctx, link.PhotoID)
+	if err != nil {
+		return nil, err
+	}
+
+	link, err = s.shareRepo.IncrementViewCount(ctx, link.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, contentType, err := s.storage.GetPhoto(ctx, photo.StoragePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if contentType == "" {
+		contentType = photo.ContentType
+	}
+
+	return &SharedPhotoContent{
+		Data:        data,
+		ContentType: contentType,
+		FileName:    photo.FileName,
+	}, nil
+}
+
+func (s *ShareService) resolveShareLink(ctx context.Context, token, password, clientIP string) (*domain.ShareLink, error) {
+	link, err := s.shareRepo.GetByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if link.IsExpired() || link.IsExhausted() {
+		return nil, apperrors.New(apperrors.Forbidden, "share link has expired or reached its view limit")
+	}
+
+	if link.PasswordHash != "" {
+		err = s.checkSharePassword(ctx, link, password, clientIP)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return link, nil
+}
+
+// checkSharePassword checks the password of a protected link. A client
+// that keeps guessing wrong is locked out of the link for a while, which
+// also bounds the hashing it can make the server do.
+func (s *ShareService) checkSharePassword(ctx context.Context, link *domain.ShareLink, password, clientIP string) error {
+	attemptKey := link.ID.String() + ":" + clientIP
+	err := s.passwordLimiter.Check(ctx, attemptKey)
+	if err != nil {
+		return err
+	}
+
+	if !auth.CheckPasswordHash(password, link.PasswordHash) {
+		// A request without a password is how clients learn that one is
+		// needed, so it isn't held against them.
+		if password != "" {
+			err = s.passwordLimiter.Fail(ctx, attemptKey)
+			if err != nil {
+				return err
+			}
+		}
+		return apperrors.New(apperrors.Unauthorized, "invalid or missing share link password")
+	}
+
+	return s.passwordLimiter.Reset(ctx, attemptKey)
+}
+
+func (s *ShareService) getAuthorizedPhoto(ctx context.Context, photoID uuid.UUID, userID uuid.UUID) (*domain.Photo, error) {
+	photo, err := s.photoRepo.GetByID(ctx, photoID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authz.AuthorizePhoto(ctx, photo, userID, ActionShare); err != nil {
+		return nil, err
+	}
+
+	return photo, nil
+}
+
+func newShareLinkResponse(link *domain.ShareLink) *ShareLinkResponse {
+	return &ShareLinkResponse{
+		ID:                link.ID.String(),
+		PhotoID:           link.PhotoID.String(),
+		Token:             link.Token,
+		URL:               "/s/" + link.Token,
+		ExpiresAt:         link.ExpiresAt,
+		MaxViews:          link.MaxViews,
+		ViewCount:         link.ViewCount,
+		AllowDownload:     link.AllowDownload,
+		PasswordProtected: link.PasswordHash != "",
+		CreatedAt:         link.CreatedAt,
+	}
+}
+
+func generateShareToken() (string, error) {
+	randBytes := make([]byte, shareTokenBytes)
+	_, err := rand.Read(randBytes)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(randBytes), nil
+}
@@ -1,28 +1,60 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/mmd-moradi/goup/internal/auth"
 	"github.com/mmd-moradi/goup/internal/domain"
+	"github.com/mmd-moradi/goup/internal/mail"
 	repositories "github.com/mmd-moradi/goup/internal/repository"
 	"github.com/mmd-moradi/goup/pkg/apperrors"
+	"github.com/mmd-moradi/goup/pkg/mergepatch"
 	"github.com/mmd-moradi/goup/pkg/validator"
 	"github.com/rs/zerolog"
 )
 
+// accountMailTimeout bounds sending an email about the account.
+const accountMailTimeout = 30 * time.Second
+
 type UserService struct {
-	repo     repositories.UserRepository
-	tokenSvc *auth.TokenService
-	logger   zerolog.Logger
+	repo      repositories.UserRepository
+	photoRepo repositories.PhotoRepository
+	auditRepo repositories.AuditRepository
+	tokenSvc  *auth.TokenService
+	mailer    mail.Mailer
+	links     AccountLinks
+	// deletionGracePeriod is how long a deleted account can still be
+	// restored by logging in.
+	deletionGracePeriod time.Duration
+	mfaLimiter          *auth.AttemptLimiter
+	logger              zerolog.Logger
+}
+
+// AccountLinks are the pages of the web app that emails about the account
+// link to.
+type AccountLinks struct {
+	// PasswordReset receives the reset token in its token query parameter.
+	PasswordReset string
+	// EmailVerification receives the verification token in its token
+	// query parameter.
+	EmailVerification string
 }
 
 type UserRegistrationInput struct {
 	Username string `json:"username" validate:"required,min=3,max=50"`
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=8"`
+	// TimeZone is an IANA time zone name such as "Europe/Lisbon", used to
+	// group photos by date. It defaults to UTC.
+	TimeZone string `json:"time_zone,omitempty" validate:"omitempty,timezone"`
 }
 
 type UserLoginInput struct {
@@ -31,26 +63,107 @@ type UserLoginInput struct {
 }
 
 type UserResponse struct {
-	ID        string    `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
+	ID              string     `json:"id"`
+	Username        string     `json:"username"`
+	Email           string     `json:"email"`
+	EmailVerified   bool       `json:"email_verified"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	MFAEnabled      bool       `json:"mfa_enabled"`
+	TimeZone        string     `json:"time_zone"`
+	// StorageUsed is the number of bytes the user's photos and their
+	// previous versions take up. It is only part of the profile.
+	StorageUsed *int64    `json:"storage_used,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type PasswordForgotInput struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type PasswordResetInput struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// MessageResponse is a human readable outcome of a request.
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// ProfilePatchDocument holds the members of a profile that can be changed
+// with a merge patch.
+type ProfilePatchDocument struct {
+	Username string `json:"username" validate:"required,min=3,max=50"`
+	Email    string `json:"email" validate:"required,email"`
+	TimeZone string `json:"time_zone" validate:"required,timezone"`
+}
+
+type PasswordChangeInput struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8"`
 }
 
+type EmailVerifyInput struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type UserRefreshInput struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// AuthResponse holds the signed-in user and a new token pair. Token is sent
+// as the bearer token until ExpiresAt; RefreshToken is exchanged for a new
+// pair at /auth/refresh and can be used only once.
 type AuthResponse struct {
-	User  UserResponse `json:"user"`
-	Token string       `json:"token"`
+	User             UserResponse `json:"user"`
+	Token            string       `json:"token"`
+	ExpiresAt        time.Time    `json:"expires_at"`
+	RefreshToken     string       `json:"refresh_token"`
+	RefreshExpiresAt time.Time    `json:"refresh_expires_at"`
+}
+
+// SessionResponse is a device or client the user is logged in on.
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+type TokenResponse struct {
+	Token            string    `json:"token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
 }
 
-func NewUserService(repo repositories.UserRepository, tokenSvc *auth.TokenService, logger zerolog.Logger) *UserService {
+func NewUserService(
+	repo repositories.UserRepository,
+	photoRepo repositories.PhotoRepository,
+	auditRepo repositories.AuditRepository,
+	tokenSvc *auth.TokenService,
+	mailer mail.Mailer,
+	links AccountLinks,
+	deletionGracePeriod time.Duration,
+	mfaLimiter *auth.AttemptLimiter,
+	logger zerolog.Logger,
+) *UserService {
 	return &UserService{
-		repo:     repo,
-		tokenSvc: tokenSvc,
-		logger:   logger,
+		repo:                repo,
+		photoRepo:           photoRepo,
+		auditRepo:           auditRepo,
+		tokenSvc:            tokenSvc,
+		mailer:              mailer,
+		links:               links,
+		deletionGracePeriod: deletionGracePeriod,
+		mfaLimiter:          mfaLimiter,
+		logger:              logger,
 	}
 }
 
-func (s *UserService) Register(ctx context.Context, input UserRegistrationInput) (*AuthResponse, error) {
+func (s *UserService) Register(ctx context.Context, input UserRegistrationInput, client auth.ClientInfo) (*AuthResponse, error) {
 	if err := validator.Validate(input); err != nil {
 		return nil, apperrors.Wrap(err, apperrors.BadRequest)
 	}
@@ -72,33 +185,38 @@ func (s *UserService) Register(ctx context.Context, input UserRegistrationInput)
 
 	user := domain.NewUser(input.Username, input.Email)
 	user.PasswordHash = hashedPassword
+	if input.TimeZone != "" {
+		user.TimeZone = input.TimeZone
+	}
 
 	err = s.repo.CreateUser(ctx, user)
 	if err != nil {
 		return nil, err
 	}
 
-	tokenDetails, err := s.tokenSvc.GenerateToken(user.ID)
+	tokens, err := s.tokenSvc.GenerateTokenPair(user.ID, client)
 	if err != nil {
 		return nil, err
 	}
 
+	go s.sendEmailVerification(user)
+
 	s.logger.Info().
 		Str("userID", user.ID.String()).
 		Msg("user registered successfully")
 
-	return &AuthResponse{
-		User: UserResponse{
-			ID:        user.ID.String(),
-			Username:  user.Username,
-			Email:     user.Email,
-			CreatedAt: user.CreatedAt,
-		},
-		Token: tokenDetails.Token,
-	}, nil
+	return newAuthResponse(user, tokens), nil
 }
 
-func (s *UserService) Login(ctx context.Context, input UserLoginInput) (*AuthResponse, error) {
+// LoginResult is the outcome of Login. Either Auth is set, or the user has
+// two-factor authentication enabled and Challenge has to be completed at
+// /auth/mfa/verify.
+type LoginResult struct {
+	Auth      *AuthResponse
+	Challenge *MFAChallengeResponse
+}
+
+func (s *UserService) Login(ctx context.Context, input UserLoginInput, client auth.ClientInfo) (*LoginResult, error) {
 	if err := validator.Validate(input); err != nil {
 		return nil, apperrors.Wrap(err, apperrors.BadRequest)
 	}
@@ -112,7 +230,40 @@ func (s *UserService) Login(ctx context.Context, input UserLoginInput) (*AuthRes
 		return nil, apperrors.New(apperrors.Unauthorized, "invalid email or password")
 	}
 
-	tokenDetails, err := s.tokenSvc.GenerateToken(user.ID)
+	if user.MFAEnabled() {
+		token, err := s.tokenSvc.CreateMFAChallenge(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{Challenge: &MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    token,
+			ExpiresAt:   time.Now().Add(s.tokenSvc.MFAChallengeTTL()),
+		}}, nil
+	}
+
+	authResponse, err := s.completeLogin(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{Auth: authResponse}, nil
+}
+
+// completeLogin issues a token pair to a user who passed every check of a
+// login. Logging in keeps an account scheduled for deletion.
+func (s *UserService) completeLogin(ctx context.Context, user *domain.User, client auth.ClientInfo) (*AuthResponse, error) {
+	if user.DeletionScheduledAt != nil {
+		err := s.cancelDeletion(ctx, user)
+		if err != nil {
+			var appErr apperrors.Error
+			if errors.As(err, &appErr) && appErr.Type == apperrors.Conflict {
+				return nil, apperrors.New(apperrors.Unauthorized, "invalid email or password")
+			}
+			return nil, err
+		}
+	}
+
+	tokens, err := s.tokenSvc.GenerateTokenPair(user.ID, client)
 	if err != nil {
 		return nil, err
 	}
@@ -121,15 +272,7 @@ func (s *UserService) Login(ctx context.Context, input UserLoginInput) (*AuthRes
 		Str("userID", user.ID.String()).
 		Msg("user logged in successfully")
 
-	return &AuthResponse{
-		User: UserResponse{
-			ID:        user.ID.String(),
-			Username:  user.Username,
-			Email:     user.Email,
-			CreatedAt: user.CreatedAt,
-		},
-		Token: tokenDetails.Token,
-	}, nil
+	return newAuthResponse(user, tokens), nil
 }
 
 func (s *UserService) GetUserByID(ctx context.Context, userID uuid.UUID) (*UserResponse, error) {
@@ -138,11 +281,37 @@ func (s *UserService) GetUserByID(ctx context.Context, userID uuid.UUID) (*UserR
 		return nil, err
 	}
 
-	return &UserResponse{
-		ID:        user.ID.String(),
-		Username:  user.Username,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
+	storageUsed, err := s.photoRepo.GetStorageUsage(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := newUserResponse(user)
+	resp.StorageUsed = &storageUsed
+	return resp, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair. A refresh token
+// that was already used revokes every token issued since the same login.
+func (s *UserService) Refresh(ctx context.Context, input UserRefreshInput, client auth.ClientInfo) (*TokenResponse, error) {
+	if err := validator.Validate(input); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.BadRequest)
+	}
+
+	tokens, err := s.tokenSvc.RefreshToken(input.RefreshToken, client)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("userID", tokens.UserID.String()).
+		Msg("token refreshed successfully")
+
+	return &TokenResponse{
+		Token:            tokens.AccessToken,
+		ExpiresAt:        tokens.ExpiresAt,
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
 	}, nil
 }
 
@@ -154,3 +323,397 @@ func (s *UserService) Logout(ctx context.Context, token string) error {
 	s.logger.Info().Msg("user logged out successfully")
 	return nil
 }
+
+// PatchProfile updates the username, email address and time zone of the user
+// with a JSON Merge Patch. A new email address has to be verified again.
+func (s *UserService) PatchProfile(ctx context.Context, userID uuid.UUID, patch []byte) (*UserResponse, error) {
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := json.Marshal(ProfilePatchDocument{
+		Username: user.Username,
+		Email:    user.Email,
+		TimeZone: user.TimeZone,
+	})
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to encode profile: %v", err)
+	}
+
+	patched, err := mergepatch.Apply(current, patch)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.BadRequest)
+	}
+
+	var document ProfilePatchDocument
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&document)
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.BadRequest, "invalid merge patch: %v", err)
+	}
+	document.Username = strings.TrimSpace(document.Username)
+	document.Email = strings.TrimSpace(document.Email)
+	if err := validator.Validate(document); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.BadRequest)
+	}
+
+	if document.Username != user.Username {
+		existing, err := s.repo.GetByUsername(ctx, document.Username)
+		if err == nil && existing.ID != user.ID {
+			return nil, apperrors.New(apperrors.Conflict, "username already exists")
+		}
+	}
+	emailChanged := document.Email != user.Email
+	if emailChanged {
+		existing, err := s.repo.GetByEmail(ctx, document.Email)
+		if err == nil && existing.ID != user.ID {
+			return nil, apperrors.New(apperrors.Conflict, "email already exists")
+		}
+		user.EmailVerifiedAt = nil
+	}
+
+	user.Username = document.Username
+	user.Email = document.Email
+	user.TimeZone = document.TimeZone
+	user.UpdatedAt = time.Now()
+
+	err = s.repo.Update(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if emailChanged {
+		go s.sendEmailVerification(user)
+	}
+
+	s.logger.Info().
+		Str("userID", user.ID.String()).
+		Bool("emailChanged", emailChanged).
+		Msg("profile updated successfully")
+
+	return newUserResponse(user), nil
+}
+
+// ChangePassword replaces the user's password after checking the current
+// one, and logs out every other session. currentFamilyID is the token family
+// of the request, which stays logged in.
+func (s *UserService) ChangePassword(ctx context.Context, userID uuid.UUID, input PasswordChangeInput, currentFamilyID string) error {
+	if err := validator.Validate(input); err != nil {
+		return apperrors.Wrap(err, apperrors.BadRequest)
+	}
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !auth.CheckPasswordHash(input.CurrentPassword, user.PasswordHash) {
+		return apperrors.New(apperrors.Unauthorized, "current password is incorrect")
+	}
+	if input.NewPassword == input.CurrentPassword {
+		return apperrors.New(apperrors.BadRequest, "new password must differ from the current one")
+	}
+
+	return s.setPassword(ctx, user, input.NewPassword, currentFamilyID)
+}
+
+// GetSessions lists where the user is logged in. currentFamilyID is the token
+// family of the request, marked as the current session.
+func (s *UserService) GetSessions(ctx context.Context, userID uuid.UUID, currentFamilyID string) ([]SessionResponse, error) {
+	sessions, err := s.tokenSvc.ListSessions(ctx, userID, currentFamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSessionResponses(sessions), nil
+}
+
+func (s *UserService) RevokeSession(ctx context.Context, userID uuid.UUID, sessionID string) error {
+	err := s.tokenSvc.RevokeSession(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info().
+		Str("userID", userID.String()).
+		Str("sessionID", sessionID).
+		Msg("session revoked successfully")
+
+	return nil
+}
+
+// LogoutAll revokes every session of the user, including the current one.
+func (s *UserService) LogoutAll(ctx context.Context, userID uuid.UUID) error {
+	revoked, err := s.tokenSvc.RevokeAllSessions(ctx, userID, "")
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info().
+		Str("userID", userID.String()).
+		Int("sessions", revoked).
+		Msg("user logged out everywhere successfully")
+
+	return nil
+}
+
+// ForgotPassword emails a password reset link to the user with the email
+// address, if there is one. The response is the same either way, and the
+// email is sent in the background so response times don't tell either.
+func (s *UserService) ForgotPassword(ctx context.Context, input PasswordForgotInput) (*MessageResponse, error) {
+	if err := validator.Validate(input); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.BadRequest)
+	}
+
+	accepted := &MessageResponse{
+		Message: "If an account with that email exists, a password reset link has been sent to it.",
+	}
+
+	user, err := s.repo.GetByEmail(ctx, input.Email)
+	if err != nil {
+		var appErr apperrors.Error
+		if errors.As(err, &appErr) && appErr.Type == apperrors.NotFound {
+			return accepted, nil
+		}
+		return nil, err
+	}
+
+	go s.sendPasswordReset(user)
+
+	return accepted, nil
+}
+
+func (s *UserService) sendPasswordReset(user *domain.User) {
+	ctx, cancel := context.WithTimeout(context.Background(), accountMailTimeout)
+	defer cancel()
+
+	token, err := s.tokenSvc.GeneratePasswordResetToken(ctx, user.ID)
+	if err != nil {
+		s.logger.Error().Err(err).Str("userID", user.ID.String()).Msg("failed to create password reset token")
+		return
+	}
+
+	link, err := withQuery(s.links.PasswordReset, "token", token)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("invalid password reset URL")
+		return
+	}
+
+	err = s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\r\n\r\n"+
+			"Someone asked to reset the password of your account. If it was you, open this link to choose a new password:\r\n\r\n"+
+			"%s\r\n\r\n"+
+			"The link can be used once and expires in %d minutes. If you didn't ask for it, you can ignore this email.\r\n",
+			user.Username, link, int(s.tokenSvc.PasswordResetTTL().Minutes())),
+	})
+	if err != nil {
+		s.logger.Error().Err(err).Str("userID", user.ID.String()).Msg("failed to send password reset email")
+		return
+	}
+
+	s.logger.Info().
+		Str("userID", user.ID.String()).
+		Msg("password reset email sent successfully")
+}
+
+// ResetPassword sets a new password with a token from a password reset email
+// and logs the user out everywhere.
+func (s *UserService) ResetPassword(ctx context.Context, input PasswordResetInput) error {
+	if err := validator.Validate(input); err != nil {
+		return apperrors.Wrap(err, apperrors.BadRequest)
+	}
+
+	userID, err := s.tokenSvc.ConsumePasswordResetToken(ctx, input.Token)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		var appErr apperrors.Error
+		if errors.As(err, &appErr) && appErr.Type == apperrors.NotFound {
+			return apperrors.New(apperrors.BadRequest, "invalid or expired password reset token")
+		}
+		return err
+	}
+
+	return s.setPassword(ctx, user, input.Password, "")
+}
+
+// VerifyEmail marks the email address of a verification link as verified,
+// if it is still the user's address.
+func (s *UserService) VerifyEmail(ctx context.Context, input EmailVerifyInput) (*UserResponse, error) {
+	if err := validator.Validate(input); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.BadRequest)
+	}
+
+	userID, email, err := s.tokenSvc.ParseEmailVerificationToken(input.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		var appErr apperrors.Error
+		if errors.As(err, &appErr) && appErr.Type == apperrors.NotFound {
+			return nil, apperrors.New(apperrors.BadRequest, "invalid or expired verification token")
+		}
+		return nil, err
+	}
+	if user.Email != email {
+		return nil, apperrors.New(apperrors.BadRequest, "verification link is for a previous email address")
+	}
+	if user.EmailVerifiedAt != nil {
+		return newUserResponse(user), nil
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	user.UpdatedAt = now
+	err = s.repo.Update(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("userID", user.ID.String()).
+		Msg("email verified successfully")
+
+	return newUserResponse(user), nil
+}
+
+// ResendEmailVerification sends a new verification link to the user's email
+// address.
+func (s *UserService) ResendEmailVerification(ctx context.Context, userID uuid.UUID) (*MessageResponse, error) {
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil, apperrors.New(apperrors.Conflict, "email address is already verified")
+	}
+
+	go s.sendEmailVerification(user)
+
+	return &MessageResponse{
+		Message: "A verification link has been sent to " + user.Email + ".",
+	}, nil
+}
+
+func (s *UserService) sendEmailVerification(user *domain.User) {
+	ctx, cancel := context.WithTimeout(context.Background(), accountMailTimeout)
+	defer cancel()
+
+	token, err := s.tokenSvc.GenerateEmailVerificationToken(user.ID, user.Email)
+	if err != nil {
+		s.logger.Error().Err(err).Str("userID", user.ID.String()).Msg("failed to create email verification token")
+		return
+	}
+
+	link, err := withQuery(s.links.EmailVerification, "token", token)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("invalid email verification URL")
+		return
+	}
+
+	err = s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\r\n\r\n"+
+			"Open this link to confirm that %s is your email address:\r\n\r\n"+
+			"%s\r\n\r\n"+
+			"The link expires in %d hours. If you didn't create an account, you can ignore this email.\r\n",
+			user.Username, user.Email, link, int(s.tokenSvc.EmailVerificationTTL().Hours())),
+	})
+	if err != nil {
+		s.logger.Error().Err(err).Str("userID", user.ID.String()).Msg("failed to send email verification")
+		return
+	}
+
+	s.logger.Info().
+		Str("userID", user.ID.String()).
+		Msg("email verification sent successfully")
+}
+
+// setPassword replaces the user's password and revokes their sessions other
+// than the one of keepFamilyID, so a changed password locks out anyone who
+// knew the old one. Every password change goes through it.
+func (s *UserService) setPassword(ctx context.Context, user *domain.User, password string, keepFamilyID string) error {
+	hashedPassword, err := auth.HashPassword(password)
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to hash password: %v", err)
+	}
+
+	user.PasswordHash = hashedPassword
+	user.UpdatedAt = time.Now()
+	err = s.repo.UpdatePassword(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := s.tokenSvc.RevokeAllSessions(ctx, user.ID, keepFamilyID)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info().
+		Str("userID", user.ID.String()).
+		Int("revokedSessions", revoked).
+		Msg("password changed successfully")
+
+	return nil
+}
+
+// withQuery adds a query parameter to a URL.
+func withQuery(rawURL, key, value string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set(key, value)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+func newAuthResponse(user *domain.User, tokens *auth.TokenPair) *AuthResponse {
+	return &AuthResponse{
+		User:             *newUserResponse(user),
+		Token:            tokens.AccessToken,
+		ExpiresAt:        tokens.ExpiresAt,
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
+	}
+}
+
+func newSessionResponses(sessions []*auth.Session) []SessionResponse {
+	responses := make([]SessionResponse, len(sessions))
+	for i, session := range sessions {
+		responses[i] = SessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			Current:    session.Current,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+		}
+	}
+	return responses
+}
+
+func newUserResponse(user *domain.User) *UserResponse {
+	return &UserResponse{
+		ID:              user.ID.String(),
+		Username:        user.Username,
+		Email:           user.Email,
+		EmailVerified:   user.EmailVerifiedAt != nil,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		MFAEnabled:      user.MFAEnabled(),
+		TimeZone:        user.TimeZone,
+		CreatedAt:       user.CreatedAt,
+	}
+}
@@ -5,13 +5,16 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/url"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/google/uuid"
 	cfg "github.com/mmd-moradi/goup/configs"
 	"github.com/mmd-moradi/goup/internal/domain"
@@ -19,6 +22,10 @@ import (
 	"github.com/rs/zerolog"
 )
 
+// exportPartSize is the size of the parts an export archive is uploaded in.
+// Only one part is held in memory at a time.
+const exportPartSize = 8 << 20
+
 type S3StorageService struct {
 	s3Client *s3.Client
 	bucket   string
@@ -51,14 +58,7 @@ func NewS3StorageService(cfg *cfg.AWSConfig, logger zerolog.Logger) (*S3StorageS
 }
 
 func (s *S3StorageService) UploadPhoto(ctx context.Context, data []byte, userID uuid.UUID, photo *domain.Photo) error {
-	timestamp := time.Now().Format("20060102-150405")
-	storagePath := fmt.Sprintf(
-		"users/%s/photos/%s-%s%s",
-		userID.String(),
-		timestamp,
-		uuid.New().String()[:8],
-		filepath.Ext(photo.FileName),
-	)
+	storagePath := photoStoragePath(userID, photo.FileName)
 
 	input := &s3.PutObjectInput{
 		Bucket:      aws.String(s.bucket),
@@ -73,7 +73,7 @@ func (s *S3StorageService) UploadPhoto(ctx context.Context, data []byte, userID
 	}
 
 	photo.StoragePath = storagePath
-	photo.PublicURL = fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.bucket, s.cfg.Region, storagePath)
+	photo.PublicURL = s.publicURL(storagePath)
 
 	s.loger.Info().
 		Str("userID", userID.String()).
@@ -84,6 +84,33 @@ func (s *S3StorageService) UploadPhoto(ctx context.Context, data []byte, userID
 	return nil
 }
 
+func (s *S3StorageService) CopyTransferredPhoto(ctx context.Context, storagePath string, userID, transferID uuid.UUID, fileName string) (string, string, error) {
+	copyPath := transferStoragePrefix(userID, transferID) + storageFileName(fileName)
+
+	segments := strings.Split(storagePath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	_, err := s.s3Client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     aws.String(s.bucket),
+		Key:        aws.String(copyPath),
+		CopySource: aws.String(s.bucket + "/" + strings.Join(segments, "/")),
+	})
+	if err != nil {
+		return "", "", apperrors.NewWithFormat(apperrors.InternalServer, "failed to copy photo: %v", err)
+	}
+
+	s.loger.Info().
+		Str("userID", userID.String()).
+		Str("transferID", transferID.String()).
+		Str("from", storagePath).
+		Str("path", copyPath).
+		Msg("Photo copied in s3 successfully")
+
+	return copyPath, s.publicURL(copyPath), nil
+}
+
 func (s *S3StorageService) GetPhoto(ctx context.Context, storagePath string) ([]byte, string, error) {
 	input := &s3.GetObjectInput{
 		Bucket: aws.String(s.bucket),
@@ -125,3 +152,225 @@ func (s *S3StorageService) DeletePhoto(ctx context.Context, storagePath string)
 
 	return nil
 }
+
+func (s *S3StorageService) OpenPhoto(ctx context.Context, storagePath string) (io.ReadCloser, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(storagePath),
+	}
+
+	output, err := s.s3Client.GetObject(ctx, input)
+	if err != nil {
+		return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to get from S3")
+	}
+
+	return output.Body, nil
+}
+
+func (s *S3StorageService) UploadExport(ctx context.Context, userID, exportID uuid.UUID, body io.Reader) (string, error) {
+	storagePath := fmt.Sprintf("%sexports/%s.zip", userStoragePrefix(userID), exportID.String())
+
+	upload, err := s.s3Client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(storagePath),
+		ContentType: aws.String("application/zip"),
+	})
+	if err != nil {
+		return "", apperrors.NewWithFormat(apperrors.InternalServer, "failed to start export upload: %v", err)
+	}
+
+	parts, err := s.uploadParts(ctx, storagePath, upload.UploadId, body)
+	if err != nil {
+		_, abortErr := s.s3Client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(s.bucket),
+			Key:      aws.String(storagePath),
+			UploadId: upload.UploadId,
+		})
+		if abortErr != nil {
+			s.loger.Error().Err(abortErr).Str("path", storagePath).Msg("failed to abort export upload")
+		}
+		return "", err
+	}
+
+	_, err = s.s3Client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.bucket),
+		Key:             aws.String(storagePath),
+		UploadId:        upload.UploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		return "", apperrors.NewWithFormat(apperrors.InternalServer, "failed to complete export upload: %v", err)
+	}
+
+	s.loger.Info().
+		Str("userID", userID.String()).
+		Str("exportID", exportID.String()).
+		Str("path", storagePath).
+		Msg("Export uploaded to s3 successfully")
+
+	return storagePath, nil
+}
+
+func (s *S3StorageService) DeleteExport(ctx context.Context, storagePath string) error {
+	_, err := s.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(storagePath),
+	})
+	if err != nil {
+		return apperrors.NewWithFormat(apperrors.InternalServer, "failed to delete export from S3: %v", err)
+	}
+	s.loger.Info().
+		Str("path", storagePath).
+		Msg("Export deleted from s3 successfully")
+
+	return nil
+}
+
+func (s *S3StorageService) uploadParts(ctx context.Context, storagePath string, uploadID *string, body io.Reader) ([]types.CompletedPart, error) {
+	var parts []types.CompletedPart
+	buf := make([]byte, exportPartSize)
+
+	for partNumber := int32(1); ; partNumber++ {
+		n, readErr := io.ReadFull(body, buf)
+		if readErr != nil && readErr != io.ErrUnexpectedEOF && readErr != io.EOF {
+			return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to read export: %v", readErr)
+		}
+		// S3 requires at least one part, even for an empty body.
+		if n == 0 && len(parts) > 0 {
+			return parts, nil
+		}
+
+		output, err := s.s3Client.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:     aws.String(s.bucket),
+			Key:        aws.String(storagePath),
+			UploadId:   uploadID,
+			PartNumber: aws.Int32(partNumber),
+			Body:       bytes.NewReader(buf[:n]),
+		})
+		if err != nil {
+			return nil, apperrors.NewWithFormat(apperrors.InternalServer, "failed to upload export part: %v", err)
+		}
+		parts = append(parts, types.CompletedPart{
+			ETag:       output.ETag,
+			PartNumber: aws.Int32(partNumber),
+		})
+
+		if readErr != nil {
+			return parts, nil
+		}
+	}
+}
+
+func (s *S3StorageService) GetDownloadURL(ctx context.Context, storagePath, fileName string, ttl time.Duration) (string, error) {
+	presignClient := s3.NewPresignClient(s.s3Client)
+
+	request, err := presignClient.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket:                     aws.String(s.bucket),
+		Key:                        aws.String(storagePath),
+		ResponseContentDisposition: aws.String(fmt.Sprintf("attachment; filename=%q", fileName)),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", apperrors.NewWithFormat(apperrors.InternalServer, "failed to create download URL: %v", err)
+	}
+
+	return request.URL, nil
+}
+
+func (s *S3StorageService) DeleteUserFiles(ctx context.Context, userID uuid.UUID) (int, error) {
+	deleted, err := s.deletePrefix(ctx, userStoragePrefix(userID))
+	if err != nil {
+		return deleted, err
+	}
+
+	s.loger.Info().
+		Str("userID", userID.String()).
+		Int("files", deleted).
+		Msg("User files deleted from s3 successfully")
+
+	return deleted, nil
+}
+
+func (s *S3StorageService) DeleteTransferFiles(ctx context.Context, userID, transferID uuid.UUID) (int, error) {
+	deleted, err := s.deletePrefix(ctx, transferStoragePrefix(userID, transferID))
+	if err != nil {
+		return deleted, err
+	}
+
+	s.loger.Info().
+		Str("userID", userID.String()).
+		Str("transferID", transferID.String()).
+		Int("files", deleted).
+		Msg("Transfer files deleted from s3 successfully")
+
+	return deleted, nil
+}
+
+// deletePrefix deletes every object whose key starts with prefix and returns
+// how many it deleted.
+func (s *S3StorageService) deletePrefix(ctx context.Context, prefix string) (int, error) {
+	deleted := 0
+
+	paginator := s3.NewListObjectsV2Paginator(s.s3Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return deleted, apperrors.NewWithFormat(apperrors.InternalServer, "failed to list files: %v", err)
+		}
+		if len(page.Contents) == 0 {
+			continue
+		}
+
+		// A page holds at most 1000 keys, the most DeleteObjects accepts.
+		objects := make([]types.ObjectIdentifier, len(page.Contents))
+		for i, object := range page.Contents {
+			objects[i] = types.ObjectIdentifier{Key: object.Key}
+		}
+		output, err := s.s3Client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(s.bucket),
+			Delete: &types.Delete{Objects: objects, Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return deleted, apperrors.NewWithFormat(apperrors.InternalServer, "failed to delete files: %v", err)
+		}
+		if len(output.Errors) > 0 {
+			return deleted, apperrors.NewWithFormat(apperrors.InternalServer, "failed to delete file %s: %s", aws.ToString(output.Errors[0].Key), aws.ToString(output.Errors[0].Message))
+		}
+		deleted += len(objects)
+	}
+
+	return deleted, nil
+}
+
+// userStoragePrefix is the prefix of every key stored for the user.
+func userStoragePrefix(userID uuid.UUID) string {
+	return fmt.Sprintf("users/%s/", userID.String())
+}
+
+// transferStoragePrefix is the prefix of the copies made for a transfer to
+// the user, so those of a transfer that never finished can be found.
+func transferStoragePrefix(userID, transferID uuid.UUID) string {
+	return fmt.Sprintf("%stransfers/%s/", userStoragePrefix(userID), transferID.String())
+}
+
+// photoStoragePath returns a new, unique key for a file of the user's.
+func photoStoragePath(userID uuid.UUID, fileName string) string {
+	return userStoragePrefix(userID) + "photos/" + storageFileName(fileName)
+}
+
+// storageFileName returns a new, unique name for a file, keeping its
+// extension.
+func storageFileName(fileName string) string {
+	return fmt.Sprintf(
+		"%s-%s%s",
+		time.Now().Format("20060102-150405"),
+		uuid.New().String()[:8],
+		filepath.Ext(fileName),
+	)
+}
+
+func (s *S3StorageService) publicURL(storagePath string) string {
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.bucket, s.cfg.Region, storagePath)
+}
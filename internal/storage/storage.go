@@ -2,6 +2,8 @@ package storage
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/mmd-moradi/goup/internal/domain"
@@ -11,4 +13,23 @@ type StorageService interface {
 	UploadPhoto(ctx context.Context, data []byte, userID uuid.UUID, photo *domain.Photo) error
 	GetPhoto(ctx context.Context, storagePath string) ([]byte, string, error)
 	DeletePhoto(ctx context.Context, storagePath string) error
+	// CopyTransferredPhoto copies a stored file to a new path under the
+	// recipient of a transfer, kept apart per transfer, and returns the path
+	// and public URL of the copy.
+	CopyTransferredPhoto(ctx context.Context, storagePath string, userID, transferID uuid.UUID, fileName string) (string, string, error)
+	// OpenPhoto streams the stored object instead of reading it into memory.
+	OpenPhoto(ctx context.Context, storagePath string) (io.ReadCloser, error)
+	// UploadExport stores an export archive read from body, whose length is
+	// not known in advance, and returns its storage path.
+	UploadExport(ctx context.Context, userID, exportID uuid.UUID, body io.Reader) (string, error)
+	DeleteExport(ctx context.Context, storagePath string) error
+	// GetDownloadURL returns a URL that downloads the object as fileName and
+	// stops working after ttl.
+	GetDownloadURL(ctx context.Context, storagePath, fileName string, ttl time.Duration) (string, error)
+	// DeleteUserFiles deletes every object stored under userID, including
+	// files no record points to anymore, and returns how many it deleted.
+	DeleteUserFiles(ctx context.Context, userID uuid.UUID) (int, error)
+	// DeleteTransferFiles deletes every copy CopyTransferredPhoto made for a
+	// transfer to userID and returns how many it deleted.
+	DeleteTransferFiles(ctx context.Context, userID, transferID uuid.UUID) (int, error)
 }
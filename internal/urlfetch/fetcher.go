@@ -0,0 +1,296 @@
+// Package urlfetch downloads user supplied URLs without letting them reach
+// the internal network. Host names are resolved before connecting and the
+// connection is made to the checked address, so a DNS answer changing
+// between the check and the request can't redirect it.
+package urlfetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmd-moradi/goup/pkg/apperrors"
+)
+
+// Resolver looks up the addresses of a host. *net.Resolver implements it.
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+type Config struct {
+	// Timeout bounds the whole fetch, redirects and body included.
+	Timeout time.Duration
+	// MaxSize is the largest body accepted, in bytes.
+	MaxSize int64
+	// MaxRedirects is how many redirects are followed.
+	MaxRedirects int
+	// AllowedSchemes defaults to http and https.
+	AllowedSchemes []string
+	// Resolver defaults to net.DefaultResolver.
+	Resolver Resolver
+	// AllowIP reports whether connecting to ip is allowed and defaults to
+	// IsPublicIP.
+	AllowIP func(ip net.IP) bool
+}
+
+// Result is a fetched document.
+type Result struct {
+	Data        []byte
+	ContentType string
+	// FileName is the last segment of the final URL's path, if any.
+	FileName string
+}
+
+type Fetcher struct {
+	client         *http.Client
+	resolver       Resolver
+	allowIP        func(ip net.IP) bool
+	allowedSchemes map[string]bool
+	maxSize        int64
+}
+
+func New(cfg Config) *Fetcher {
+	f := &Fetcher{
+		resolver:       cfg.Resolver,
+		allowIP:        cfg.AllowIP,
+		allowedSchemes: make(map[string]bool),
+		maxSize:        cfg.MaxSize,
+	}
+	if f.resolver == nil {
+		f.resolver = net.DefaultResolver
+	}
+	if f.allowIP == nil {
+		f.allowIP = IsPublicIP
+	}
+
+	schemes := cfg.AllowedSchemes
+	if len(schemes) == 0 {
+		schemes = []string{"http", "https"}
+	}
+	for _, scheme := range schemes {
+		f.allowedSchemes[strings.ToLower(scheme)] = true
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	transport := &http.Transport{
+		// Proxies from the environment would connect on our behalf to
+		// addresses that were never checked.
+		Proxy: nil,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			ip, port, err := f.resolve(ctx, addr)
+			if err != nil {
+				return nil, err
+			}
+			return dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		},
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: cfg.Timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	f.client = &http.Client{
+		Transport: transport,
+		Timeout:   cfg.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > cfg.MaxRedirects {
+				return apperrors.NewWithFormat(apperrors.BadRequest, "too many redirects, at most %d are followed", cfg.MaxRedirects)
+			}
+			return f.checkURL(req.URL)
+		},
+	}
+
+	return f
+}
+
+// Fetch downloads rawURL. Every address connected to, including those of
+// redirects, must be allowed by the configured AllowIP.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Result, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, apperrors.New(apperrors.BadRequest, "invalid URL")
+	}
+	if err := f.checkURL(u); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, apperrors.New(apperrors.BadRequest, "invalid URL")
+	}
+	req.Header.Set("Accept", "image/*")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fetchError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apperrors.NewWithFormat(apperrors.BadGateway, "remote server responded with status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > f.maxSize {
+		return nil, apperrors.NewWithFormat(apperrors.BadRequest, "remote file exceeds the maximum size of %d bytes", f.maxSize)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.maxSize+1))
+	if err != nil {
+		return nil, fetchError(err)
+	}
+	if int64(len(data)) > f.maxSize {
+		return nil, apperrors.NewWithFormat(apperrors.BadRequest, "remote file exceeds the maximum size of %d bytes", f.maxSize)
+	}
+
+	return &Result{
+		Data:        data,
+		ContentType: contentType(resp.Header.Get("Content-Type"), data),
+		FileName:    path.Base(resp.Request.URL.Path),
+	}, nil
+}
+
+func (f *Fetcher) checkURL(u *url.URL) error {
+	if !f.allowedSchemes[strings.ToLower(u.Scheme)] {
+		return apperrors.NewWithFormat(apperrors.BadRequest, "URL scheme %q is not allowed", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return apperrors.New(apperrors.BadRequest, "URL must have a host")
+	}
+	if u.User != nil {
+		return apperrors.New(apperrors.BadRequest, "URL must not contain credentials")
+	}
+	return nil
+}
+
+// resolve returns the first allowed address of the host in addr. Any
+// disallowed address fails the lookup, so a host can't mix a public address
+// with an internal one.
+func (f *Fetcher) resolve(ctx context.Context, addr string) (net.IP, string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := strconv.Atoi(port); err != nil {
+		return nil, "", fmt.Errorf("invalid port %q", port)
+	}
+
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		ips = []net.IP{ip}
+	} else {
+		addrs, err := f.resolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, a := range addrs {
+			ips = append(ips, a.IP)
+		}
+	}
+
+	if len(ips) == 0 {
+		return nil, "", fmt.Errorf("no addresses found for %s", host)
+	}
+	for _, ip := range ips {
+		if !f.allowIP(ip) {
+			return nil, "", errBlockedAddress
+		}
+	}
+
+	return ips[0], port, nil
+}
+
+var errBlockedAddress = errors.New("address is not allowed")
+
+// IsPublicIP reports whether ip is a globally routable unicast address.
+// Loopback, private, link-local, multicast and unspecified addresses are
+// rejected, as are the shared, benchmarking and reserved IPv4 ranges and
+// IPv4 addresses embedded in IPv6 ones.
+func IsPublicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+
+	for _, block := range nonPublicBlocks {
+		if block.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+var nonPublicBlocks = func() []*net.IPNet {
+	cidrs := []string{
+		"0.0.0.0/8",       // "this" network
+		"100.64.0.0/10",   // carrier grade NAT
+		"192.0.0.0/24",    // IETF protocol assignments
+		"192.0.2.0/24",    // documentation
+		"198.18.0.0/15",   // benchmarking
+		"198.51.100.0/24", // documentation
+		"203.0.113.0/24",  // documentation
+		"240.0.0.0/4",     // reserved, including broadcast
+		"64:ff9b::/96",    // IPv4/IPv6 translation
+		"64:ff9b:1::/48",  // local IPv4/IPv6 translation
+		"100::/64",        // discard only
+		"2001::/32",       // Teredo
+		"2001:db8::/32",   // documentation
+		"2002::/16",       // 6to4
+		"fc00::/7",        // unique local
+		"fec0::/10",       // deprecated site local
+	}
+	blocks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, block, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		blocks[i] = block
+	}
+	return blocks
+}()
+
+// fetchError converts a failed request into the error reported to the user.
+func fetchError(err error) error {
+	var appErr apperrors.Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	if errors.Is(err, errBlockedAddress) {
+		return apperrors.New(apperrors.BadRequest, "URL points to an address that is not allowed")
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return apperrors.New(apperrors.BadGateway, "timed out fetching URL")
+	}
+	return apperrors.New(apperrors.BadGateway, "failed to fetch URL")
+}
+
+// contentType returns the media type of a fetched body. The body is sniffed
+// so a server can't pass off a document as an image; the Content-Type header
+// is only used for formats the sniffer doesn't recognize.
+func contentType(header string, data []byte) string {
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	if sniffed != "application/octet-stream" {
+		return sniffed
+	}
+
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil || mediaType == "" {
+		return sniffed
+	}
+	return mediaType
+}
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE photos
+    ADD COLUMN visibility VARCHAR(16) NOT NULL DEFAULT 'private'
+    CHECK (visibility IN ('private', 'unlisted', 'public'));
+
+CREATE INDEX idx_photos_public_feed ON photos(created_at DESC, id DESC) WHERE visibility = 'public';
+CREATE INDEX idx_photos_user_public ON photos(user_id, created_at DESC) WHERE visibility = 'public';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_photos_user_public;
+DROP INDEX IF EXISTS idx_photos_public_feed;
+ALTER TABLE photos DROP COLUMN IF EXISTS visibility;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE albums (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_albums_user_id ON albums(user_id);
+
+ALTER TABLE photos ADD COLUMN album_id UUID REFERENCES albums(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_photos_album_id ON photos(album_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE photos DROP COLUMN IF EXISTS album_id;
+DROP TABLE IF EXISTS albums;
+-- +goose StatementEnd
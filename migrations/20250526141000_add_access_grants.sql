@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE access_grants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    photo_id UUID REFERENCES photos(id) ON DELETE CASCADE,
+    album_id UUID REFERENCES albums(id) ON DELETE CASCADE,
+    grantee_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    granted_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('viewer', 'commenter', 'editor')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK ((photo_id IS NULL) <> (album_id IS NULL))
+);
+
+CREATE UNIQUE INDEX idx_access_grants_photo_grantee ON access_grants(photo_id, grantee_id) WHERE photo_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_access_grants_album_grantee ON access_grants(album_id, grantee_id) WHERE album_id IS NOT NULL;
+CREATE INDEX idx_access_grants_grantee_id ON access_grants(grantee_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS access_grants;
+-- +goose StatementEnd
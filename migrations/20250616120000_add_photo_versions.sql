@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE photo_versions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    photo_id UUID NOT NULL REFERENCES photos(id) ON DELETE CASCADE,
+    version_number INT NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    file_size BIGINT NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    storage_path VARCHAR(512) NOT NULL,
+    public_url VARCHAR(512),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (photo_id, version_number)
+);
+
+CREATE INDEX idx_photo_versions_photo_id ON photo_versions(photo_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS photo_versions;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE photos ADD COLUMN latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90);
+ALTER TABLE photos ADD COLUMN longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180);
+ALTER TABLE photos ADD CONSTRAINT photos_location_complete CHECK ((latitude IS NULL) = (longitude IS NULL));
+
+-- Bounding box scans filter on the latitude range first, then longitude.
+CREATE INDEX idx_photos_location ON photos(user_id, latitude, longitude) WHERE latitude IS NOT NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE photos DROP CONSTRAINT IF EXISTS photos_location_complete;
+ALTER TABLE photos DROP COLUMN IF EXISTS longitude;
+ALTER TABLE photos DROP COLUMN IF EXISTS latitude;
+-- +goose StatementEnd
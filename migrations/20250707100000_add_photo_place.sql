@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE photos ADD COLUMN place_name TEXT;
+ALTER TABLE photos ADD COLUMN country_code TEXT;
+ALTER TABLE photos ADD COLUMN country_name TEXT;
+
+CREATE INDEX idx_photos_place ON photos(user_id, lower(place_name)) WHERE place_name IS NOT NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_photos_place;
+ALTER TABLE photos DROP COLUMN IF EXISTS country_name;
+ALTER TABLE photos DROP COLUMN IF EXISTS country_code;
+ALTER TABLE photos DROP COLUMN IF EXISTS place_name;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE photos ADD COLUMN taken_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE users ADD COLUMN time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- Photos are listed and grouped by when they were taken, falling back to
+-- when they were uploaded.
+CREATE INDEX idx_photos_user_date ON photos(user_id, (COALESCE(taken_at, created_at)) DESC, id DESC);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_photos_user_date;
+ALTER TABLE users DROP COLUMN IF EXISTS time_zone;
+ALTER TABLE photos DROP COLUMN IF EXISTS taken_at;
+-- +goose StatementEnd
@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE ownership_transfers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    from_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    to_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'declined', 'canceled', 'failed')),
+    selection VARCHAR(16) NOT NULL CHECK (selection IN ('photos', 'albums', 'all')),
+    photo_ids UUID[] NOT NULL DEFAULT '{}',
+    album_ids UUID[] NOT NULL DEFAULT '{}',
+    photo_count INT NOT NULL DEFAULT 0,
+    error TEXT,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (from_user_id <> to_user_id)
+);
+
+CREATE INDEX idx_ownership_transfers_from_user_id ON ownership_transfers(from_user_id);
+CREATE INDEX idx_ownership_transfers_to_user_id ON ownership_transfers(to_user_id);
+
+-- Audit events have no foreign keys so they outlive the users and records
+-- they describe.
+CREATE TABLE audit_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    actor_id UUID,
+    action VARCHAR(64) NOT NULL,
+    subject_type VARCHAR(32) NOT NULL,
+    subject_id UUID NOT NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_events_subject ON audit_events(subject_type, subject_id);
+CREATE INDEX idx_audit_events_actor_id ON audit_events(actor_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS audit_events;
+DROP TABLE IF EXISTS ownership_transfers;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- NULL until the user opens the verification link sent to their current
+-- email address.
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP WITH TIME ZONE;
+
+-- Accounts created before verification existed were never sent a link, so
+-- they are treated as verified rather than losing access to restricted
+-- features.
+UPDATE users SET email_verified_at = created_at;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- deletion_scheduled_at is when the grace period of a requested account
+-- deletion ends; NULL unless the user asked for their account to be
+-- deleted. erasure_started_at is when a worker claimed the account for
+-- erasure, so a stale claim can be picked up again.
+ALTER TABLE users
+    ADD COLUMN deletion_scheduled_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN erasure_started_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_users_deletion_scheduled_at ON users(deletion_scheduled_at)
+WHERE deletion_scheduled_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_users_deletion_scheduled_at;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS erasure_started_at,
+    DROP COLUMN IF EXISTS deletion_scheduled_at;
+-- +goose StatementEnd
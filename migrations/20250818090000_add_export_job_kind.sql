@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- 'account' jobs export everything held about the user, 'photos' jobs a
+-- selection of photos.
+ALTER TABLE export_jobs
+    ADD COLUMN kind VARCHAR(16) NOT NULL DEFAULT 'photos' CHECK (kind IN ('photos', 'account'));
+
+CREATE INDEX idx_export_jobs_user_id_kind ON export_jobs(user_id, kind, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_export_jobs_user_id_kind;
+ALTER TABLE export_jobs DROP COLUMN IF EXISTS kind;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Finds the jobs a restart cut short.
+CREATE INDEX idx_export_jobs_active ON export_jobs(created_at) WHERE status IN ('pending', 'running');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_export_jobs_active;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Finds the transfers a restart cut short.
+CREATE INDEX idx_ownership_transfers_running ON ownership_transfers(updated_at) WHERE status = 'running';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_ownership_transfers_running;
+-- +goose StatementEnd
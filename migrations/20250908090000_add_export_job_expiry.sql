@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Jobs whose archive was deleted once its download link expired.
+ALTER TABLE export_jobs DROP CONSTRAINT export_jobs_status_check;
+ALTER TABLE export_jobs ADD CONSTRAINT export_jobs_status_check
+    CHECK (status IN ('pending', 'running', 'completed', 'failed', 'expired'));
+
+-- Finds the completed jobs whose archive has to be deleted.
+CREATE INDEX idx_export_jobs_completed ON export_jobs(expires_at) WHERE status = 'completed';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_export_jobs_completed;
+UPDATE export_jobs SET status = 'completed' WHERE status = 'expired';
+ALTER TABLE export_jobs DROP CONSTRAINT export_jobs_status_check;
+ALTER TABLE export_jobs ADD CONSTRAINT export_jobs_status_check
+    CHECK (status IN ('pending', 'running', 'completed', 'failed'));
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Only the newest of several active data exports of a user can still be
+-- running; the others are failed so the index below can be built.
+UPDATE export_jobs j
+SET status = 'failed',
+    error = 'the export was interrupted, please request a new one',
+    updated_at = NOW()
+WHERE j.kind = 'account'
+  AND j.status IN ('pending', 'running')
+  AND EXISTS (
+      SELECT 1 FROM export_jobs newer
+      WHERE newer.user_id = j.user_id
+        AND newer.kind = 'account'
+        AND newer.status IN ('pending', 'running')
+        AND newer.created_at > j.created_at
+  );
+
+-- A user can run one data export at a time.
+CREATE UNIQUE INDEX idx_export_jobs_active_account ON export_jobs(user_id)
+    WHERE kind = 'account' AND status IN ('pending', 'running');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_export_jobs_active_account;
+-- +goose StatementEnd
@@ -8,13 +8,17 @@ import (
 type Type string
 
 const (
-	BadRequest         Type = "BAD_REQUEST"
-	NotFound           Type = "NOT_FOUND"
-	Conflict           Type = "CONFLICT"
-	Unauthorized       Type = "UNAUTHORIZED"
-	Forbidden          Type = "FORBIDDEN"
-	InternalServer     Type = "INTERNAL_SERVER"
-	ServiceUnavailable Type = "SERVICE_UNAVAILABLE"
+	BadRequest          Type = "BAD_REQUEST"
+	NotFound            Type = "NOT_FOUND"
+	Conflict            Type = "CONFLICT"
+	Unauthorized        Type = "UNAUTHORIZED"
+	Forbidden           Type = "FORBIDDEN"
+	InternalServer      Type = "INTERNAL_SERVER"
+	ServiceUnavailable  Type = "SERVICE_UNAVAILABLE"
+	PreconditionFailed  Type = "PRECONDITION_FAILED"
+	BadGateway          Type = "BAD_GATEWAY"
+	UnprocessableEntity Type = "UNPROCESSABLE_ENTITY"
+	TooManyRequests     Type = "TOO_MANY_REQUESTS"
 )
 
 type Error struct {
@@ -40,6 +44,14 @@ func (e Error) StatusCode() int {
 		return http.StatusForbidden
 	case ServiceUnavailable:
 		return http.StatusServiceUnavailable
+	case PreconditionFailed:
+		return http.StatusPreconditionFailed
+	case BadGateway:
+		return http.StatusBadGateway
+	case UnprocessableEntity:
+		return http.StatusUnprocessableEntity
+	case TooManyRequests:
+		return http.StatusTooManyRequests
 	default:
 		return http.StatusInternalServerError
 	}
@@ -0,0 +1,47 @@
+// Package mergepatch implements JSON Merge Patch as defined in RFC 7396.
+package mergepatch
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Apply applies patch to the JSON document target and returns the result.
+func Apply(target, patch []byte) ([]byte, error) {
+	var targetValue interface{}
+	if err := json.Unmarshal(target, &targetValue); err != nil {
+		return nil, fmt.Errorf("invalid target document: %w", err)
+	}
+
+	var patchValue interface{}
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %w", err)
+	}
+
+	return json.Marshal(merge(targetValue, patchValue))
+}
+
+// merge follows the MergePatch pseudo code of RFC 7396, section 2: objects
+// are merged member by member, null removes a member, and any other value
+// replaces the target.
+func merge(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+		targetObject[name] = merge(targetObject[name], value)
+	}
+
+	return targetObject
+}
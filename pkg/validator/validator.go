@@ -2,12 +2,29 @@ package validator
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/go-playground/validator/v10"
 )
 
-var validate = validator.New()
+var validate = newValidate()
+
+// fieldNamePattern is what the fieldname tag accepts. Names are used as
+// query parameters and JSON keys, so they are kept to a safe subset.
+var fieldNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+func newValidate() *validator.Validate {
+	v := validator.New()
+	v.RegisterValidation("fieldname", func(fl validator.FieldLevel) bool {
+		return fieldNamePattern.MatchString(fl.Field().String())
+	})
+	return v
+}
 
 func Validate(s interface{}) error {
 	if err := validate.Struct(s); err != nil {
@@ -36,7 +53,137 @@ func getErrorMessage(err validator.FieldError) string {
 		return fmt.Sprintf("must be at most %s characters long", err.Param())
 	case "gt":
 		return fmt.Sprintf("must be greater than %s", err.Param())
+	case "gte":
+		return fmt.Sprintf("must be at least %s", err.Param())
+	case "lte":
+		return fmt.Sprintf("must be at most %s", err.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of [%s]", err.Param())
+	case "timezone":
+		return "must be a valid IANA time zone"
+	case "uuid":
+		return "must be a valid UUID"
+	case "required_if":
+		return fmt.Sprintf("is required when %s", err.Param())
+	case "required_with":
+		return fmt.Sprintf("is required with %s", strings.ToLower(err.Param()))
+	case "fieldname":
+		return "must start with a lowercase letter and contain only lowercase letters, digits and underscores"
 	default:
 		return fmt.Sprintf("failed validation for tag %s", err.Tag())
 	}
 }
+
+type FieldType string
+
+const (
+	FieldText    FieldType = "text"
+	FieldNumber  FieldType = "number"
+	FieldBoolean FieldType = "boolean"
+	// FieldDate values are dates in YYYY-MM-DD format.
+	FieldDate FieldType = "date"
+)
+
+// DateLayout is the format of FieldDate values.
+const DateLayout = "2006-01-02"
+
+// maxTextFieldLength limits FieldText values, in characters.
+const maxTextFieldLength = 1000
+
+// FieldRule describes a field defined at runtime, such as a user defined
+// custom field, that can't be validated with struct tags.
+type FieldRule struct {
+	Name     string
+	Type     FieldType
+	Required bool
+	// AllowedValues restricts text, number and date fields to a set of
+	// values when not empty. Numbers are compared in their shortest
+	// decimal form, e.g. "1.5".
+	AllowedValues []string
+}
+
+// ValidateFields checks values decoded from JSON against rules. Every value
+// must have a rule, and errors are reported in the same format as Validate.
+func ValidateFields(rules []FieldRule, values map[string]interface{}) error {
+	byName := make(map[string]FieldRule, len(rules))
+	for _, rule := range rules {
+		byName[rule.Name] = rule
+	}
+
+	var errorMessages []string
+	for _, rule := range rules {
+		value, ok := values[rule.Name]
+		if !ok || value == nil {
+			if rule.Required {
+				errorMessages = append(errorMessages, fmt.Sprintf("field %s failed validation: is required", rule.Name))
+			}
+			continue
+		}
+		if message := checkField(rule, value); message != "" {
+			errorMessages = append(errorMessages, fmt.Sprintf("field %s failed validation: %s", rule.Name, message))
+		}
+	}
+
+	var unknown []string
+	for name := range values {
+		if _, ok := byName[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		errorMessages = append(errorMessages, fmt.Sprintf("field %s failed validation: is not defined", name))
+	}
+
+	if len(errorMessages) > 0 {
+		return fmt.Errorf("validation failed: %s", strings.Join(errorMessages, ", "))
+	}
+	return nil
+}
+
+func checkField(rule FieldRule, value interface{}) string {
+	var text string
+	switch rule.Type {
+	case FieldText:
+		v, ok := value.(string)
+		if !ok {
+			return "must be a string"
+		}
+		if utf8.RuneCountInString(v) > maxTextFieldLength {
+			return fmt.Sprintf("must be at most %d characters long", maxTextFieldLength)
+		}
+		text = v
+	case FieldNumber:
+		v, ok := value.(float64)
+		if !ok {
+			return "must be a number"
+		}
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	case FieldBoolean:
+		if _, ok := value.(bool); !ok {
+			return "must be a boolean"
+		}
+		return ""
+	case FieldDate:
+		v, ok := value.(string)
+		if !ok {
+			return "must be a date in YYYY-MM-DD format"
+		}
+		if _, err := time.Parse(DateLayout, v); err != nil {
+			return "must be a date in YYYY-MM-DD format"
+		}
+		text = v
+	default:
+		return fmt.Sprintf("has unknown type %s", rule.Type)
+	}
+
+	if len(rule.AllowedValues) == 0 {
+		return ""
+	}
+	for _, allowed := range rule.AllowedValues {
+		if text == allowed {
+			return ""
+		}
+	}
+	return fmt.Sprintf("must be one of [%s]", strings.Join(rule.AllowedValues, " "))
+}
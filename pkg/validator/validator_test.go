@@ -0,0 +1,182 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateFields(t *testing.T) {
+	rules := []FieldRule{
+		{Name: "camera", Type: FieldText},
+		{Name: "rating", Type: FieldNumber, AllowedValues: []string{"1", "2", "3", "4", "5"}},
+		{Name: "aperture", Type: FieldNumber},
+		{Name: "printed", Type: FieldBoolean},
+		{Name: "shot_on", Type: FieldDate},
+		{Name: "client", Type: FieldText, Required: true},
+		{Name: "status", Type: FieldText, AllowedValues: []string{"draft", "final"}},
+	}
+
+	tests := []struct {
+		name   string
+		values map[string]interface{}
+		// wantErrors are the messages expected in the error, in order. No
+		// error is expected when it is empty.
+		wantErrors []string
+	}{
+		{
+			name:   "only required",
+			values: map[string]interface{}{"client": "Acme"},
+		},
+		{
+			name: "all valid",
+			values: map[string]interface{}{
+				"camera":   "X100V",
+				"rating":   float64(4),
+				"aperture": 2.8,
+				"printed":  false,
+				"shot_on":  "2024-02-29",
+				"client":   "Acme",
+				"status":   "final",
+			},
+		},
+		{
+			name:       "required missing",
+			values:     map[string]interface{}{"camera": "X100V"},
+			wantErrors: []string{"field client failed validation: is required"},
+		},
+		{
+			name:       "required null",
+			values:     map[string]interface{}{"client": nil},
+			wantErrors: []string{"field client failed validation: is required"},
+		},
+		{
+			name:   "optional null",
+			values: map[string]interface{}{"client": "Acme", "camera": nil},
+		},
+		{
+			name:       "text of another type",
+			values:     map[string]interface{}{"client": float64(1)},
+			wantErrors: []string{"field client failed validation: must be a string"},
+		},
+		{
+			name:       "text too long",
+			values:     map[string]interface{}{"client": "Acme", "camera": strings.Repeat("a", maxTextFieldLength+1)},
+			wantErrors: []string{"field camera failed validation: must be at most 1000 characters long"},
+		},
+		{
+			name:   "text counted in characters",
+			values: map[string]interface{}{"client": "Acme", "camera": strings.Repeat("é", maxTextFieldLength)},
+		},
+		{
+			name:       "number of another type",
+			values:     map[string]interface{}{"client": "Acme", "aperture": "2.8"},
+			wantErrors: []string{"field aperture failed validation: must be a number"},
+		},
+		{
+			name:       "boolean of another type",
+			values:     map[string]interface{}{"client": "Acme", "printed": "true"},
+			wantErrors: []string{"field printed failed validation: must be a boolean"},
+		},
+		{
+			name:       "invalid date",
+			values:     map[string]interface{}{"client": "Acme", "shot_on": "2023-02-29"},
+			wantErrors: []string{"field shot_on failed validation: must be a date in YYYY-MM-DD format"},
+		},
+		{
+			name:       "date with a time",
+			values:     map[string]interface{}{"client": "Acme", "shot_on": "2024-02-29T10:00:00Z"},
+			wantErrors: []string{"field shot_on failed validation: must be a date in YYYY-MM-DD format"},
+		},
+		{
+			name:       "date of another type",
+			values:     map[string]interface{}{"client": "Acme", "shot_on": float64(20240229)},
+			wantErrors: []string{"field shot_on failed validation: must be a date in YYYY-MM-DD format"},
+		},
+		{
+			name:       "text not allowed",
+			values:     map[string]interface{}{"client": "Acme", "status": "Final"},
+			wantErrors: []string{"field status failed validation: must be one of [draft final]"},
+		},
+		{
+			name:       "number not allowed",
+			values:     map[string]interface{}{"client": "Acme", "rating": 4.5},
+			wantErrors: []string{"field rating failed validation: must be one of [1 2 3 4 5]"},
+		},
+		{
+			name: "unknown fields",
+			values: map[string]interface{}{
+				"client": "Acme",
+				"zoom":   float64(2),
+				"lens":   "35mm",
+			},
+			wantErrors: []string{
+				"field lens failed validation: is not defined",
+				"field zoom failed validation: is not defined",
+			},
+		},
+		{
+			name: "several errors",
+			values: map[string]interface{}{
+				"rating": float64(6),
+				"extra":  true,
+			},
+			wantErrors: []string{
+				"field rating failed validation: must be one of [1 2 3 4 5]",
+				"field client failed validation: is required",
+				"field extra failed validation: is not defined",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFields(rules, tt.values)
+			if len(tt.wantErrors) == 0 {
+				if err != nil {
+					t.Fatalf("ValidateFields failed: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("ValidateFields succeeded, want an error")
+			}
+
+			want := "validation failed: " + strings.Join(tt.wantErrors, ", ")
+			if err.Error() != want {
+				t.Errorf("ValidateFields error = %q, want %q", err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestValidateFieldName(t *testing.T) {
+	type definition struct {
+		Name string `validate:"required,fieldname"`
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "lowercase", value: "camera"},
+		{name: "digits and underscores", value: "lens_2"},
+		{name: "single letter", value: "x"},
+		{name: "empty", value: "", wantErr: true},
+		{name: "uppercase", value: "Camera", wantErr: true},
+		{name: "leading digit", value: "2lens", wantErr: true},
+		{name: "leading underscore", value: "_lens", wantErr: true},
+		{name: "hyphen", value: "shot-on", wantErr: true},
+		{name: "space", value: "shot on", wantErr: true},
+		{name: "non-ASCII", value: "größe", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(definition{Name: tt.value})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}